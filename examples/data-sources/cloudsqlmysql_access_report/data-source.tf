@@ -0,0 +1,24 @@
+resource "cloudsqlmysql_grant_database" "app" {
+  database   = "app"
+  user       = "app"
+  privileges = ["SELECT", "INSERT", "UPDATE", "DELETE"]
+  labels = {
+    team   = "payments"
+    review = "quarterly"
+  }
+}
+
+data "cloudsqlmysql_access_report" "quarterly" {
+  entries = [
+    for grant in [cloudsqlmysql_grant_database.app] : {
+      user     = grant.user
+      host     = grant.host
+      database = grant.database
+      labels   = grant.labels
+    }
+  ]
+}
+
+output "access_report" {
+  value = jsondecode(data.cloudsqlmysql_access_report.quarterly.report_json)
+}
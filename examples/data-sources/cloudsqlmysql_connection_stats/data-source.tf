@@ -0,0 +1,5 @@
+data "cloudsqlmysql_connection_stats" "this" {}
+
+output "connection_waits" {
+  value = { for pool in data.cloudsqlmysql_connection_stats.this.pools : pool.database => pool.wait_count }
+}
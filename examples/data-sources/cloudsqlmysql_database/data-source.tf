@@ -1,3 +1,8 @@
-data "googlecloudsql_mysql_database" "default" {
+data "cloudsqlmysql_database" "default" {
   name = "database"
-}
\ No newline at end of file
+}
+
+# The name is known once the baseline is applied, the data source is read after it
+data "cloudsqlmysql_database" "app" {
+  name = cloudsqlmysql_schema_baseline.app.database
+}
@@ -0,0 +1,11 @@
+data "cloudsqlmysql_database_exists" "orders" {
+  name = "orders"
+}
+
+resource "cloudsqlmysql_grant_database" "orders_reader" {
+  count = data.cloudsqlmysql_database_exists.orders.exists ? 1 : 0
+
+  database = "orders"
+  user     = "reporting"
+  preset   = "reader"
+}
@@ -0,0 +1,10 @@
+data "cloudsqlmysql_effective_privileges" "default" {
+  user = "user"
+  host = "%"
+}
+
+# The privileges of the user when only the reporting role is active
+data "cloudsqlmysql_effective_privileges" "reporting" {
+  user        = "user"
+  using_roles = ["'reporting'@'%'"]
+}
@@ -0,0 +1,10 @@
+data "cloudsqlmysql_flags_check" "default" {
+  flags = ["cloudsql_mysql_audit"]
+}
+
+check "audit_flag" {
+  assert {
+    condition     = data.cloudsqlmysql_flags_check.default.all_ok
+    error_message = "The cloudsql_mysql_audit flag needs to be enabled on the instance"
+  }
+}
@@ -0,0 +1,5 @@
+data "cloudsqlmysql_instance" "default" {}
+
+output "database_version" {
+  value = data.cloudsqlmysql_instance.default.database_version
+}
@@ -0,0 +1,11 @@
+data "cloudsqlmysql_processlist" "app" {
+  user     = "app"
+  database = "orders"
+}
+
+check "no_app_connections" {
+  assert {
+    condition     = data.cloudsqlmysql_processlist.app.connection_count == 0
+    error_message = "The app user still has active connections"
+  }
+}
@@ -0,0 +1,3 @@
+data "cloudsqlmysql_role_edges" "default" {
+  transitive = true
+}
@@ -0,0 +1,11 @@
+data "cloudsqlmysql_table" "customers" {
+  database = "orders"
+  table    = "customers"
+}
+
+check "customers_columns" {
+  assert {
+    condition     = alltrue([for column in ["id", "email"] : contains(data.cloudsqlmysql_table.customers.column_names, column)])
+    error_message = "The customers table is missing a column the grants depend on"
+  }
+}
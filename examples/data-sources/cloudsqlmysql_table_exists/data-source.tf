@@ -0,0 +1,12 @@
+data "cloudsqlmysql_table_exists" "migrations" {
+  database = "orders"
+  name     = "schema_migrations"
+}
+
+resource "cloudsqlmysql_grant_database" "reporting" {
+  count = data.cloudsqlmysql_table_exists.migrations.exists ? 1 : 0
+
+  database   = "orders"
+  user       = "reporting"
+  privileges = ["SELECT"]
+}
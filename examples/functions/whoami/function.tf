@@ -0,0 +1,3 @@
+output "provider_username" {
+  value = provider::cloudsqlmysql::whoami().username
+}
@@ -4,6 +4,11 @@ terraform {
       source = "devoteamgcloud/cloudsqlmysql"
     }
   }
+
+  # Modules can set their name, it is added to the log lines of their resources
+  provider_meta "cloudsqlmysql" {
+    module_name = "app-grants"
+  }
 }
 
 provider "cloudsqlmysql" {
@@ -0,0 +1,23 @@
+variable "reporting_password" {
+  type      = string
+  sensitive = true
+}
+
+resource "cloudsqlmysql_access_map" "default" {
+  users = {
+    "app" = {
+      databases = {
+        "orders"   = ["SELECT", "INSERT", "UPDATE", "DELETE"]
+        "products" = ["SELECT"]
+      }
+    }
+    # Created and dropped by the access map
+    "reporting" = {
+      host     = "10.0.0.0/255.0.0.0"
+      password = var.reporting_password
+      databases = {
+        "orders" = ["SELECT", "SHOW VIEW"]
+      }
+    }
+  }
+}
@@ -4,4 +4,17 @@ resource "cloudsqlmysql_audit_rule" "default" {
   object     = "*"
   operation  = "*"
   ops_result = "B"
-}
\ No newline at end of file
+}
+# Forward the rule to the SIEM pipeline
+output "audit_rule" {
+  value = jsondecode(cloudsqlmysql_audit_rule.default.rule_json)
+}
+
+# Audit the schema changes of the app database
+resource "cloudsqlmysql_audit_rule" "ddl" {
+  user       = "*"
+  database   = "app"
+  object     = "*"
+  preset     = "ddl"
+  ops_result = "B"
+}
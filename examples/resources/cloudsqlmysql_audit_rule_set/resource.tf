@@ -0,0 +1,12 @@
+# Audit the changes of every application user with a single reload of the audit plugin
+resource "cloudsqlmysql_audit_rule_set" "applications" {
+  rules = [
+    for user in ["orders-service", "billing-service", "reporting"] : {
+      user       = user
+      database   = "*"
+      object     = "*"
+      operation  = "insert,update,delete"
+      ops_result = "B"
+    }
+  ]
+}
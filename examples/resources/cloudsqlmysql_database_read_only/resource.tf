@@ -0,0 +1,4 @@
+# Freeze the orders schema while its data is migrated
+resource "cloudsqlmysql_database_read_only" "orders" {
+  database = "orders"
+}
@@ -0,0 +1,16 @@
+resource "cloudsqlmysql_grant_bundle" "default" {
+  statements = [
+    {
+      apply  = "GRANT SELECT ON `app`.* TO 'reader'@'%'"
+      revert = "REVOKE SELECT ON `app`.* FROM 'reader'@'%'"
+    },
+    {
+      apply  = "GRANT 'reader'@'%' TO 'user'@'%'"
+      revert = "REVOKE 'reader'@'%' FROM 'user'@'%'"
+    },
+    {
+      apply  = "SET DEFAULT ROLE 'reader'@'%' TO 'user'@'%'"
+      revert = "SET DEFAULT ROLE NONE TO 'user'@'%'"
+    },
+  ]
+}
@@ -0,0 +1,6 @@
+# Give the new service the same access to the orders database as the existing one
+resource "cloudsqlmysql_grant_copy" "billing" {
+  source    = "orders-service"
+  target    = "billing-service"
+  databases = ["orders"]
+}
@@ -1,6 +1,30 @@
-resource "cloudsqlmysql_database_grant" "default" {
+resource "cloudsqlmysql_grant_database" "default" {
   database          = "database"
   user              = "user"
   privileges        = ["SELECT", "UPDATE", "DELETE"]
   with_grant_option = true
-}
\ No newline at end of file
+}
+
+# Allow calling all stored procedures of the database
+resource "cloudsqlmysql_grant_database" "procedures" {
+  database    = "database"
+  user        = "user"
+  object_type = "PROCEDURE"
+  privileges  = ["EXECUTE"]
+}
+
+# Grant the privileges of a reader, the preset is expanded into privileges
+resource "cloudsqlmysql_grant_database" "reader" {
+  database = "database"
+  user     = "reporting"
+  preset   = "reader"
+}
+
+# Correct the privileges DBAs hotfix on the server on the next apply
+resource "cloudsqlmysql_grant_database" "enforced" {
+  database          = "database"
+  user              = "app"
+  privileges        = ["SELECT", "INSERT", "UPDATE", "DELETE"]
+  with_grant_option = false
+  enforce           = true
+}
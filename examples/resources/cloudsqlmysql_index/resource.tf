@@ -0,0 +1,7 @@
+# Requires allow_index_ddl = true in the provider configuration
+resource "cloudsqlmysql_index" "audit_log_created_at" {
+  database = "app"
+  table    = "audit_log"
+  name     = "idx_audit_log_created_at"
+  columns  = ["created_at", "user_id"]
+}
@@ -0,0 +1,10 @@
+# The user of the Datadog Agent, created with the Cloud SQL Admin API
+resource "google_sql_user" "datadog" {
+  instance = "my-instance"
+  name     = "datadog"
+  password = var.datadog_password
+}
+
+resource "cloudsqlmysql_monitoring_grant" "datadog" {
+  user = google_sql_user.datadog.name
+}
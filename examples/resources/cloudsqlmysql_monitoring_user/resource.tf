@@ -0,0 +1,10 @@
+variable "datadog_password" {
+  type      = string
+  sensitive = true
+}
+
+resource "cloudsqlmysql_monitoring_user" "datadog" {
+  user     = "datadog"
+  password = var.datadog_password
+  agent    = "datadog"
+}
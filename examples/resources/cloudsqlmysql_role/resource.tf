@@ -1,3 +1,10 @@
-resource "googlecloudsql_mysql_role" "default" {
-  name = "role"
-}
\ No newline at end of file
+resource "cloudsqlmysql_role" "default" {
+  name    = "role"
+  comment = "Read access for the reporting team"
+  attributes = jsonencode({
+    owner = "reporting"
+  })
+
+  # Refuse to drop the role while it's still granted to users
+  prevent_destroy_sql = "SELECT TO_USER, TO_HOST FROM mysql.role_edges WHERE FROM_USER = 'role'"
+}
@@ -0,0 +1,4 @@
+resource "cloudsqlmysql_schema_baseline" "default" {
+  database          = "database"
+  reader_privileges = ["SELECT"]
+}
@@ -0,0 +1,13 @@
+variable "app_password" {
+  type      = string
+  sensitive = true
+  ephemeral = true
+}
+
+# Rotate the password of the app user every month, the write-only password is
+# only set again when rotation_trigger changes
+resource "cloudsqlmysql_user_password" "app" {
+  user             = "app"
+  password         = var.app_password
+  rotation_trigger = "2026-10"
+}
@@ -0,0 +1,428 @@
+// Package grantparser parses the statements returned by SHOW GRANTS into structured grants.
+//
+// The formats of MySQL 5.7, 8.0 and 8.4 are supported, for example:
+//
+//	GRANT SELECT, INSERT ON 'db'.* TO 'user'@'%' IDENTIFIED BY PASSWORD '*E6CC...' WITH GRANT OPTION  (5.7)
+//	GRANT SELECT (`col1`, `col2`), UPDATE (`col1`) ON `db`.`table` TO `user`@`%`
+//	GRANT EXECUTE ON PROCEDURE `db`.`procedure` TO `user`@`10.0.0.%`
+//	GRANT BACKUP_ADMIN,BINLOG_ADMIN ON *.* TO `user`@`%` WITH GRANT OPTION
+//	GRANT PROXY ON ``@`` TO `root`@`localhost` WITH GRANT OPTION
+//	GRANT `role1`@`%`,`role2`@`%` TO `user`@`%` WITH ADMIN OPTION
+//	REVOKE INSERT ON `mysql`.* FROM `user`@`%`  (partial revokes)
+package grantparser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Level is the level a grant applies to.
+type Level int
+
+const (
+	LevelGlobal   Level = iota // ON *.*
+	LevelDatabase              // ON db.*
+	LevelTable                 // ON db.table
+	LevelRoutine               // ON FUNCTION|PROCEDURE db.routine
+	LevelProxy                 // ON user@host for the PROXY privilege
+	LevelRole                  // Roles granted to the grantee, there is no ON clause
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelGlobal:
+		return "global"
+	case LevelDatabase:
+		return "database"
+	case LevelTable:
+		return "table"
+	case LevelRoutine:
+		return "routine"
+	case LevelProxy:
+		return "proxy"
+	case LevelRole:
+		return "role"
+	default:
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+}
+
+// Account is a MySQL user or role.
+type Account struct {
+	User string
+	Host string
+}
+
+func (a Account) String() string {
+	return "'" + strings.ReplaceAll(a.User, "'", "''") + "'@'" + strings.ReplaceAll(a.Host, "'", "''") + "'"
+}
+
+// Privilege is a single privilege, Columns is only filled in for column level privileges.
+type Privilege struct {
+	Name    string
+	Columns []string
+}
+
+// Grant is a parsed GRANT or REVOKE statement.
+type Grant struct {
+	Revoke     bool
+	Privileges []Privilege // Empty for role grants
+	Roles      []Account   // Only for role grants
+	Level      Level
+	ObjectType string // TABLE, FUNCTION or PROCEDURE when present in the statement
+	Database   string // Empty for global, proxy and role grants
+	Object     string // Table or routine name, empty when not applicable
+	Proxied    Account
+	Grantees   []Account
+	// WithGrantOption is set for WITH GRANT OPTION and also for WITH ADMIN OPTION on role grants.
+	WithGrantOption bool
+}
+
+// Grantee returns the first grantee, SHOW GRANTS always returns a single grantee per statement.
+func (g *Grant) Grantee() Account {
+	if len(g.Grantees) == 0 {
+		return Account{}
+	}
+	return g.Grantees[0]
+}
+
+// PrivilegeNames returns the names of the privileges without columns.
+func (g *Grant) PrivilegeNames() []string {
+	var names []string
+	for _, privilege := range g.Privileges {
+		names = append(names, privilege.Name)
+	}
+	return names
+}
+
+// HasPrivilege checks case insensitively if the grant contains the privilege.
+func (g *Grant) HasPrivilege(name string) bool {
+	for _, privilege := range g.Privileges {
+		if strings.EqualFold(privilege.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse parses a single GRANT or REVOKE statement as returned by SHOW GRANTS.
+func Parse(statement string) (*Grant, error) {
+	tokens, err := tokenize(statement)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	grant, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse grant statement %q: %w", statement, err)
+	}
+	return grant, nil
+}
+
+// ParseAll parses all statements, typically all rows returned by SHOW GRANTS for an account.
+func ParseAll(statements []string) ([]*Grant, error) {
+	var grants []*Grant
+	for _, statement := range statements {
+		grant, err := Parse(statement)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+	return grants, nil
+}
+
+// ParseAccount parses an account name like 'user'@'host', user@host or user, the host defaults to %.
+func ParseAccount(value string) (Account, error) {
+	tokens, err := tokenize(value)
+	if err != nil {
+		return Account{}, err
+	}
+	p := &parser{tokens: tokens}
+	account, err := p.parseAccount()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q after the account", p.tokens[p.pos].value)
+	}
+	if err != nil {
+		return Account{}, fmt.Errorf("unable to parse account %q: %w", value, err)
+	}
+	return account, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) next() (token, bool) {
+	t, ok := p.peek()
+	if ok {
+		p.pos++
+	}
+	return t, ok
+}
+
+func (p *parser) peekWord(word string) bool {
+	t, ok := p.peek()
+	return ok && t.isWord(word)
+}
+
+func (p *parser) peekSymbol(symbol string) bool {
+	t, ok := p.peek()
+	return ok && t.isSymbol(symbol)
+}
+
+func (p *parser) expectWord(word string) error {
+	t, ok := p.next()
+	if !ok {
+		return fmt.Errorf("expected %s, got end of statement", word)
+	}
+	if !t.isWord(word) {
+		return fmt.Errorf("expected %s, got %q", word, t.value)
+	}
+	return nil
+}
+
+func (p *parser) parse() (*Grant, error) {
+	grant := &Grant{}
+	target := "TO"
+	switch {
+	case p.peekWord("GRANT"):
+	case p.peekWord("REVOKE"):
+		grant.Revoke = true
+		target = "FROM"
+	default:
+		return nil, errors.New("statement needs to start with GRANT or REVOKE")
+	}
+	p.pos++
+
+	if p.isRoleGrant(target) {
+		grant.Level = LevelRole
+		roles, err := p.parseAccounts()
+		if err != nil {
+			return nil, err
+		}
+		grant.Roles = roles
+	} else {
+		privileges, err := p.parsePrivileges()
+		if err != nil {
+			return nil, err
+		}
+		grant.Privileges = privileges
+		if err = p.expectWord("ON"); err != nil {
+			return nil, err
+		}
+		if err = p.parseOn(grant); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.expectWord(target); err != nil {
+		return nil, err
+	}
+	grantees, err := p.parseAccounts()
+	if err != nil {
+		return nil, err
+	}
+	grant.Grantees = grantees
+
+	p.parseOptions(grant)
+	return grant, nil
+}
+
+// isRoleGrant checks if the statement lacks an ON clause before the TO/FROM keyword.
+func (p *parser) isRoleGrant(target string) bool {
+	depth := 0
+	for _, t := range p.tokens[p.pos:] {
+		switch {
+		case t.isSymbol("("):
+			depth++
+		case t.isSymbol(")"):
+			depth--
+		case depth == 0 && t.isWord("ON"):
+			return false
+		case depth == 0 && t.isWord(target):
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parsePrivileges() ([]Privilege, error) {
+	var privileges []Privilege
+	for {
+		var words []string
+		for {
+			t, ok := p.peek()
+			if !ok || t.kind != tokenWord || t.isWord("ON") {
+				break
+			}
+			words = append(words, strings.ToUpper(t.value))
+			p.pos++
+		}
+		if len(words) == 0 {
+			return nil, errors.New("expected a privilege")
+		}
+		privilege := Privilege{Name: strings.Join(words, " ")}
+
+		if p.peekSymbol("(") {
+			p.pos++
+			columns, err := p.parseColumns()
+			if err != nil {
+				return nil, err
+			}
+			privilege.Columns = columns
+		}
+		privileges = append(privileges, privilege)
+
+		if !p.peekSymbol(",") {
+			return privileges, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) parseColumns() ([]string, error) {
+	var columns []string
+	for {
+		t, ok := p.next()
+		if !ok || !t.isName() {
+			return nil, errors.New("expected a column name")
+		}
+		columns = append(columns, t.value)
+
+		t, ok = p.next()
+		switch {
+		case ok && t.isSymbol(")"):
+			return columns, nil
+		case ok && t.isSymbol(","):
+		default:
+			return nil, errors.New("expected , or ) in column list")
+		}
+	}
+}
+
+func (p *parser) parseOn(grant *Grant) error {
+	for _, objectType := range []string{"TABLE", "FUNCTION", "PROCEDURE"} {
+		if p.peekWord(objectType) {
+			grant.ObjectType = objectType
+			p.pos++
+			break
+		}
+	}
+
+	if len(grant.Privileges) == 1 && grant.Privileges[0].Name == "PROXY" {
+		grant.Level = LevelProxy
+		proxied, err := p.parseAccount()
+		if err != nil {
+			return err
+		}
+		grant.Proxied = proxied
+		return nil
+	}
+
+	first, err := p.parseLevelName()
+	if err != nil {
+		return err
+	}
+	if !p.peekSymbol(".") {
+		// Only a table name or *, relative to the default database
+		return p.setLevel(grant, "", first)
+	}
+	p.pos++
+	second, err := p.parseLevelName()
+	if err != nil {
+		return err
+	}
+	return p.setLevel(grant, first, second)
+}
+
+func (p *parser) setLevel(grant *Grant, database, object string) error {
+	switch {
+	case database == "*" && object == "*":
+		grant.Level = LevelGlobal
+	case database == "*":
+		return errors.New("invalid privilege level *." + object)
+	case object == "*":
+		grant.Level = LevelDatabase
+		grant.Database = database
+	default:
+		grant.Level = LevelTable
+		if grant.ObjectType == "FUNCTION" || grant.ObjectType == "PROCEDURE" {
+			grant.Level = LevelRoutine
+		}
+		grant.Database = database
+		grant.Object = object
+	}
+	return nil
+}
+
+// parseLevelName returns a name in the privilege level, * is returned for the wildcard.
+func (p *parser) parseLevelName() (string, error) {
+	t, ok := p.next()
+	if !ok {
+		return "", errors.New("expected privilege level, got end of statement")
+	}
+	if t.isSymbol("*") {
+		return "*", nil
+	}
+	if !t.isName() {
+		return "", fmt.Errorf("expected privilege level, got %q", t.value)
+	}
+	return t.value, nil
+}
+
+func (p *parser) parseAccounts() ([]Account, error) {
+	var accounts []Account
+	for {
+		account, err := p.parseAccount()
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+		if !p.peekSymbol(",") {
+			return accounts, nil
+		}
+		p.pos++
+	}
+}
+
+// parseAccount parses user@host, the host defaults to % when it's omitted.
+func (p *parser) parseAccount() (Account, error) {
+	t, ok := p.next()
+	if !ok || !t.isName() {
+		return Account{}, errors.New("expected an account")
+	}
+	account := Account{User: t.value, Host: "%"}
+
+	if !p.peekSymbol("@") {
+		return account, nil
+	}
+	p.pos++
+	host, ok := p.next()
+	if !ok || !host.isName() {
+		return Account{}, errors.New("expected a host after @")
+	}
+	account.Host = host.value
+	return account, nil
+}
+
+// parseOptions looks for WITH GRANT OPTION or WITH ADMIN OPTION in the remainder of the statement,
+// other clauses like IDENTIFIED BY or REQUIRE from MySQL 5.7 are ignored.
+func (p *parser) parseOptions(grant *Grant) {
+	for ; p.pos+2 < len(p.tokens); p.pos++ {
+		if p.tokens[p.pos].isWord("WITH") &&
+			(p.tokens[p.pos+1].isWord("GRANT") || p.tokens[p.pos+1].isWord("ADMIN")) &&
+			p.tokens[p.pos+2].isWord("OPTION") {
+			grant.WithGrantOption = true
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package grantparser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		want      *Grant
+	}{
+		// MySQL 5.7 quotes the accounts with single quotes and appends the authentication of the account
+		{
+			name:      "5.7 usage with password",
+			statement: "GRANT USAGE ON *.* TO 'app'@'%' IDENTIFIED BY PASSWORD '*E6CC90B878B948C35E92B003C792C46C58C4AF40'",
+			want: &Grant{
+				Privileges: []Privilege{{Name: "USAGE"}},
+				Level:      LevelGlobal,
+				Grantees:   []Account{{User: "app", Host: "%"}},
+			},
+		},
+		{
+			name:      "5.7 all privileges on database with grant option",
+			statement: "GRANT ALL PRIVILEGES ON `app`.* TO 'app'@'%' WITH GRANT OPTION",
+			want: &Grant{
+				Privileges:      []Privilege{{Name: "ALL PRIVILEGES"}},
+				Level:           LevelDatabase,
+				Database:        "app",
+				Grantees:        []Account{{User: "app", Host: "%"}},
+				WithGrantOption: true,
+			},
+		},
+		{
+			name:      "5.7 column privileges",
+			statement: "GRANT SELECT (`id`, `name`), UPDATE (`name`) ON `app`.`users` TO 'app'@'10.0.0.%'",
+			want: &Grant{
+				Privileges: []Privilege{{Name: "SELECT", Columns: []string{"id", "name"}}, {Name: "UPDATE", Columns: []string{"name"}}},
+				Level:      LevelTable,
+				Database:   "app",
+				Object:     "users",
+				Grantees:   []Account{{User: "app", Host: "10.0.0.%"}},
+			},
+		},
+		{
+			name:      "5.7 procedure",
+			statement: "GRANT EXECUTE, ALTER ROUTINE ON PROCEDURE `app`.`refresh` TO 'app'@'%'",
+			want: &Grant{
+				Privileges: []Privilege{{Name: "EXECUTE"}, {Name: "ALTER ROUTINE"}},
+				Level:      LevelRoutine,
+				ObjectType: "PROCEDURE",
+				Database:   "app",
+				Object:     "refresh",
+				Grantees:   []Account{{User: "app", Host: "%"}},
+			},
+		},
+		{
+			name:      "5.7 proxy",
+			statement: "GRANT PROXY ON ''@'' TO 'root'@'localhost' WITH GRANT OPTION",
+			want: &Grant{
+				Privileges:      []Privilege{{Name: "PROXY"}},
+				Level:           LevelProxy,
+				Proxied:         Account{User: "", Host: ""},
+				Grantees:        []Account{{User: "root", Host: "localhost"}},
+				WithGrantOption: true,
+			},
+		},
+		{
+			name:      "5.7 require ssl",
+			statement: "GRANT SELECT ON `app`.* TO 'app'@'%' REQUIRE SSL WITH GRANT OPTION MAX_QUERIES_PER_HOUR 10",
+			want: &Grant{
+				Privileges:      []Privilege{{Name: "SELECT"}},
+				Level:           LevelDatabase,
+				Database:        "app",
+				Grantees:        []Account{{User: "app", Host: "%"}},
+				WithGrantOption: true,
+			},
+		},
+		{
+			name:      "5.7 escaped wildcard in database",
+			statement: "GRANT SELECT ON `my\\_app`.* TO 'app'@'%'",
+			want: &Grant{
+				Privileges: []Privilege{{Name: "SELECT"}},
+				Level:      LevelDatabase,
+				Database:   "my\\_app",
+				Grantees:   []Account{{User: "app", Host: "%"}},
+			},
+		},
+		// MySQL 8.0 quotes the accounts with backticks and lists the dynamic privileges without spaces
+		{
+			name:      "8.0 database privileges",
+			statement: "GRANT SELECT, INSERT, UPDATE ON `app`.* TO `app`@`%`",
+			want: &Grant{
+				Privileges: []Privilege{{Name: "SELECT"}, {Name: "INSERT"}, {Name: "UPDATE"}},
+				Level:      LevelDatabase,
+				Database:   "app",
+				Grantees:   []Account{{User: "app", Host: "%"}},
+			},
+		},
+		{
+			name:      "8.0 dynamic privileges",
+			statement: "GRANT BACKUP_ADMIN,BINLOG_ADMIN,CONNECTION_ADMIN ON *.* TO `admin`@`%` WITH GRANT OPTION",
+			want: &Grant{
+				Privileges:      []Privilege{{Name: "BACKUP_ADMIN"}, {Name: "BINLOG_ADMIN"}, {Name: "CONNECTION_ADMIN"}},
+				Level:           LevelGlobal,
+				Grantees:        []Account{{User: "admin", Host: "%"}},
+				WithGrantOption: true,
+			},
+		},
+		{
+			name:      "8.0 roles with admin option",
+			statement: "GRANT `reader`@`%`,`writer`@`%` TO `app`@`%` WITH ADMIN OPTION",
+			want: &Grant{
+				Roles:           []Account{{User: "reader", Host: "%"}, {User: "writer", Host: "%"}},
+				Level:           LevelRole,
+				Grantees:        []Account{{User: "app", Host: "%"}},
+				WithGrantOption: true,
+			},
+		},
+		{
+			name:      "8.0 partial revoke",
+			statement: "REVOKE INSERT ON `mysql`.* FROM `app`@`%`",
+			want: &Grant{
+				Revoke:     true,
+				Privileges: []Privilege{{Name: "INSERT"}},
+				Level:      LevelDatabase,
+				Database:   "mysql",
+				Grantees:   []Account{{User: "app", Host: "%"}},
+			},
+		},
+		{
+			name:      "8.0 all privileges on database",
+			statement: "GRANT ALL PRIVILEGES ON `app`.* TO `app`@`%`",
+			want: &Grant{
+				Privileges: []Privilege{{Name: "ALL PRIVILEGES"}},
+				Level:      LevelDatabase,
+				Database:   "app",
+				Grantees:   []Account{{User: "app", Host: "%"}},
+			},
+		},
+		{
+			name:      "8.0 table",
+			statement: "GRANT SELECT ON `app`.`orders` TO `reporting`@`%`",
+			want: &Grant{
+				Privileges: []Privilege{{Name: "SELECT"}},
+				Level:      LevelTable,
+				Database:   "app",
+				Object:     "orders",
+				Grantees:   []Account{{User: "reporting", Host: "%"}},
+			},
+		},
+		{
+			name:      "8.0 function",
+			statement: "GRANT EXECUTE ON FUNCTION `app`.`total` TO `app`@`%`",
+			want: &Grant{
+				Privileges: []Privilege{{Name: "EXECUTE"}},
+				Level:      LevelRoutine,
+				ObjectType: "FUNCTION",
+				Database:   "app",
+				Object:     "total",
+				Grantees:   []Account{{User: "app", Host: "%"}},
+			},
+		},
+		// MySQL 8.4 has the same format as 8.0 with new dynamic privileges
+		{
+			name:      "8.4 dynamic privileges",
+			statement: "GRANT FLUSH_PRIVILEGES,OPTIMIZE_LOCAL_TABLE ON *.* TO `ops`@`%`",
+			want: &Grant{
+				Privileges: []Privilege{{Name: "FLUSH_PRIVILEGES"}, {Name: "OPTIMIZE_LOCAL_TABLE"}},
+				Level:      LevelGlobal,
+				Grantees:   []Account{{User: "ops", Host: "%"}},
+			},
+		},
+		{
+			name:      "8.4 quoted names",
+			statement: "GRANT SELECT ON `we``ird`.* TO `o'brien`@`10.0.0.0/255.255.255.0`",
+			want: &Grant{
+				Privileges: []Privilege{{Name: "SELECT"}},
+				Level:      LevelDatabase,
+				Database:   "we`ird",
+				Grantees:   []Account{{User: "o'brien", Host: "10.0.0.0/255.255.255.0"}},
+			},
+		},
+		{
+			name:      "8.4 role without host",
+			statement: "GRANT `reader` TO `app`@`%`",
+			want: &Grant{
+				Roles:    []Account{{User: "reader", Host: "%"}},
+				Level:    LevelRole,
+				Grantees: []Account{{User: "app", Host: "%"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Parse(test.statement)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", test.statement, err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", test.statement, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"SHOW GRANTS FOR `app`@`%`",
+		"GRANT ON `app`.* TO `app`@`%`",
+		"GRANT SELECT ON *.`users` TO `app`@`%`",
+		"GRANT SELECT ON `app`.* `app`@`%`",
+		"GRANT SELECT ON `app.* TO `app`@`%`",
+		"GRANT SELECT (`id` ON `app`.`users` TO `app`@`%`",
+		"GRANT SELECT ON `app`.* TO `app`@",
+	}
+	for _, statement := range tests {
+		if grant, err := Parse(statement); err == nil {
+			t.Errorf("Parse(%q) = %+v, want error", statement, grant)
+		}
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	grants, err := ParseAll([]string{
+		"GRANT USAGE ON *.* TO `app`@`%`",
+		"GRANT SELECT ON `app`.* TO `app`@`%`",
+	})
+	if err != nil {
+		t.Fatalf("ParseAll returned error: %v", err)
+	}
+	if len(grants) != 2 || grants[0].Level != LevelGlobal || grants[1].Level != LevelDatabase {
+		t.Errorf("ParseAll returned %+v", grants)
+	}
+
+	if _, err = ParseAll([]string{"GRANT USAGE ON *.* TO `app`@`%`", "GRANT"}); err == nil {
+		t.Error("ParseAll with an invalid statement returned no error")
+	}
+}
+
+func TestParseAccount(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Account
+	}{
+		{value: "'app'@'%'", want: Account{User: "app", Host: "%"}},
+		{value: "`app`@`10.0.0.%`", want: Account{User: "app", Host: "10.0.0.%"}},
+		{value: "app@localhost", want: Account{User: "app", Host: "localhost"}},
+		{value: "app", want: Account{User: "app", Host: "%"}},
+		{value: "'o''brien'@'%'", want: Account{User: "o'brien", Host: "%"}},
+	}
+	for _, test := range tests {
+		got, err := ParseAccount(test.value)
+		if err != nil {
+			t.Errorf("ParseAccount(%q) returned error: %v", test.value, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseAccount(%q) = %+v, want %+v", test.value, got, test.want)
+		}
+	}
+
+	for _, value := range []string{"", "'app'@", "'app'@'%' extra", "'app"} {
+		if account, err := ParseAccount(value); err == nil {
+			t.Errorf("ParseAccount(%q) = %+v, want error", value, account)
+		}
+	}
+}
+
+func TestAccountString(t *testing.T) {
+	account := Account{User: "o'brien", Host: "%"}
+	if got, want := account.String(), "'o''brien'@'%'"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestGrantHelpers(t *testing.T) {
+	grant, err := Parse("GRANT SELECT (`id`), insert ON `app`.`users` TO `app`@`%`, `other`@`%`")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if got, want := grant.PrivilegeNames(), []string{"SELECT", "INSERT"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PrivilegeNames() = %v, want %v", got, want)
+	}
+	if !grant.HasPrivilege("insert") || grant.HasPrivilege("UPDATE") {
+		t.Errorf("HasPrivilege returned the wrong result for %v", grant.PrivilegeNames())
+	}
+	if got, want := grant.Grantee(), (Account{User: "app", Host: "%"}); got != want {
+		t.Errorf("Grantee() = %+v, want %+v", got, want)
+	}
+	if got := (&Grant{}).Grantee(); got != (Account{}) {
+		t.Errorf("Grantee() of a grant without grantees = %+v", got)
+	}
+}
@@ -0,0 +1,110 @@
+package grantparser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenWord       tokenKind = iota // Unquoted keyword or identifier
+	tokenIdentifier                  // Identifier quoted with backticks or double quotes
+	tokenString                      // String quoted with single quotes
+	tokenSymbol                      // One of , ( ) . @ * ;
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+func (t token) isWord(word string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.value, word)
+}
+
+func (t token) isSymbol(symbol string) bool {
+	return t.kind == tokenSymbol && t.value == symbol
+}
+
+// isName reports if the token can be used as a name of an account, database, table, column or role.
+func (t token) isName() bool {
+	return t.kind == tokenWord || t.kind == tokenIdentifier || t.kind == tokenString
+}
+
+func tokenize(statement string) ([]token, error) {
+	var tokens []token
+	runes := []rune(statement)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case strings.ContainsRune(",().@*;", r):
+			tokens = append(tokens, token{kind: tokenSymbol, value: string(r)})
+			i++
+		case r == '`' || r == '"' || r == '\'':
+			value, next, err := readQuoted(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			kind := tokenIdentifier
+			if r == '\'' {
+				kind = tokenString
+			}
+			tokens = append(tokens, token{kind: kind, value: value})
+			i = next
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(",().@*;`\"'", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, value: string(runes[start:i])})
+		}
+	}
+	return tokens, nil
+}
+
+// readQuoted reads a quoted value starting at the opening quote. A doubled quote escapes the quote character,
+// in strings a backslash escapes the next character too.
+func readQuoted(runes []rune, start int) (string, int, error) {
+	quote := runes[start]
+	var value strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		r := runes[i]
+		if quote == '\'' && r == '\\' && i+1 < len(runes) {
+			if runes[i+1] == '%' || runes[i+1] == '_' {
+				value.WriteRune(r) // MySQL keeps the backslash for escaped wildcards
+			}
+			value.WriteRune(unescape(runes[i+1]))
+			i++
+			continue
+		}
+		if r == quote {
+			if i+1 < len(runes) && runes[i+1] == quote {
+				value.WriteRune(quote)
+				i++
+				continue
+			}
+			return value.String(), i + 1, nil
+		}
+		value.WriteRune(r)
+	}
+	return "", 0, fmt.Errorf("unterminated quoted value starting at position %d", start)
+}
+
+func unescape(r rune) rune {
+	switch r {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	case '0':
+		return 0
+	default:
+		return r
+	}
+}
@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// accountCommentKey is the key of the user attributes in which MySQL stores the COMMENT of an account.
+const accountCommentKey = "comment"
+
+// userAttributesVersion is the first version with the user attributes of ALTER USER ... ATTRIBUTE.
+var userAttributesVersion = serverVersion{major: 8, patch: 21}
+
+// accountGrantDescriptionsKey is the key of the user attributes in which the descriptions of the grants with
+// persist_description are stored, by grant.
+const accountGrantDescriptionsKey = "grant_descriptions"
+
+var _ validator.String = accountAttributesValidator{}
+
+// accountAttributesValidator validates that the value is a JSON object without the comment key, the comment
+// has its own attribute. The grant_descriptions key is managed by the grants.
+type accountAttributesValidator struct{}
+
+func (v accountAttributesValidator) Description(_ context.Context) string {
+	return "attributes must be a JSON object, the comment is set with the comment attribute and grant_descriptions by the grants"
+}
+
+func (v accountAttributesValidator) MarkdownDescription(_ context.Context) string {
+	return "attributes must be a JSON object, the comment is set with the `comment` attribute and `grant_descriptions` by the grants"
+}
+
+func (v accountAttributesValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	attributes, err := parseAccountAttributes(req.ConfigValue.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid attributes", "The attributes are not a JSON object: "+err.Error())
+		return
+	}
+	_, comment := attributes[accountCommentKey]
+	_, grantDescriptions := attributes[accountGrantDescriptionsKey]
+	if comment || grantDescriptions {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid attributes", v.Description(ctx))
+	}
+}
+
+func parseAccountAttributes(value string) (map[string]any, error) {
+	var attributes map[string]any
+	if err := json.Unmarshal([]byte(value), &attributes); err != nil {
+		return nil, err
+	}
+	if attributes == nil {
+		return nil, errors.New("null is not a JSON object")
+	}
+	return attributes, nil
+}
+
+// readAccountAttributes reads the comment and the other user attributes of the account from
+// INFORMATION_SCHEMA.USER_ATTRIBUTES, available since MySQL 8.0.21. The values are null when not set. The
+// grant_descriptions are left out, they are read with readGrantDescriptions.
+func (c *Config) readAccountAttributes(ctx context.Context, db dbExecutor, user, host string) (types.String, types.String, error) {
+	attributes, err := c.queryAccountAttributes(ctx, db, user, host)
+	if err != nil || attributes == nil {
+		return types.StringNull(), types.StringNull(), err
+	}
+
+	comment := types.StringNull()
+	if text, ok := attributes[accountCommentKey].(string); ok {
+		comment = types.StringValue(text)
+	}
+	delete(attributes, accountCommentKey)
+	delete(attributes, accountGrantDescriptionsKey)
+	if len(attributes) == 0 {
+		return comment, types.StringNull(), nil
+	}
+
+	encoded, err := json.Marshal(attributes)
+	if err != nil {
+		return types.StringNull(), types.StringNull(), err
+	}
+	return comment, types.StringValue(string(encoded)), nil
+}
+
+// readGrantDescriptions reads the descriptions of the grants stored in the grant_descriptions of the user attributes.
+func (c *Config) readGrantDescriptions(ctx context.Context, db dbExecutor, user, host string) (map[string]string, error) {
+	attributes, err := c.queryAccountAttributes(ctx, db, user, host)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptions := make(map[string]string)
+	grantDescriptions, _ := attributes[accountGrantDescriptionsKey].(map[string]any)
+	for key, value := range grantDescriptions {
+		if text, ok := value.(string); ok {
+			descriptions[key] = text
+		}
+	}
+	return descriptions, nil
+}
+
+// queryAccountAttributes reads the user attributes of the account, nil when the account has none.
+func (c *Config) queryAccountAttributes(ctx context.Context, db dbExecutor, user, host string) (map[string]any, error) {
+	user, host = c.canonicalAccount(user, host)
+	var value sql.NullString
+	err := queryRow(ctx, db, "SELECT ATTRIBUTE FROM INFORMATION_SCHEMA.USER_ATTRIBUTES WHERE USER = ? AND HOST = ?",
+		[]any{user, host}, &value)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !value.Valid) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseAccountAttributes(value.String)
+}
+
+// accountAttributesEqual compares the JSON of the attributes regardless of formatting and key order.
+func accountAttributesEqual(a, b types.String) bool {
+	if a.IsNull() || b.IsNull() {
+		return a.IsNull() == b.IsNull()
+	}
+	attributesA, errA := parseAccountAttributes(a.ValueString())
+	attributesB, errB := parseAccountAttributes(b.ValueString())
+	if errA != nil || errB != nil {
+		return a.ValueString() == b.ValueString()
+	}
+	return reflect.DeepEqual(attributesA, attributesB)
+}
+
+// accountAttributesPatch returns the JSON merge patch for ALTER USER ... ATTRIBUTE that changes the comment and
+// attributes from the state to the plan, keys that are no longer set are removed with null. It returns an empty
+// string when nothing changes.
+func accountAttributesPatch(stateComment, planComment, stateAttributes, planAttributes types.String) (string, error) {
+	var current, desired map[string]any
+	var err error
+	if !stateAttributes.IsNull() {
+		if current, err = parseAccountAttributes(stateAttributes.ValueString()); err != nil {
+			return "", err
+		}
+	}
+	if !planAttributes.IsNull() {
+		if desired, err = parseAccountAttributes(planAttributes.ValueString()); err != nil {
+			return "", err
+		}
+	}
+	patch := mergePatch(current, desired)
+
+	if !planComment.Equal(stateComment) {
+		if planComment.IsNull() {
+			patch[accountCommentKey] = nil
+		} else {
+			patch[accountCommentKey] = planComment.ValueString()
+		}
+	}
+
+	if len(patch) == 0 {
+		return "", nil
+	}
+	encoded, err := json.Marshal(patch)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// mergePatch returns the JSON merge patch (RFC 7396) that turns current into desired. Nested objects are patched
+// recursively because MySQL merges them instead of replacing them.
+func mergePatch(current, desired map[string]any) map[string]any {
+	patch := make(map[string]any)
+	for key := range current {
+		if _, ok := desired[key]; !ok {
+			patch[key] = nil
+		}
+	}
+	for key, value := range desired {
+		currentValue, ok := current[key]
+		if ok && reflect.DeepEqual(currentValue, value) {
+			continue
+		}
+		currentObject, currentIsObject := currentValue.(map[string]any)
+		desiredObject, desiredIsObject := value.(map[string]any)
+		if ok && currentIsObject && desiredIsObject {
+			patch[key] = mergePatch(currentObject, desiredObject)
+			continue
+		}
+		patch[key] = value
+	}
+	return patch
+}
@@ -0,0 +1,198 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"terraform-provider-cloudsqlmysql/internal/grantparser"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/attr/xattr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable                    = AccountType{}
+	_ xattr.TypeWithValidate                     = AccountType{}
+	_ basetypes.StringValuableWithSemanticEquals = AccountValue{}
+)
+
+// AccountType is a MySQL account name: 'user'@'host', user@host or user with the default host %.
+type AccountType struct {
+	basetypes.StringType
+}
+
+func (t AccountType) Equal(o attr.Type) bool {
+	other, ok := o.(AccountType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t AccountType) String() string {
+	return "AccountType"
+}
+
+func (t AccountType) ValueFromString(_ context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return AccountValue{StringValue: in}, nil
+}
+
+func (t AccountType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+
+	stringValuable, diags := t.ValueFromString(ctx, stringValue)
+	if diags.HasError() {
+		return nil, fmt.Errorf("unexpected error converting StringValue to StringValuable: %v", diags)
+	}
+	return stringValuable, nil
+}
+
+func (t AccountType) ValueType(_ context.Context) attr.Value {
+	return AccountValue{}
+}
+
+// Validate checks that the value can be parsed as an account and that the host is valid.
+func (t AccountType) Validate(_ context.Context, in tftypes.Value, valuePath path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if in.IsNull() || !in.IsKnown() {
+		return diags
+	}
+
+	var value string
+	if err := in.As(&value); err != nil {
+		diags.AddAttributeError(valuePath, "Invalid account", "Could not read the account: "+err.Error())
+		return diags
+	}
+
+	account, err := grantparser.ParseAccount(value)
+	if err != nil {
+		diags.AddAttributeError(valuePath, "Invalid account", "The value \""+value+"\" is not a valid account name, expected 'user'@'host' or user: "+err.Error())
+		return diags
+	}
+	if blankUser(account.User) {
+		diags.AddAttributeError(valuePath, "Invalid account", blankUserMessage)
+	}
+	if !validHost(account.Host) {
+		diags.AddAttributeError(valuePath, "Invalid account", "The host of \""+value+"\" is invalid, "+hostValidator{}.Description(context.Background()))
+	}
+	return diags
+}
+
+// AccountValue is the value of an AccountType. Values are semantically equal when they name the same account,
+// so role and 'role'@'%' are the same. The values have no access to the provider configuration, the case is compared
+// like MySQL does and keepStateAccounts applies the case_sensitivity setting to the plan.
+type AccountValue struct {
+	basetypes.StringValue
+}
+
+// newAccountValue returns the quoted account name of the user and host.
+func (c *Config) newAccountValue(user, host string) AccountValue {
+	return AccountValue{StringValue: basetypes.NewStringValue(c.quoteAccount(user, host))}
+}
+
+func (v AccountValue) Equal(o attr.Value) bool {
+	other, ok := o.(AccountValue)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v AccountValue) Type(_ context.Context) attr.Type {
+	return AccountType{}
+}
+
+func (v AccountValue) StringSemanticEquals(_ context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(AccountValue)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T but got value type %T. Please report this to the provider developers.", v, newValuable),
+		)
+		return false, diags
+	}
+
+	current, err := v.Account()
+	if err != nil {
+		return false, diags
+	}
+	updated, err := newValue.Account()
+	if err != nil {
+		return false, diags
+	}
+	return accountsEqualFor(caseSensitivityMySQL, current, updated), diags
+}
+
+// Account parses the value, the host defaults to %.
+func (v AccountValue) Account() (grantparser.Account, error) {
+	return grantparser.ParseAccount(v.ValueString())
+}
+
+// quoteAccountValue returns the account name of the value quoted to be used in statements.
+func (c *Config) quoteAccountValue(v AccountValue) (string, error) {
+	account, err := v.Account()
+	if err != nil {
+		return "", err
+	}
+	return c.quoteAccount(account.User, account.Host), nil
+}
+
+// accountsEqual compares the canonical accounts following case_sensitivity.
+func (c *Config) accountsEqual(a, b grantparser.Account) bool {
+	return accountsEqualFor(c.caseSensitivity, a, b)
+}
+
+// accountsEqualFor compares the canonical accounts of the case_sensitivity setting, by default user names are
+// case-sensitive and host names are not like in MySQL.
+func accountsEqualFor(caseSensitivity string, a, b grantparser.Account) bool {
+	userA, hostA := canonicalAccountFor(caseSensitivity, a.User, a.Host)
+	userB, hostB := canonicalAccountFor(caseSensitivity, b.User, b.Host)
+	return userA == userB && hostA == hostB
+}
+
+// keepStateAccounts keeps the accounts of the state in the plan where they only differ in a case that
+// case_sensitivity ignores, so changing the case in the configuration doesn't replace the resource.
+func (c *Config) keepStateAccounts(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, paths ...path.Path) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	for _, attributePath := range paths {
+		var planned, current AccountValue
+		resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, attributePath, &planned)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, attributePath, &current)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if planned.IsNull() || planned.IsUnknown() || current.IsNull() || planned.Equal(current) {
+			continue
+		}
+
+		plannedAccount, err := planned.Account()
+		if err != nil {
+			continue
+		}
+		currentAccount, err := current.Account()
+		if err != nil || !c.accountsEqual(plannedAccount, currentAccount) {
+			continue
+		}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, attributePath, current)...)
+		resp.RequiresReplace = slices.DeleteFunc(resp.RequiresReplace, attributePath.Equal)
+	}
+}
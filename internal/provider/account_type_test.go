@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestKeepStateAccounts(t *testing.T) {
+	r := &grantCopyResource{}
+	state := newTestPlan(t, r, map[string]tftypes.Value{
+		"source": tftypes.NewValue(tftypes.String, "'template'@'%'"),
+		"target": tftypes.NewValue(tftypes.String, "'app'@'%'"),
+	})
+	plan := newTestPlan(t, r, map[string]tftypes.Value{
+		"source": tftypes.NewValue(tftypes.String, "'template'@'%'"),
+		"target": tftypes.NewValue(tftypes.String, "'App'@'%'"),
+	})
+	req := resource.ModifyPlanRequest{Plan: plan, State: tfsdk.State{Schema: state.Schema, Raw: state.Raw}}
+
+	// The providers keep their own case_sensitivity, one doesn't change how the other compares
+	tests := []struct {
+		caseSensitivity string
+		wantTarget      string
+	}{
+		{caseSensitivity: caseSensitivityInsensitive, wantTarget: "'app'@'%'"},
+		{caseSensitivity: caseSensitivityMySQL, wantTarget: "'App'@'%'"},
+	}
+	for _, test := range tests {
+		t.Run(test.caseSensitivity, func(t *testing.T) {
+			config := &Config{caseSensitivity: test.caseSensitivity}
+			resp := &resource.ModifyPlanResponse{Plan: plan, RequiresReplace: path.Paths{path.Root("target")}}
+			config.keepStateAccounts(context.Background(), req, resp, path.Root("source"), path.Root("target"))
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("keepStateAccounts returned %v", resp.Diagnostics)
+			}
+
+			var target AccountValue
+			resp.Diagnostics.Append(resp.Plan.GetAttribute(context.Background(), path.Root("target"), &target)...)
+			if target.ValueString() != test.wantTarget {
+				t.Errorf("keepStateAccounts planned target %s, want %s", target.ValueString(), test.wantTarget)
+			}
+			if replace := len(resp.RequiresReplace) > 0; replace != (test.wantTarget == "'App'@'%'") {
+				t.Errorf("keepStateAccounts left RequiresReplace %v", resp.RequiresReplace)
+			}
+		})
+	}
+}
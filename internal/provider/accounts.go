@@ -0,0 +1,264 @@
+package provider
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"terraform-provider-cloudsqlmysql/internal/sqlgen"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// maxHostLength is the maximum length of the host part of an account since MySQL 8.0.17.
+const maxHostLength = 255
+
+var hostPatternRegex = regexp.MustCompile(`^[A-Za-z0-9%_.\-]+$`)
+
+// The case_sensitivity settings, they decide how account names are canonicalized before they are used.
+const (
+	// caseSensitivityMySQL compares like MySQL, user names are case-sensitive and host names are lowercased.
+	caseSensitivityMySQL = "mysql"
+	// caseSensitivitySensitive uses user and host names exactly as configured.
+	caseSensitivitySensitive = "sensitive"
+	// caseSensitivityInsensitive lowercases both user and host names.
+	caseSensitivityInsensitive = "insensitive"
+)
+
+var caseSensitivities = []string{caseSensitivityMySQL, caseSensitivitySensitive, caseSensitivityInsensitive}
+
+// canonicalAccount returns the user and host in the case that is used in statements, lookups and comparisons.
+func (c *Config) canonicalAccount(user, host string) (string, string) {
+	return canonicalAccountFor(c.caseSensitivity, user, host)
+}
+
+// canonicalAccountFor returns the user and host in the case of the case_sensitivity setting, empty is mysql.
+func canonicalAccountFor(caseSensitivity, user, host string) (string, string) {
+	switch caseSensitivity {
+	case caseSensitivitySensitive:
+		return user, host
+	case caseSensitivityInsensitive:
+		return strings.ToLower(user), strings.ToLower(host)
+	default:
+		return user, strings.ToLower(host)
+	}
+}
+
+// quoteAccount quotes the user and host as a canonical MySQL account name.
+func (c *Config) quoteAccount(user, host string) string {
+	user, host = c.canonicalAccount(user, host)
+	return sqlgen.Account(user, host)
+}
+
+// validHost checks if the host is valid in a MySQL account name: a host name or pattern with % and _ wildcards,
+// an IPv4 address with an optional netmask or CIDR prefix, or an IPv6 address with an optional prefix.
+func validHost(host string) bool {
+	if host == "" || len(host) > maxHostLength {
+		return false
+	}
+
+	address, mask, hasMask := strings.Cut(host, "/")
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return !hasMask && hostPatternRegex.MatchString(host)
+	}
+	if !hasMask {
+		return true
+	}
+
+	if ip.To4() != nil {
+		if netmask := net.ParseIP(mask); netmask != nil && netmask.To4() != nil {
+			_, bits := net.IPMask(netmask.To4()).Size()
+			return bits != 0 // Size returns 0, 0 for a non-canonical netmask
+		}
+		return validPrefixLength(mask, 32)
+	}
+	return validPrefixLength(mask, 128)
+}
+
+func validPrefixLength(prefix string, maxLength int) bool {
+	length, err := strconv.Atoi(prefix)
+	return err == nil && length >= 0 && length <= maxLength
+}
+
+// hostMatches checks if a client connecting from the host name or IP address matches the host part of an account,
+// like MySQL matches it: case-insensitive with the % and _ wildcards, or by netmask and CIDR prefix.
+func hostMatches(pattern, host string) bool {
+	if pattern == "" || pattern == "%" {
+		return true
+	}
+	if address, mask, hasMask := strings.Cut(pattern, "/"); hasMask {
+		ip := net.ParseIP(host)
+		network := net.ParseIP(address)
+		if ip == nil || network == nil {
+			return false
+		}
+		var ipMask net.IPMask
+		if netmask := net.ParseIP(mask); netmask != nil && netmask.To4() != nil {
+			ipMask = net.IPMask(netmask.To4())
+		} else if length, err := strconv.Atoi(mask); err == nil {
+			bits := 128
+			if network.To4() != nil {
+				bits = 32
+			}
+			ipMask = net.CIDRMask(length, bits)
+		}
+		if ipMask == nil {
+			return false
+		}
+		if network.To4() != nil {
+			network, ip = network.To4(), ip.To4()
+			if ip == nil {
+				return false
+			}
+		}
+		return network.Mask(ipMask).Equal(ip.Mask(ipMask))
+	}
+
+	var expression strings.Builder
+	expression.WriteString("(?i)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			expression.WriteString(".*")
+		case '_':
+			expression.WriteString(".")
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expression.WriteString("$")
+	return regexp.MustCompile(expression.String()).MatchString(host)
+}
+
+// hostSpecificity sorts the host parts of accounts like MySQL sorts the rows of mysql.user, the account with the
+// lowest rank and the longest prefix is the one a client is authenticated as: literal host names and IP addresses,
+// then netmasks and CIDR prefixes, then patterns with wildcards, then % and the empty host.
+func hostSpecificity(host string) (rank int, prefix int) {
+	switch {
+	case host == "%":
+		return 3, 0
+	case host == "":
+		return 4, 0
+	case strings.Contains(host, "/"):
+		address, mask, _ := strings.Cut(host, "/")
+		if netmask := net.ParseIP(mask); netmask != nil && netmask.To4() != nil {
+			ones, _ := net.IPMask(netmask.To4()).Size()
+			return 1, ones
+		}
+		ones, _ := strconv.Atoi(mask)
+		if net.ParseIP(address).To4() == nil {
+			ones -= 96 // Compare IPv6 prefixes on the same scale as the IPv4 ones
+		}
+		return 1, ones
+	case strings.ContainsAny(host, "%_"):
+		return 2, strings.IndexAny(host, "%_")
+	}
+	return 0, len(host)
+}
+
+// matchingHosts returns the host parts of the accounts that match a client connecting from the host, the account
+// MySQL authenticates the client as comes first. Hosts of the same specificity are ordered by name, so the order is
+// the same on every plan.
+func matchingHosts(hosts []string, host string) []string {
+	var matching []string
+	for _, pattern := range hosts {
+		if hostMatches(pattern, host) {
+			matching = append(matching, pattern)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		rankI, prefixI := hostSpecificity(matching[i])
+		rankJ, prefixJ := hostSpecificity(matching[j])
+		if rankI != rankJ {
+			return rankI < rankJ
+		}
+		if prefixI != prefixJ {
+			return prefixI > prefixJ
+		}
+		return matching[i] < matching[j]
+	})
+	return matching
+}
+
+var _ validator.String = hostValidator{}
+
+// hostValidator validates the host part of a MySQL account name.
+type hostValidator struct{}
+
+func (v hostValidator) Description(_ context.Context) string {
+	return "host must be a host name or pattern, an IPv4 address with optional netmask or an IPv6 address with optional prefix"
+}
+
+func (v hostValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v hostValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if !validHost(req.ConfigValue.ValueString()) {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid host", "The value \""+req.ConfigValue.ValueString()+"\" is invalid, "+v.Description(ctx))
+	}
+}
+
+// blankUser checks if the user name only consists of whitespace. MySQL treats it as a separate user that nobody
+// intends to create, unlike the empty user name of the anonymous account.
+func blankUser(user string) bool {
+	return user != "" && strings.TrimSpace(user) == ""
+}
+
+// blankUserMessage explains why a user or role name with only whitespace is refused.
+const blankUserMessage = "The user or role name only contains whitespace, use the name of an existing user or role"
+
+var _ validator.String = userNameValidator{}
+
+// userNameValidator validates that a user or role name is not only whitespace. The empty name of the anonymous
+// account is checked when planning, as it depends on allow_anonymous_accounts of the provider.
+type userNameValidator struct{}
+
+func (v userNameValidator) Description(_ context.Context) string {
+	return "user or role name must not only contain whitespace"
+}
+
+func (v userNameValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v userNameValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if blankUser(req.ConfigValue.ValueString()) {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid user or role name", blankUserMessage)
+	}
+}
+
+// anonymousAccountError returns an error message when the user is empty, which names the anonymous account that
+// matches any user connecting from the host, and anonymous accounts are not allowed.
+func (c *Config) anonymousAccountError(user, host string) string {
+	if user != "" || c.allowAnonymousAccounts {
+		return ""
+	}
+	return "The user or role name is empty, that is the anonymous account " + c.quoteAccount(user, host) + " which matches " +
+		"every user connecting from the host. Set `allow_anonymous_accounts = true` in the provider configuration when this is intended."
+}
+
+// anonymousAccountValueError is anonymousAccountError for an account attribute, unknown and invalid values
+// are skipped as these are reported by the validation of the attribute.
+func (c *Config) anonymousAccountValueError(value AccountValue) string {
+	if value.IsNull() || value.IsUnknown() {
+		return ""
+	}
+	account, err := value.Account()
+	if err != nil {
+		return ""
+	}
+	return c.anonymousAccountError(account.User, account.Host)
+}
@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	sqladmin "google.golang.org/api/sqladmin/v1beta4"
+	htransport "google.golang.org/api/transport/http"
+)
+
+const (
+	adminAPIRetryDelay    = time.Second
+	adminAPIMaxRetryDelay = 30 * time.Second
+)
+
+// adminAPIRetryTransport retries the Cloud SQL Admin API requests of the connector that are rejected because the
+// quota of the project is exhausted, with exponential backoff and jitter. Many provider aliases refreshing their
+// certificates at the same time can exhaust the per-minute quota.
+type adminAPIRetryTransport struct {
+	base    http.RoundTripper
+	retries int
+}
+
+func (t *adminAPIRetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	delay := adminAPIRetryDelay
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || attempt >= t.retries || !adminAPIRetryableStatus(resp.StatusCode) {
+			return resp, err
+		}
+		// The request body can only be sent again when it can be recreated
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil
+		}
+
+		wait := delay/2 + time.Duration(rand.Int63n(int64(delay)))
+		if retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && time.Duration(retryAfter)*time.Second > wait {
+			wait = time.Duration(retryAfter) * time.Second
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		recordRetry()
+		tflog.Info(ctx, fmt.Sprintf("Cloud SQL Admin API answered %d to %s, retrying in %s (retry %d of %d)",
+			resp.StatusCode, req.URL.Path, wait, attempt+1, t.retries))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		delay = min(delay*2, adminAPIMaxRetryDelay)
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+// adminAPIRetryableStatus checks if the status means the quota is exhausted or the API is briefly unavailable.
+func adminAPIRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable
+}
+
+// adminAPIHTTPClient returns the client the connector calls the Cloud SQL Admin API with, authenticated with the
+// application default credentials like the connector does itself, retrying quota errors.
+func adminAPIHTTPClient(ctx context.Context, retries int) (*http.Client, error) {
+	transport, err := htransport.NewTransport(ctx, http.DefaultTransport, option.WithScopes(sqladmin.SqlserviceAdminScope))
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: &adminAPIRetryTransport{base: transport, retries: retries}}, nil
+}
+
+// adminAPIQuotaError checks if the error means the Cloud SQL Admin API quota is exhausted. The API answers 429, or
+// 403 with a rate limit reason. The connector doesn't always wrap the API error, so the message is checked too.
+func adminAPIQuotaError(err error) bool {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		if apiErr.Code == http.StatusTooManyRequests {
+			return true
+		}
+		for _, item := range apiErr.Errors {
+			if strings.Contains(strings.ToLower(item.Reason), "ratelimitexceeded") || item.Reason == "quotaExceeded" {
+				return true
+			}
+		}
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "ratelimitexceeded") || strings.Contains(message, "quota exceeded") ||
+		strings.Contains(message, "resource_exhausted") || strings.Contains(message, "error 429")
+}
+
+// waitRefreshJitter waits a random time up to jitter before the first certificate refresh, so the refreshes of many
+// provider aliases started together are spread out.
+func waitRefreshJitter(ctx context.Context, jitter time.Duration) {
+	if jitter <= 0 {
+		return
+	}
+	wait := time.Duration(rand.Int63n(int64(jitter)))
+	tflog.Debug(ctx, "Waiting "+wait.String()+" before the first certificate refresh, refresh_jitter is set")
+	select {
+	case <-ctx.Done():
+	case <-time.After(wait):
+	}
+}
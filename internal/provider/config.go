@@ -1,47 +1,678 @@
 package provider
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
+
+	"terraform-provider-cloudsqlmysql/internal/grantparser"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 type Config struct {
-	dsnTemplate     string
-	dbRegistry      map[string]*sql.DB
-	dbRegistryMutex sync.Mutex
+	connectionFactory ConnectionFactory
+	connector         *sharedConnector   // nil when the Cloud SQL connector is not used
+	dbRegistry        map[string]*sql.DB // The connection pools by database
+	dbRegistryCalls   map[string]*dbRegistryCall
+	dbRegistryMutex   sync.Mutex
+
+	serverSettingsMutex    sync.Mutex
+	serverSettingsDetected bool
+	lowerCaseTableNames    int64
+	serverVersion          serverVersion
+
+	auditRulesMutex     sync.Mutex
+	auditRulesDetected  bool
+	auditRulesAvailable bool
+	// Whether the audit plugin has the procedure that updates a rule in place, older versions can't change rules
+	auditRuleUpdateDetected  bool
+	auditRuleUpdateAvailable bool
+
+	statementCache      map[statementCacheKey]*sql.Stmt
+	statementCacheMutex sync.Mutex
+
+	allowSystemSchemas     bool
+	allowAnonymousAccounts bool
+	allowIndexDDL          bool   // cloudsqlmysql_index may create and drop indexes
+	defaultDatabase        string // the database of the pool of connectToMySQLNoDb, empty for none
+	// additionalAllowedPrivileges are accepted by unknownPrivilegeError on top of privilegeDefinitions
+	additionalAllowedPrivileges []string
+	auditRuleRetries            int
+	auditRuleLimit              int // 0 when the number of audit rules is not checked
+	connectTimeout              time.Duration
+	readTimeout                 time.Duration // 0 when the statements of reads have no timeout
+	writeTimeout                time.Duration // 0 when the statements of writes have no timeout
+	readSource                  string        // One of readSources, how the grants of accounts are read
+	readPageSize                int           // The mysql.db rows per query of mysqlTableGrants, 0 is defaultReadPageSize
+	verifyAfterApply            bool          // Read the grants back after they are applied
+	surfaceSQLWarnings          bool          // Report the SHOW WARNINGS of the executed statements
+	logSQL                      bool          // Log the executed statements, see withStatementLog
+	caseSensitivity             string        // One of caseSensitivities, empty is mysql
+
+	advisoryLockTimeout time.Duration // 0 when the writes are not serialized with the advisory lock
+	advisoryLockName    string
+	advisoryLockMutex   sync.Mutex
+	advisoryLockConn    *sql.Conn // The connection holding the advisory lock
+
+	// The connection settings, used to compare against the instance settings from the Cloud SQL Admin API
+	connectionName string
+	privateIP      bool
+	psc            bool
+}
+
+// dbExecutor is the part of *sql.DB the helpers that only run statements depend on, so they can run on a *sql.Conn,
+// a *sql.Tx or a test double.
+type dbExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// dbPool is the connection pool the resources and data sources run their statements on. Conn returns a dedicated
+// connection for the statements that share a session or are killed on cancellation, PrepareContext prepares the
+// cached statements. The tests pass the *sql.DB of a mocked driver.
+type dbPool interface {
+	dbExecutor
+	Conn(ctx context.Context) (*sql.Conn, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+var (
+	_ dbExecutor = (*sql.DB)(nil)
+	_ dbExecutor = (*sql.Conn)(nil)
+	_ dbPool     = (*sql.DB)(nil)
+)
+
+// ConnectionFactory opens a new connection pool of the database, the database is empty for the pool that doesn't
+// connect to a specific database unless the provider configuration sets database. The provider keeps the pools open
+// until the provider server stops.
+type ConnectionFactory func(ctx context.Context, database string) (*sql.DB, error)
+
+// connectionTarget is an instance the provider can connect to, the DSN template has a %s for the database.
+type connectionTarget struct {
+	connectionName string
+	dsnTemplate    string
+}
+
+// cloudSQLConnectionFactory opens the connection pools through the driver of the Cloud SQL connector. The targets are
+// tried in order, a pool of a fallback target is only used when the targets before it can't be reached within the timeout.
+func cloudSQLConnectionFactory(driverName string, targets []connectionTarget, timeout time.Duration) ConnectionFactory {
+	return func(ctx context.Context, database string) (*sql.DB, error) {
+		if len(targets) == 1 {
+			return sql.Open(driverName, fmt.Sprintf(targets[0].dsnTemplate, database))
+		}
+
+		var errs []error
+		for i, target := range targets {
+			db, err := sql.Open(driverName, fmt.Sprintf(target.dsnTemplate, database))
+			if err == nil {
+				pingCtx, cancel := context.WithTimeout(ctx, timeout)
+				err = db.PingContext(pingCtx)
+				cancel()
+				if err == nil {
+					if i > 0 {
+						tflog.Warn(ctx, "Connected to the fallback instance "+target.connectionName+", the instances before it are unreachable")
+					} else {
+						tflog.Debug(ctx, "Connected to the instance "+target.connectionName)
+					}
+					return db, nil
+				}
+				_ = db.Close()
+			}
+			tflog.Info(ctx, "Unable to connect to the instance "+target.connectionName+": "+err.Error())
+			errs = append(errs, fmt.Errorf("%s: %w", target.connectionName, err))
+		}
+		return nil, fmt.Errorf("none of the instances can be reached: %w", errors.Join(errs...))
+	}
+}
+
+// mysqlConnectionFactory opens the connection pools with the stock MySQL driver, the DSN template has a %s for
+// the database.
+func mysqlConnectionFactory(dsnTemplate string) ConnectionFactory {
+	return func(_ context.Context, database string) (*sql.DB, error) {
+		return sql.Open("mysql", fmt.Sprintf(dsnTemplate, database))
+	}
+}
+
+// defaultConnectTimeout is used when connect_timeout is not configured.
+const defaultConnectTimeout = 30 * time.Second
+
+// connMaxIdleTime closes the connections of a pool that are idle for longer, so a lingering plugin process doesn't
+// hold connections until the wait_timeout of the instance kills them.
+const connMaxIdleTime = time.Minute
+
+// maxDatabaseNameLength is the maximum length of a database name in characters.
+const maxDatabaseNameLength = 64
+
+// systemSchemas are managed by MySQL itself, changes to these can destabilize the instance.
+var systemSchemas = []string{"information_schema", "mysql", "performance_schema", "sys"}
+
+// statementCacheKey identifies a prepared statement, statements are prepared per connection pool.
+type statementCacheKey struct {
+	db    dbPool
+	query string
 }
 
-func newConfig(dsnTemplate string) *Config {
-	return &Config{
-		dbRegistry:  make(map[string]*sql.DB),
-		dsnTemplate: dsnTemplate,
+// openConfigs are the configs of the process, their connection pools are closed by CloseConnections.
+var (
+	openConfigs      []*Config
+	openConfigsMutex sync.Mutex
+)
+
+func newConfig(connectionFactory ConnectionFactory) *Config {
+	c := &Config{
+		connectionFactory: connectionFactory,
+		dbRegistry:        make(map[string]*sql.DB),
+		dbRegistryCalls:   make(map[string]*dbRegistryCall),
+		statementCache:    make(map[statementCacheKey]*sql.Stmt),
+		connectTimeout:    defaultConnectTimeout,
 	}
+
+	openConfigsMutex.Lock()
+	defer openConfigsMutex.Unlock()
+	openConfigs = append(openConfigs, c)
+	return c
 }
 
-func (c *Config) connectToMySQLNoDb() (*sql.DB, error) {
-	dsn := fmt.Sprintf(c.dsnTemplate, "")
-	return c.connectToMySQL(dsn)
+// CloseConnections closes the connection pools of all configured providers of the process, releasing the advisory
+// lock. It is called when the provider server stops.
+func CloseConnections() error {
+	openConfigsMutex.Lock()
+	defer openConfigsMutex.Unlock()
+
+	var errs []error
+	for _, c := range openConfigs {
+		errs = append(errs, c.close())
+	}
+	openConfigs = nil
+	return errors.Join(errs...)
+}
+
+// close closes the advisory lock connection, the prepared statements and the connection pools of the registry, and
+// releases the Cloud SQL connector.
+func (c *Config) close() error {
+	var errs []error
+
+	c.advisoryLockMutex.Lock()
+	if c.advisoryLockConn != nil {
+		errs = append(errs, c.advisoryLockConn.Close())
+		c.advisoryLockConn = nil
+	}
+	c.advisoryLockMutex.Unlock()
+
+	c.statementCacheMutex.Lock()
+	for key, stmt := range c.statementCache {
+		errs = append(errs, stmt.Close())
+		delete(c.statementCache, key)
+	}
+	c.statementCacheMutex.Unlock()
+
+	c.dbRegistryMutex.Lock()
+	defer c.dbRegistryMutex.Unlock()
+	for database, db := range c.dbRegistry {
+		if err := db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing the connection pool of '%s': %w", database, err))
+		}
+		delete(c.dbRegistry, database)
+	}
+	if c.connector != nil {
+		errs = append(errs, c.connector.release())
+		c.connector = nil
+	}
+	return errors.Join(errs...)
 }
 
-// func (c *Config) connectToMySQLDb(dbName string) (*sql.DB, error) {
-// 	dsn := fmt.Sprintf(c.dsnTemplate, dbName)
-// 	return c.connectToMySQL(dsn)
+// connectToMySQLNoDb returns the connection pool that doesn't connect to a specific database, its connections use
+// the database of the provider configuration as default database.
+func (c *Config) connectToMySQLNoDb(ctx context.Context) (*sql.DB, error) {
+	return c.connectToMySQL(ctx, "")
+}
+
+// func (c *Config) connectToMySQLDb(ctx context.Context, dbName string) (*sql.DB, error) {
+// 	return c.connectToMySQL(ctx, dbName)
 // }
 
-func (c *Config) connectToMySQL(dsn string) (*sql.DB, error) {
+// dbRegistryCall is a connection pool that is being opened, the callers for the same database wait for it instead of
+// opening a pool of their own.
+type dbRegistryCall struct {
+	done chan struct{}
+	db   *sql.DB
+	err  error
+}
+
+// connectToMySQL returns the connection pool of the database, there is only one pool per database. Pools of different
+// databases are opened concurrently, the concurrent callers for the same database share the pool that is being opened
+// and its error.
+func (c *Config) connectToMySQL(ctx context.Context, database string) (*sql.DB, error) {
+	c.dbRegistryMutex.Lock()
+	if db := c.dbRegistry[database]; db != nil {
+		c.dbRegistryMutex.Unlock()
+		return db, nil
+	}
+	if call, ok := c.dbRegistryCalls[database]; ok {
+		c.dbRegistryMutex.Unlock()
+		select {
+		case <-call.done:
+			return call.db, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &dbRegistryCall{done: make(chan struct{})}
+	c.dbRegistryCalls[database] = call
+	c.dbRegistryMutex.Unlock()
+
+	call.db, call.err = c.openPool(ctx, database)
+
+	c.dbRegistryMutex.Lock()
+	delete(c.dbRegistryCalls, database)
+	if call.err == nil {
+		c.dbRegistry[database] = call.db
+	}
+	c.dbRegistryMutex.Unlock()
+	close(call.done)
+	return call.db, call.err
+}
+
+// openPool opens a new connection pool of the database. The pool is pinged within connect_timeout, so a broken
+// network path fails the operation early instead of hanging on the first statement.
+func (c *Config) openPool(ctx context.Context, database string) (*sql.DB, error) {
+	dsnDatabase := database
+	if dsnDatabase == "" {
+		dsnDatabase = c.defaultDatabase
+	}
+	db, err := c.connectionFactory(ctx, dsnDatabase)
+	if err != nil {
+		return nil, err
+	}
+
+	pingCtx, cancel := c.withConnectTimeout(ctx)
+	defer cancel()
+	if err = db.PingContext(pingCtx); err != nil {
+		_ = db.Close()
+		return nil, c.connectError(err)
+	}
+
+	db.SetConnMaxIdleTime(connMaxIdleTime)
+	return db, nil
+}
+
+// defaultAdvisoryLockName is the name of the GET_LOCK lock that serializes the writes of concurrent Terraform runs
+// when advisory_lock_name isn't set.
+const defaultAdvisoryLockName = "tf-cloudsqlmysql"
+
+// acquireAdvisoryLock takes the advisory lock before the first write of the process when advisory_lock_timeout is set.
+// The lock is held on a dedicated connection until the provider process exits, so the GRANT and REVOKE statements of
+// competing runs on the same instance don't interleave. MySQL releases the lock when the connection closes. Provider
+// aliases of one run that take the same lock on the same instance wait for each other, they need different names.
+func (c *Config) acquireAdvisoryLock(ctx context.Context, db dbPool) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if c.advisoryLockTimeout == 0 {
+		return diags
+	}
+
+	c.advisoryLockMutex.Lock()
+	defer c.advisoryLockMutex.Unlock()
+	if c.advisoryLockConn != nil {
+		return diags
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		diags.AddError(
+			"Unable to acquire the advisory lock",
+			"Could not open a connection for the advisory lock '"+c.advisoryLockName+"', unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+
+	tflog.Debug(ctx, "Waiting up to "+c.advisoryLockTimeout.String()+" for the advisory lock '"+c.advisoryLockName+"'")
+	var acquired sql.NullInt64
+	// GET_LOCK waits up to advisory_lock_timeout, so it isn't limited by the statement timeout of queryRow
+	start := time.Now()
+	err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", c.advisoryLockName, int64(c.advisoryLockTimeout.Seconds())).Scan(&acquired)
+	logStatement(ctx, "SELECT GET_LOCK(?, ?)", 2, start, nil, err)
+	switch {
+	case err != nil:
+		diags.AddError(
+			"Unable to acquire the advisory lock",
+			"Could not acquire the advisory lock '"+c.advisoryLockName+"', unexpected error: "+err.Error(),
+		)
+	case !acquired.Valid || acquired.Int64 != 1:
+		diags.AddError(
+			"Unable to acquire the advisory lock",
+			"The advisory lock '"+c.advisoryLockName+"' is still held by another Terraform run after "+c.advisoryLockTimeout.String()+
+				". Try again when the other run finished, or raise advisory_lock_timeout",
+		)
+	}
+	if diags.HasError() {
+		_ = conn.Close()
+		return diags
+	}
+
+	tflog.Info(ctx, "Acquired the advisory lock '"+c.advisoryLockName+"'")
+	c.advisoryLockConn = conn
+	return diags
+}
+
+// withConnectTimeout returns a context that is cancelled after connect_timeout.
+func (c *Config) withConnectTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(c.withStatementLog(ctx), c.connectTimeout)
+}
+
+// withReadTimeout sets read_timeout as the timeout of every statement of a Read.
+func (c *Config) withReadTimeout(ctx context.Context) context.Context {
+	return withStatementTimeout(c.withStatementLog(ctx), c.readTimeout)
+}
+
+// withWriteTimeout sets write_timeout as the timeout of every statement of a Create, Update or Delete, including the
+// statements that read back what was written.
+func (c *Config) withWriteTimeout(ctx context.Context) context.Context {
+	return withStatementTimeout(c.withStatementLog(ctx), c.writeTimeout)
+}
+
+// withSQLWarnings collects the warnings of the statements of a Create, Update or Delete when surface_sql_warnings
+// is set, the returned collector is nil otherwise.
+func (c *Config) withSQLWarnings(ctx context.Context) (context.Context, *sqlWarnings) {
+	if !c.surfaceSQLWarnings {
+		return ctx, nil
+	}
+	warnings := &sqlWarnings{}
+	return context.WithValue(ctx, sqlWarningsKey{}, warnings), warnings
+}
+
+// connectError explains errors caused by connect_timeout, other errors are returned as they are.
+func (c *Config) connectError(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("the Cloud SQL MySQL instance did not respond within the connect_timeout of %s, check the network path "+
+			"to the instance: the private_ip, psc and proxy settings and the firewall rules. Error: %w", c.connectTimeout, err)
+	}
+	return err
+}
+
+// connectionPoolStats are the statistics of a connection pool in the registry.
+type connectionPoolStats struct {
+	database string
+	stats    sql.DBStats
+}
+
+// connectionStats returns the statistics of all connection pools ordered by database.
+func (c *Config) connectionStats() []connectionPoolStats {
 	c.dbRegistryMutex.Lock()
 	defer c.dbRegistryMutex.Unlock()
 
-	if c.dbRegistry[dsn] != nil {
-		return c.dbRegistry[dsn], nil
+	var pools []connectionPoolStats
+	for database, db := range c.dbRegistry {
+		pools = append(pools, connectionPoolStats{database: database, stats: db.Stats()})
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		return pools[i].database < pools[j].database
+	})
+	return pools
+}
+
+// detectServerSettings queries the server settings that influence how the provider needs to compare values.
+// The settings are only queried once per provider configuration.
+func (c *Config) detectServerSettings(ctx context.Context, db dbPool) error {
+	c.serverSettingsMutex.Lock()
+	defer c.serverSettingsMutex.Unlock()
+
+	if c.serverSettingsDetected {
+		return nil
+	}
+
+	queryCtx, cancel := c.withConnectTimeout(ctx)
+	defer cancel()
+
+	var version string
+	err := queryRow(queryCtx, db, "SELECT @@GLOBAL.lower_case_table_names, @@GLOBAL.version", nil, &c.lowerCaseTableNames, &version)
+	if err != nil {
+		return c.connectError(err)
+	}
+
+	c.serverVersion, err = parseServerVersion(version)
+	if err != nil {
+		return err
+	}
+
+	c.serverSettingsDetected = true
+	return nil
+}
+
+// detectAuditRules checks once per provider configuration if the audit stored procedures of Cloud SQL are
+// installed, they are only installed when the cloudsql_mysql_audit instance flag is enabled.
+func (c *Config) detectAuditRules(ctx context.Context, db dbPool) (bool, error) {
+	c.auditRulesMutex.Lock()
+	defer c.auditRulesMutex.Unlock()
+
+	if c.auditRulesDetected {
+		return c.auditRulesAvailable, nil
+	}
+
+	// The session variables are used by the procedure, a dedicated connection keeps them away from the audit rule resources
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	rows, err := queryContext(ctx, conn, "CALL mysql.cloudsql_list_audit_rule('*',@outval,@outmsg);")
+	if err != nil && !auditProcedureMissing(err) {
+		return false, err
+	}
+	if err == nil {
+		_ = rows.Close()
+	}
+
+	c.auditRulesAvailable = err == nil
+	c.auditRulesDetected = true
+	return c.auditRulesAvailable, nil
+}
+
+// detectAuditRuleUpdate detects once whether the audit plugin has the mysql.cloudsql_update_audit_rule procedure.
+// Without it a changed rule can't be updated in place and is recreated instead.
+func (c *Config) detectAuditRuleUpdate(ctx context.Context, db dbPool) (bool, error) {
+	c.auditRulesMutex.Lock()
+	defer c.auditRulesMutex.Unlock()
+
+	if c.auditRuleUpdateDetected {
+		return c.auditRuleUpdateAvailable, nil
+	}
+
+	var routines int64
+	err := c.queryRowPrepared(ctx, db, "SELECT COUNT(*) FROM INFORMATION_SCHEMA.ROUTINES WHERE ROUTINE_SCHEMA = 'mysql' AND "+
+		"ROUTINE_NAME = 'cloudsql_update_audit_rule'", nil, &routines)
+	if err != nil {
+		return false, err
+	}
+
+	c.auditRuleUpdateAvailable = routines > 0
+	c.auditRuleUpdateDetected = true
+	if !c.auditRuleUpdateAvailable {
+		tflog.Info(ctx, "The audit plugin has no mysql.cloudsql_update_audit_rule procedure, changed audit rules are recreated")
+	}
+	return c.auditRuleUpdateAvailable, nil
+}
+
+// databaseNameForLookup returns the database name as it's stored by the server.
+// With lower_case_table_names set to 1 or 2 the server stores database names in lowercase in the system tables.
+func (c *Config) databaseNameForLookup(database string) string {
+	if c.lowerCaseTableNames == 0 {
+		return database
+	}
+	return strings.ToLower(database)
+}
+
+// databaseNamesEqual compares database names the same way the server does.
+func (c *Config) databaseNamesEqual(a, b string) bool {
+	if c.lowerCaseTableNames == 0 {
+		return a == b
+	}
+	return strings.EqualFold(a, b)
+}
+
+// serverVersion is the MySQL version of the server, without the suffix like -google or -log.
+type serverVersion struct {
+	major int
+	minor int
+	patch int
+}
+
+var serverVersionRegex = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)`)
+
+func parseServerVersion(version string) (serverVersion, error) {
+	matches := serverVersionRegex.FindStringSubmatch(version)
+	if matches == nil {
+		return serverVersion{}, fmt.Errorf("unable to parse the server version %q", version)
+	}
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+	patch, _ := strconv.Atoi(matches[3])
+	return serverVersion{major: major, minor: minor, patch: patch}, nil
+}
+
+func (v serverVersion) less(other serverVersion) bool {
+	if v.major != other.major {
+		return v.major < other.major
 	}
+	if v.minor != other.minor {
+		return v.minor < other.minor
+	}
+	return v.patch < other.patch
+}
+
+func (v serverVersion) isZero() bool {
+	return v == serverVersion{}
+}
+
+func (v serverVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
+}
 
-	db, err := sql.Open("cloudsql-mysql", dsn)
+// preparedStatement returns the cached prepared statement for the query or prepares it. Refreshing many resources
+// executes the same queries, preparing them once saves a round trip per query.
+func (c *Config) preparedStatement(ctx context.Context, db dbPool, query string) (*sql.Stmt, error) {
+	c.statementCacheMutex.Lock()
+	defer c.statementCacheMutex.Unlock()
+
+	key := statementCacheKey{db: db, query: query}
+	if stmt, ok := c.statementCache[key]; ok {
+		return stmt, nil
+	}
+
+	stmt, err := db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
+	if c.statementCache == nil {
+		c.statementCache = make(map[statementCacheKey]*sql.Stmt)
+	}
+	c.statementCache[key] = stmt
+	return stmt, nil
+}
+
+// invalidatePreparedStatement removes the prepared statement from the cache when the error is a connection error,
+// the statement is prepared again on the next use.
+func (c *Config) invalidatePreparedStatement(db dbPool, query string, err error) {
+	if !isConnectionError(err) {
+		return
+	}
+
+	c.statementCacheMutex.Lock()
+	defer c.statementCacheMutex.Unlock()
+
+	key := statementCacheKey{db: db, query: query}
+	if stmt, ok := c.statementCache[key]; ok {
+		stmt.Close()
+		delete(c.statementCache, key)
+	}
+}
 
-	c.dbRegistry[dsn] = db
-	return c.dbRegistry[dsn], nil
+// queryRowPrepared executes the query returning a single row with a cached prepared statement and scans the row into dest.
+func (c *Config) queryRowPrepared(ctx context.Context, db dbPool, query string, args []any, dest ...any) error {
+	stmt, err := c.preparedStatement(ctx, db, query)
+	if err != nil {
+		return err
+	}
+
+	stmtCtx, cancel := statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	err = stmt.QueryRowContext(stmtCtx, args...).Scan(dest...)
+	logStatement(ctx, query, len(args), start, nil, err)
+	c.invalidatePreparedStatement(db, query, err)
+	return err
+}
+
+// queryRowsPrepared runs the query like queryRows, with a cached prepared statement when the query has arguments and
+// runs on a connection pool. The MySQL driver prepares and closes a statement for every ad-hoc query with arguments,
+// a query without arguments is sent as text in a single round trip and isn't prepared.
+func (c *Config) queryRowsPrepared(ctx context.Context, db dbExecutor, query string, args []any, scan func(rows *sql.Rows) error) error {
+	pool, ok := db.(dbPool)
+	if !ok || len(args) == 0 {
+		return queryRows(ctx, db, query, args, scan)
+	}
+	stmt, err := c.preparedStatement(ctx, pool, query)
+	if err != nil {
+		return err
+	}
+
+	stmtCtx, cancel := statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	rows, err := stmt.QueryContext(stmtCtx, args...)
+	logStatement(ctx, query, len(args), start, nil, err)
+	c.invalidatePreparedStatement(pool, query, err)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func isConnectionError(err error) bool {
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, sql.ErrConnDone)
+}
+
+// systemSchemaError returns an error message when the database is a system schema and changes to
+// system schemas are not allowed.
+func (c *Config) systemSchemaError(database string) string {
+	if c.allowSystemSchemas {
+		return ""
+	}
+	for _, systemSchema := range systemSchemas {
+		if strings.EqualFold(database, systemSchema) {
+			return "The database '" + database + "' is a MySQL system schema, changes to it can destabilize the instance. " +
+				"Set `allow_system_schemas = true` in the provider configuration to allow it."
+		}
+	}
+	return ""
+}
+
+// systemSchemaGrantError returns an error message when the grant gives privileges on a system schema and changes to
+// system schemas are not allowed. Revokes only take privileges away, they are allowed.
+func (c *Config) systemSchemaGrantError(grant *grantparser.Grant) string {
+	if grant.Revoke {
+		return ""
+	}
+	return c.systemSchemaError(grant.Database)
 }
@@ -0,0 +1,258 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+func TestAcquireAdvisoryLock(t *testing.T) {
+	db, mock := newMockDB(t)
+	config := &Config{advisoryLockTimeout: 5 * time.Second, advisoryLockName: defaultAdvisoryLockName}
+	mock.ExpectQuery("SELECT GET_LOCK(?, ?)").WithArgs(defaultAdvisoryLockName, int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"GET_LOCK"}).AddRow(1))
+
+	if diags := config.acquireAdvisoryLock(context.Background(), db); diags.HasError() {
+		t.Fatalf("acquireAdvisoryLock returned %v", diags)
+	}
+	// The lock is held by the provider until it closes its connections
+	if diags := config.acquireAdvisoryLock(context.Background(), db); diags.HasError() {
+		t.Fatalf("acquireAdvisoryLock returned %v", diags)
+	}
+	if config.advisoryLockConn == nil {
+		t.Fatal("acquireAdvisoryLock didn't keep the connection")
+	}
+	config.advisoryLockConn.Close()
+}
+
+func TestAcquireAdvisoryLockTimeout(t *testing.T) {
+	db, mock := newMockDB(t)
+	config := &Config{advisoryLockTimeout: time.Second, advisoryLockName: "tf-cloudsqlmysql-admin"}
+	mock.ExpectQuery("SELECT GET_LOCK(?, ?)").WithArgs("tf-cloudsqlmysql-admin", int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"GET_LOCK"}).AddRow(0))
+
+	diags := config.acquireAdvisoryLock(context.Background(), db)
+	if !diags.HasError() {
+		t.Fatal("acquireAdvisoryLock returned no error when the lock is held")
+	}
+	if config.advisoryLockConn != nil {
+		t.Error("acquireAdvisoryLock kept the connection without the lock")
+	}
+}
+
+func TestAcquireAdvisoryLockDisabled(t *testing.T) {
+	db, _ := newMockDB(t)
+	if diags := (&Config{}).acquireAdvisoryLock(context.Background(), db); diags.HasError() {
+		t.Errorf("acquireAdvisoryLock without a timeout returned %v", diags)
+	}
+}
+
+func TestDetectServerSettings(t *testing.T) {
+	db, mock := newMockDB(t)
+	config := &Config{connectTimeout: defaultConnectTimeout}
+	mock.ExpectQuery("SELECT @@GLOBAL.lower_case_table_names, @@GLOBAL.version").
+		WillReturnRows(sqlmock.NewRows([]string{"lower_case_table_names", "version"}).AddRow(1, "8.0.36-google"))
+
+	if err := config.detectServerSettings(context.Background(), db); err != nil {
+		t.Fatalf("detectServerSettings returned error: %v", err)
+	}
+	// Detected once per provider configuration
+	if err := config.detectServerSettings(context.Background(), db); err != nil {
+		t.Fatalf("detectServerSettings returned error: %v", err)
+	}
+	if config.lowerCaseTableNames != 1 || config.serverVersion != (serverVersion{major: 8, patch: 36}) {
+		t.Errorf("detectServerSettings detected %d and %v", config.lowerCaseTableNames, config.serverVersion)
+	}
+}
+
+// countingConnectionFactory returns a ConnectionFactory of sqlmock pools that counts the pools it opens by database.
+// Opening a pool takes a moment, so the concurrent callers overlap while the first pool is being opened.
+func countingConnectionFactory(t *testing.T, expect func(mock sqlmock.Sqlmock)) (ConnectionFactory, func(database string) int) {
+	var mutex sync.Mutex
+	opened := make(map[string]int)
+	factory := func(ctx context.Context, database string) (*sql.DB, error) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		if err != nil {
+			return nil, err
+		}
+		expect(mock)
+		t.Cleanup(func() { db.Close() })
+
+		time.Sleep(10 * time.Millisecond)
+		mutex.Lock()
+		defer mutex.Unlock()
+		opened[database]++
+		return db, nil
+	}
+	count := func(database string) int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return opened[database]
+	}
+	return factory, count
+}
+
+func TestConcurrentConfigure(t *testing.T) {
+	factory, opened := countingConnectionFactory(t, func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("SELECT @@GLOBAL.lower_case_table_names, @@GLOBAL.version").
+			WillReturnRows(sqlmock.NewRows([]string{"lower_case_table_names", "version"}).AddRow(0, "8.0.36"))
+	})
+	config := newConfig(factory)
+	t.Cleanup(func() { _ = config.close() })
+
+	var wg sync.WaitGroup
+	resources := make([]*databaseGrantResource, 20)
+	responses := make([]*resource.ConfigureResponse, len(resources))
+	for i := range resources {
+		resources[i], responses[i] = &databaseGrantResource{}, &resource.ConfigureResponse{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resources[i].Configure(context.Background(), resource.ConfigureRequest{ProviderData: config}, responses[i])
+		}(i)
+	}
+	wg.Wait()
+
+	if n := opened(""); n != 1 {
+		t.Errorf("concurrent Configure calls opened %d pools, want 1", n)
+	}
+	for i := range resources {
+		if responses[i].Diagnostics.HasError() {
+			t.Fatalf("Configure returned %v", responses[i].Diagnostics)
+		}
+		if resources[i].db != resources[0].db {
+			t.Errorf("resource %d was configured with another pool", i)
+		}
+	}
+}
+
+func TestConcurrentConnectToMySQL(t *testing.T) {
+	factory, opened := countingConnectionFactory(t, func(mock sqlmock.Sqlmock) {})
+	config := newConfig(factory)
+	t.Cleanup(func() { _ = config.close() })
+
+	databases := []string{"", "app", "reporting"}
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func(database string) {
+			defer wg.Done()
+			if _, err := config.connectToMySQL(context.Background(), database); err != nil {
+				t.Errorf("connectToMySQL returned error: %v", err)
+			}
+		}(databases[i%len(databases)])
+	}
+	wg.Wait()
+
+	for _, database := range databases {
+		if n := opened(database); n != 1 {
+			t.Errorf("connectToMySQL opened %d pools of '%s', want 1", n, database)
+		}
+	}
+}
+
+func TestConnectToMySQLErrorNotCached(t *testing.T) {
+	calls := 0
+	config := newConfig(func(ctx context.Context, database string) (*sql.DB, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("dial tcp: connection refused")
+		}
+		db, _, err := sqlmock.New()
+		return db, err
+	})
+	t.Cleanup(func() { _ = config.close() })
+
+	if _, err := config.connectToMySQL(context.Background(), "app"); err == nil {
+		t.Fatal("connectToMySQL returned no error when the factory failed")
+	}
+	// The failed pool is not in the registry, the next call opens a new one
+	if _, err := config.connectToMySQL(context.Background(), "app"); err != nil || calls != 2 {
+		t.Errorf("connectToMySQL after a failure returned %v after %d calls", err, calls)
+	}
+}
+
+// BenchmarkQueryRow compares the prepared statements of the statement cache with ad-hoc queries on a server with
+// 100µs of latency. An ad-hoc query with arguments prepares, executes and closes a statement, three round trips,
+// the cached statement is only executed. The grant reads of a refresh are measured per read_source: SHOW GRANTS
+// has no arguments and is sent as text, the queries of mysql_tables are prepared once per pool.
+func BenchmarkQueryRow(b *testing.B) {
+	const query = "SELECT 1 FROM mysql.user WHERE User = ? AND Host = ?"
+	results := map[string]roundTripResult{
+		"SHOW GRANTS FOR 'app'@'%'": {columns: []string{"Grants"}, rows: [][]driver.Value{
+			{"GRANT USAGE ON *.* TO `app`@`%`"},
+			{"GRANT SELECT, INSERT ON `app`.* TO `app`@`%`"},
+		}},
+		"SELECT * FROM mysql.user WHERE User = ? AND Host = ?": {columns: []string{"Host", "User", "Select_priv"}, rows: [][]driver.Value{
+			{"%", "app", "N"},
+		}},
+		mysqlDBPageQuery: {columns: []string{"Db", "Select_priv", "Insert_priv"}, rows: [][]driver.Value{
+			{"app", "Y", "Y"},
+		}},
+		"SELECT Db, Routine_name, Routine_type, Proc_priv FROM mysql.procs_priv WHERE User = ? AND Host = ?": {
+			columns: []string{"Db", "Routine_name", "Routine_type", "Proc_priv"},
+		},
+	}
+	readGrants := func(readSource string) func(ctx context.Context, config *Config, db dbExecutor) error {
+		return func(ctx context.Context, config *Config, db dbExecutor) error {
+			config.readSource = readSource
+			_, err := config.readGrants(ctx, db, "app", "%")
+			return err
+		}
+	}
+	benchmarks := []struct {
+		name   string
+		adHoc  bool
+		runSQL func(ctx context.Context, config *Config, db dbExecutor) error
+	}{
+		{
+			name:  "ad-hoc",
+			adHoc: true,
+			runSQL: func(ctx context.Context, config *Config, db dbExecutor) error {
+				var exists int
+				return queryRow(ctx, db, query, []any{"app", "%"}, &exists)
+			},
+		},
+		{
+			name: "prepared",
+			runSQL: func(ctx context.Context, config *Config, db dbExecutor) error {
+				var exists int
+				return config.queryRowPrepared(ctx, db.(dbPool), query, []any{"app", "%"}, &exists)
+			},
+		},
+		{name: "show_grants", runSQL: readGrants(readSourceShowGrants)},
+		{name: "mysql_tables ad-hoc", adHoc: true, runSQL: readGrants(readSourceMySQLTables)},
+		{name: "mysql_tables prepared", runSQL: readGrants(readSourceMySQLTables)},
+	}
+	for _, benchmark := range benchmarks {
+		b.Run(benchmark.name, func(b *testing.B) {
+			roundTrips := &roundTripDriver{latency: 100 * time.Microsecond, results: results}
+			pool := sql.OpenDB(roundTrips)
+			config := newConfig(nil)
+			b.Cleanup(func() {
+				_ = config.close()
+				pool.Close()
+			})
+			var db dbExecutor = pool
+			if benchmark.adHoc {
+				// Without the methods of a dbPool the statement cache is not used
+				db = struct{ dbExecutor }{pool}
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := benchmark.runSQL(context.Background(), config, db); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(roundTrips.roundTrips.Load())/float64(b.N), "roundtrips/op")
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"terraform-provider-cloudsqlmysql/internal/grantparser"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	resourceschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &accessReportDataSource{}
+	_ datasource.DataSourceWithConfigure = &accessReportDataSource{}
+)
+
+// labelsAttribute returns the schema of the labels of the grant, role and user resources. The labels are only kept
+// in the Terraform state and passed to cloudsqlmysql_access_report.
+func labelsAttribute() resourceschema.MapAttribute {
+	return resourceschema.MapAttribute{
+		Description: "Labels to group the resource in access reviews, e.g. the owning team or the review cycle. They are only " +
+			"stored in the Terraform state, pass them to the cloudsqlmysql_access_report data source",
+		MarkdownDescription: "Labels to group the resource in access reviews, e.g. the owning team or the review cycle. They are only " +
+			"stored in the Terraform state, pass them to the `cloudsqlmysql_access_report` data source",
+		ElementType: types.StringType,
+		Optional:    true,
+	}
+}
+
+func newAccessReportDataSource() datasource.DataSource {
+	return &accessReportDataSource{}
+}
+
+type accessReportDataSourceModel struct {
+	Entries    []accessReportEntryModel `tfsdk:"entries"`
+	ReportJSON types.String             `tfsdk:"report_json"`
+}
+
+type accessReportEntryModel struct {
+	User     types.String `tfsdk:"user"`
+	Host     types.String `tfsdk:"host"`
+	Database types.String `tfsdk:"database"`
+	Labels   types.Map    `tfsdk:"labels"`
+}
+
+// accessReportEntry is an entry of report_json.
+type accessReportEntry struct {
+	Account  string              `json:"account"`
+	Database string              `json:"database,omitempty"`
+	Labels   map[string]string   `json:"labels"`
+	Roles    []string            `json:"roles"`
+	Grants   []accessReportGrant `json:"grants"`
+}
+
+type accessReportGrant struct {
+	Level           string   `json:"level"`
+	Database        string   `json:"database,omitempty"`
+	Object          string   `json:"object,omitempty"`
+	Privileges      []string `json:"privileges"`
+	WithGrantOption bool     `json:"with_grant_option"`
+}
+
+type accessReportDataSource struct {
+	db     dbPool
+	config *Config
+}
+
+func (d *accessReportDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_access_report"
+}
+
+func (d *accessReportDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Builds a JSON report of the grants of the managed accounts with their labels for periodic access reviews. " +
+			"The grants are read from the server, so the report also shows the privileges granted outside of Terraform",
+		MarkdownDescription: "Builds a JSON report of the grants of the managed accounts with their labels for periodic access reviews. " +
+			"The grants are read from the server, so the report also shows the privileges granted outside of Terraform",
+		Attributes: map[string]schema.Attribute{
+			"entries": schema.ListNestedAttribute{
+				Description: "The accounts in the report, usually built from the user, role, host, database and labels attributes " +
+					"of the managed resources",
+				MarkdownDescription: "The accounts in the report, usually built from the `user`, `role`, `host`, `database` and `labels` attributes " +
+					"of the managed resources",
+				Required: true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"user": schema.StringAttribute{
+							Description:         "The name of the user or role",
+							MarkdownDescription: "The name of the user or role",
+							Required:            true,
+						},
+						"host": schema.StringAttribute{
+							Description:         "The host of the user or role. Default: %",
+							MarkdownDescription: "The host of the user or role. Default: `%`",
+							Optional:            true,
+							Validators: []validator.String{
+								hostValidator{},
+							},
+						},
+						"database": schema.StringAttribute{
+							Description:         "Only report the grants on this database. All grants of the account are reported when not set",
+							MarkdownDescription: "Only report the grants on this database. All grants of the account are reported when not set",
+							Optional:            true,
+						},
+						"labels": schema.MapAttribute{
+							Description:         "The labels of the entry",
+							MarkdownDescription: "The labels of the entry",
+							ElementType:         types.StringType,
+							Optional:            true,
+						},
+					},
+				},
+			},
+			"report_json": schema.StringAttribute{
+				Description: "The report as JSON array with an object per entry: account, database, labels, roles with the roles " +
+					"granted to the account, and grants with the level, database, object, privileges and with_grant_option of each grant",
+				MarkdownDescription: "The report as JSON array with an object per entry: `account`, `database`, `labels`, `roles` with the roles " +
+					"granted to the account, and `grants` with the `level`, `database`, `object`, `privileges` and `with_grant_option` of each grant",
+				Computed: true,
+			},
+		},
+	}
+}
+
+func (d *accessReportDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = d.config.withReadTimeout(ctx)
+
+	var state accessReportDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	report := []accessReportEntry{}
+	for _, entry := range state.Entries {
+		host := "%"
+		if !entry.Host.IsNull() {
+			host = entry.Host.ValueString()
+		}
+		account := d.config.quoteAccount(entry.User.ValueString(), host)
+
+		grants, err := d.config.readGrants(ctx, d.db, entry.User.ValueString(), host)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error building the access report",
+				"Could not read the grants of "+account+", unexpected error: "+err.Error(),
+			)
+			return
+		}
+
+		reportEntry := accessReportEntry{
+			Account:  account,
+			Database: entry.Database.ValueString(),
+			Labels:   map[string]string{},
+			Roles:    []string{},
+			Grants:   []accessReportGrant{},
+		}
+		if !entry.Labels.IsNull() {
+			resp.Diagnostics.Append(entry.Labels.ElementsAs(ctx, &reportEntry.Labels, false)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+		for _, grant := range grants {
+			if grant.Revoke {
+				continue
+			}
+			if grant.Level == grantparser.LevelRole {
+				for _, role := range grant.Roles {
+					reportEntry.Roles = append(reportEntry.Roles, role.String())
+				}
+				continue
+			}
+			if !entry.Database.IsNull() && !d.config.databaseNamesEqual(grant.Database, entry.Database.ValueString()) {
+				continue
+			}
+			object := grant.Object
+			if grant.Level == grantparser.LevelProxy {
+				object = grant.Proxied.String()
+			}
+			reportEntry.Grants = append(reportEntry.Grants, accessReportGrant{
+				Level:           grant.Level.String(),
+				Database:        grant.Database,
+				Object:          object,
+				Privileges:      grant.PrivilegeNames(),
+				WithGrantOption: grant.WithGrantOption,
+			})
+		}
+		report = append(report, reportEntry)
+	}
+
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		resp.Diagnostics.AddError("Error building the access report", "Could not encode the report, unexpected error: "+err.Error())
+		return
+	}
+	state.ReportJSON = types.StringValue(string(reportJSON))
+
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *accessReportDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to the Cloud SQL MySQL instance",
+			err.Error(),
+		)
+		return
+	}
+
+	err = config.detectServerSettings(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read the Cloud SQL MySQL server settings",
+			err.Error(),
+		)
+		return
+	}
+
+	d.db = db
+	d.config = config
+}
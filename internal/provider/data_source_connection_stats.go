@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &connectionStatsDataSource{}
+	_ datasource.DataSourceWithConfigure = &connectionStatsDataSource{}
+)
+
+func newConnectionStatsDataSource() datasource.DataSource {
+	return &connectionStatsDataSource{}
+}
+
+type connectionStatsDataSourceModel struct {
+	Pools []connectionPoolModel `tfsdk:"pools"`
+}
+
+type connectionPoolModel struct {
+	Database           types.String `tfsdk:"database"`
+	MaxOpenConnections types.Int64  `tfsdk:"max_open_connections"`
+	OpenConnections    types.Int64  `tfsdk:"open_connections"`
+	InUse              types.Int64  `tfsdk:"in_use"`
+	Idle               types.Int64  `tfsdk:"idle"`
+	WaitCount          types.Int64  `tfsdk:"wait_count"`
+	WaitDurationMs     types.Int64  `tfsdk:"wait_duration_ms"`
+	MaxIdleClosed      types.Int64  `tfsdk:"max_idle_closed"`
+	MaxLifetimeClosed  types.Int64  `tfsdk:"max_lifetime_closed"`
+}
+
+type connectionStatsDataSource struct {
+	config *Config
+}
+
+func (d *connectionStatsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_connection_stats"
+}
+
+func (d *connectionStatsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reports the statistics of the connection pools of the provider, one pool per database the provider connected to. " +
+			"Only the pools opened before the data source is read are included, so it is mostly useful to troubleshoot waits in CI",
+		MarkdownDescription: "Reports the statistics of the connection pools of the provider, one pool per database the provider connected to. " +
+			"Only the pools opened before the data source is read are included, so it is mostly useful to troubleshoot waits in CI",
+		Attributes: map[string]schema.Attribute{
+			"pools": schema.ListNestedAttribute{
+				Description:         "The connection pools, ordered by database",
+				MarkdownDescription: "The connection pools, ordered by database",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"database": schema.StringAttribute{
+							Description:         "The database of the pool, empty for the pool that does not connect to a specific database",
+							MarkdownDescription: "The database of the pool, empty for the pool that does not connect to a specific database",
+							Computed:            true,
+						},
+						"max_open_connections": schema.Int64Attribute{
+							Description:         "The maximum number of open connections, 0 when unlimited",
+							MarkdownDescription: "The maximum number of open connections, `0` when unlimited",
+							Computed:            true,
+						},
+						"open_connections": schema.Int64Attribute{
+							Description:         "The number of open connections, in use and idle",
+							MarkdownDescription: "The number of open connections, in use and idle",
+							Computed:            true,
+						},
+						"in_use": schema.Int64Attribute{
+							Description:         "The number of connections in use",
+							MarkdownDescription: "The number of connections in use",
+							Computed:            true,
+						},
+						"idle": schema.Int64Attribute{
+							Description:         "The number of idle connections",
+							MarkdownDescription: "The number of idle connections",
+							Computed:            true,
+						},
+						"wait_count": schema.Int64Attribute{
+							Description:         "The total number of times a connection had to be waited for",
+							MarkdownDescription: "The total number of times a connection had to be waited for",
+							Computed:            true,
+						},
+						"wait_duration_ms": schema.Int64Attribute{
+							Description:         "The total time in milliseconds spent waiting for a connection",
+							MarkdownDescription: "The total time in milliseconds spent waiting for a connection",
+							Computed:            true,
+						},
+						"max_idle_closed": schema.Int64Attribute{
+							Description:         "The total number of connections closed because of the maximum of idle connections",
+							MarkdownDescription: "The total number of connections closed because of the maximum of idle connections",
+							Computed:            true,
+						},
+						"max_lifetime_closed": schema.Int64Attribute{
+							Description:         "The total number of connections closed because of their maximum lifetime",
+							MarkdownDescription: "The total number of connections closed because of their maximum lifetime",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *connectionStatsDataSource) Read(ctx context.Context, _ datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state connectionStatsDataSourceModel
+
+	state.Pools = []connectionPoolModel{}
+	for _, pool := range d.config.connectionStats() {
+		state.Pools = append(state.Pools, connectionPoolModel{
+			Database:           types.StringValue(pool.database),
+			MaxOpenConnections: types.Int64Value(int64(pool.stats.MaxOpenConnections)),
+			OpenConnections:    types.Int64Value(int64(pool.stats.OpenConnections)),
+			InUse:              types.Int64Value(int64(pool.stats.InUse)),
+			Idle:               types.Int64Value(int64(pool.stats.Idle)),
+			WaitCount:          types.Int64Value(pool.stats.WaitCount),
+			WaitDurationMs:     types.Int64Value(pool.stats.WaitDuration.Milliseconds()),
+			MaxIdleClosed:      types.Int64Value(pool.stats.MaxIdleClosed),
+			MaxLifetimeClosed:  types.Int64Value(pool.stats.MaxLifetimeClosed),
+		})
+	}
+
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *connectionStatsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.config = config
+}
@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -28,7 +31,8 @@ type databaseDataSourceModel struct {
 }
 
 type databaseDataSource struct {
-	db *sql.DB
+	db     dbPool
+	config *Config
 }
 
 func (d *databaseDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -40,33 +44,60 @@ func (d *databaseDataSource) Schema(_ context.Context, _ datasource.SchemaReques
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"name": schema.StringAttribute{
+				Description: "The name of the database. When the name comes from a resource that is not created yet, " +
+					"Terraform reads the data source during apply once the name is known",
+				MarkdownDescription: "The name of the database. When the name comes from a resource that is not created yet, " +
+					"Terraform reads the data source during apply once the name is known",
 				Required: true,
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, maxDatabaseNameLength),
+				},
 			},
 			"default_character_set": schema.StringAttribute{
-				Computed: true,
+				Description:         "The default character set of the database",
+				MarkdownDescription: "The default character set of the database",
+				Computed:            true,
 			},
 			"default_collation": schema.StringAttribute{
-				Computed: true,
+				Description:         "The default collation of the database",
+				MarkdownDescription: "The default collation of the database",
+				Computed:            true,
 			},
 		},
 	}
 }
 
 func (d *databaseDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = d.config.withReadTimeout(ctx)
+
 	var state databaseDataSourceModel
 
 	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	database := state.Name.ValueString()
-	row := d.db.QueryRowContext(ctx, "SELECT SCHEMA_NAME, DEFAULT_CHARACTER_SET_NAME, DEFAULT_COLLATION_NAME "+
-		"FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME = ?", database)
+	// Terraform defers the read until the name is known, this only happens when the data source is read
+	// during plan with a name that depends on a resource that is not applied yet
+	if state.Name.IsUnknown() || state.Name.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("name"),
+			"Database name not known",
+			"The database can't be read as the name is not known yet. When the name comes from a resource that is created "+
+				"in the same apply, reference an attribute of that resource or add it to depends_on so the data source is read after it's created.")
+		return
+	}
 
+	database := state.Name.ValueString()
 	var (
 		name                string
 		defaultCharacterSet string
 		defaultCollation    string
 	)
-	if err := row.Scan(&name, &defaultCharacterSet, &defaultCollation); err != nil {
+	err := d.config.queryRowPrepared(ctx, d.db, "SELECT SCHEMA_NAME, DEFAULT_CHARACTER_SET_NAME, DEFAULT_COLLATION_NAME "+
+		"FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME = ?", []any{d.config.databaseNameForLookup(database)},
+		&name, &defaultCharacterSet, &defaultCollation)
+	if err != nil {
 		if err == sql.ErrNoRows {
 			resp.Diagnostics.AddError(
 				"Database not found",
@@ -80,7 +111,9 @@ func (d *databaseDataSource) Read(ctx context.Context, req datasource.ReadReques
 		return
 	}
 
-	state.Name = types.StringValue(name)
+	if !d.config.databaseNamesEqual(name, database) {
+		state.Name = types.StringValue(name)
+	}
 	state.DefaultCharacterSet = types.StringValue(defaultCharacterSet)
 	state.DefaultCollation = types.StringValue(defaultCollation)
 
@@ -88,7 +121,7 @@ func (d *databaseDataSource) Read(ctx context.Context, req datasource.ReadReques
 	resp.Diagnostics.Append(diags...)
 }
 
-func (d *databaseDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+func (d *databaseDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
 	}
@@ -102,7 +135,7 @@ func (d *databaseDataSource) Configure(_ context.Context, req datasource.Configu
 		return
 	}
 
-	db, err := config.connectToMySQLNoDb() // Not connecting to a specific database
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to connect to the Cloud SQL MySQL instance",
@@ -111,5 +144,15 @@ func (d *databaseDataSource) Configure(_ context.Context, req datasource.Configu
 		return
 	}
 
+	err = config.detectServerSettings(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read the Cloud SQL MySQL server settings",
+			err.Error(),
+		)
+		return
+	}
+
 	d.db = db
+	d.config = config
 }
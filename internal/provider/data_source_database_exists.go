@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &databaseExistsDataSource{}
+	_ datasource.DataSourceWithConfigure = &databaseExistsDataSource{}
+)
+
+func newDatabaseExistsDataSource() datasource.DataSource {
+	return &databaseExistsDataSource{}
+}
+
+type databaseExistsDataSourceModel struct {
+	Name   types.String `tfsdk:"name"`
+	Exists types.Bool   `tfsdk:"exists"`
+}
+
+type databaseExistsDataSource struct {
+	db     dbPool
+	config *Config
+}
+
+func (d *databaseExistsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_database_exists"
+}
+
+func (d *databaseExistsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Checks whether a database exists in INFORMATION_SCHEMA.SCHEMATA, e.g. to only create grants once the database " +
+			"of an application exists. Unlike cloudsqlmysql_database a missing database is not an error. Only the databases the " +
+			"provider user has a privilege on are visible",
+		MarkdownDescription: "Checks whether a database exists in `INFORMATION_SCHEMA.SCHEMATA`, e.g. to only create grants once the database " +
+			"of an application exists. Unlike `cloudsqlmysql_database` a missing database is not an error. Only the databases the " +
+			"provider user has a privilege on are visible",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Description:         "The name of the database",
+				MarkdownDescription: "The name of the database",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, maxDatabaseNameLength),
+				},
+			},
+			"exists": schema.BoolAttribute{
+				Description:         "Whether the database exists",
+				MarkdownDescription: "Whether the database exists",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *databaseExistsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = d.config.withReadTimeout(ctx)
+
+	var state databaseExistsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	database := state.Name.ValueString()
+	var name string
+	err := d.config.queryRowPrepared(ctx, d.db, "SELECT SCHEMA_NAME FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME = ?",
+		[]any{d.config.databaseNameForLookup(database)}, &name)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		state.Exists = types.BoolValue(false)
+	case err != nil:
+		resp.Diagnostics.AddError(
+			"Error reading the database",
+			"Could not check whether database '"+database+"' exists, unexpected error: "+err.Error())
+		return
+	default:
+		state.Exists = types.BoolValue(true)
+	}
+
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *databaseExistsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to the Cloud SQL MySQL instance",
+			err.Error(),
+		)
+		return
+	}
+
+	err = config.detectServerSettings(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read the Cloud SQL MySQL server settings",
+			err.Error(),
+		)
+		return
+	}
+
+	d.db = db
+	d.config = config
+}
@@ -0,0 +1,336 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"terraform-provider-cloudsqlmysql/internal/grantparser"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &effectivePrivilegesDataSource{}
+	_ datasource.DataSourceWithConfigure = &effectivePrivilegesDataSource{}
+)
+
+func newEffectivePrivilegesDataSource() datasource.DataSource {
+	return &effectivePrivilegesDataSource{}
+}
+
+type effectivePrivilegesDataSourceModel struct {
+	User       types.String              `tfsdk:"user"`
+	Host       types.String              `tfsdk:"host"`
+	UsingRoles []AccountValue            `tfsdk:"using_roles"`
+	Roles      []AccountValue            `tfsdk:"roles"`
+	Privileges []effectivePrivilegeModel `tfsdk:"privileges"`
+}
+
+type effectivePrivilegeModel struct {
+	Privilege       types.String   `tfsdk:"privilege"`
+	Level           types.String   `tfsdk:"level"`
+	Database        types.String   `tfsdk:"database"`
+	Object          types.String   `tfsdk:"object"`
+	Column          types.String   `tfsdk:"column"`
+	WithGrantOption types.Bool     `tfsdk:"with_grant_option"`
+	GrantedThrough  []AccountValue `tfsdk:"granted_through"`
+}
+
+type effectivePrivilegesDataSource struct {
+	db     dbPool
+	config *Config
+}
+
+func (d *effectivePrivilegesDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_effective_privileges"
+}
+
+func (d *effectivePrivilegesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Computes the effective privileges of a user, including the privileges inherited through granted roles. " +
+			"Partial revokes are not taken into account",
+		MarkdownDescription: "Computes the effective privileges of a user, including the privileges inherited through granted roles. " +
+			"Partial revokes are not taken into account",
+		Attributes: map[string]schema.Attribute{
+			"user": schema.StringAttribute{
+				Description:         "The name of the user or role",
+				MarkdownDescription: "The name of the user or role",
+				Required:            true,
+			},
+			"host": schema.StringAttribute{
+				Description:         "The host of the user or role. Default: %",
+				MarkdownDescription: "The host of the user or role. Default: `%`",
+				Optional:            true,
+				Validators: []validator.String{
+					hostValidator{},
+				},
+			},
+			"using_roles": schema.ListAttribute{
+				Description: "Compute the privileges of the user as if only these roles are active, the roles need to be granted to the user. " +
+					"The privileges are read with SHOW GRANTS ... USING, which merges the privileges of the roles into the grants of the user, " +
+					"so granted_through only lists the user",
+				MarkdownDescription: "Compute the privileges of the user as if only these roles are active, the roles need to be granted to the user. " +
+					"The privileges are read with `SHOW GRANTS ... USING`, which merges the privileges of the roles into the grants of the user, " +
+					"so `granted_through` only lists the user",
+				ElementType: AccountType{},
+				Optional:    true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.UniqueValues(),
+				},
+			},
+			"roles": schema.ListAttribute{
+				Description:         "All roles granted to the user, directly or through other roles, as 'role'@'host'",
+				MarkdownDescription: "All roles granted to the user, directly or through other roles, as `'role'@'host'`",
+				ElementType:         AccountType{},
+				Computed:            true,
+			},
+			"privileges": schema.ListNestedAttribute{
+				Description:         "The effective privileges of the user",
+				MarkdownDescription: "The effective privileges of the user",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"privilege": schema.StringAttribute{
+							Description:         "The name of the privilege",
+							MarkdownDescription: "The name of the privilege",
+							Computed:            true,
+						},
+						"level": schema.StringAttribute{
+							Description:         "The level of the privilege: global, database, table, routine or proxy",
+							MarkdownDescription: "The level of the privilege: `global`, `database`, `table`, `routine` or `proxy`",
+							Computed:            true,
+						},
+						"database": schema.StringAttribute{
+							Description:         "The database, empty for global privileges",
+							MarkdownDescription: "The database, empty for global privileges",
+							Computed:            true,
+						},
+						"object": schema.StringAttribute{
+							Description:         "The table or routine, the proxied account for the proxy privilege",
+							MarkdownDescription: "The table or routine, the proxied account for the proxy privilege",
+							Computed:            true,
+						},
+						"column": schema.StringAttribute{
+							Description:         "The column for column level privileges",
+							MarkdownDescription: "The column for column level privileges",
+							Computed:            true,
+						},
+						"with_grant_option": schema.BoolAttribute{
+							Description:         "True when the privilege can be granted to others",
+							MarkdownDescription: "True when the privilege can be granted to others",
+							Computed:            true,
+						},
+						"granted_through": schema.ListAttribute{
+							Description:         "The accounts that hold the privilege, the user itself or the roles",
+							MarkdownDescription: "The accounts that hold the privilege, the user itself or the roles",
+							ElementType:         AccountType{},
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *effectivePrivilegesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = d.config.withReadTimeout(ctx)
+
+	var state effectivePrivilegesDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	host := "%"
+	if !state.Host.IsNull() {
+		host = state.Host.ValueString()
+	}
+	user := grantparser.Account{User: state.User.ValueString(), Host: host}
+
+	grantsPerAccount, roles, err := d.config.collectGrantsWithRoles(ctx, d.db, user)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading the effective privileges",
+			"Could not read the grants of "+user.String()+", unexpected error: "+err.Error())
+		return
+	}
+
+	state.Roles = []AccountValue{}
+	for _, role := range roles {
+		state.Roles = append(state.Roles, d.config.newAccountValue(role.User, role.Host))
+	}
+	if state.UsingRoles == nil {
+		state.Privileges = d.config.rollUpPrivileges(append([]grantparser.Account{user}, roles...), grantsPerAccount)
+	} else {
+		usingRoles := make([]string, len(state.UsingRoles))
+		for i, role := range state.UsingRoles {
+			usingRoles[i], err = d.config.quoteAccountValue(role)
+			if err != nil {
+				resp.Diagnostics.AddError("Invalid role", "Could not parse the role "+role.ValueString()+": "+err.Error())
+				return
+			}
+		}
+		user.User, user.Host = d.config.canonicalAccount(user.User, user.Host)
+		grants, err := d.config.showGrantsUsing(ctx, d.db, user.User, user.Host, usingRoles)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading the effective privileges",
+				"Could not read the grants of "+user.String()+" using the roles, unexpected error: "+err.Error())
+			return
+		}
+		state.Privileges = d.config.rollUpPrivileges([]grantparser.Account{user}, map[grantparser.Account][]*grantparser.Grant{user: grants})
+	}
+
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *effectivePrivilegesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to the Cloud SQL MySQL instance",
+			err.Error(),
+		)
+		return
+	}
+
+	d.db = db
+	d.config = config
+}
+
+// collectGrantsWithRoles returns the grants of the account and of all roles granted to it, following the role
+// grants in the SHOW GRANTS output. The roles are returned in the order they were found.
+func (c *Config) collectGrantsWithRoles(ctx context.Context, db dbExecutor, account grantparser.Account) (map[grantparser.Account][]*grantparser.Grant, []grantparser.Account, error) {
+	grantsPerAccount := make(map[grantparser.Account][]*grantparser.Grant)
+	var roles []grantparser.Account
+
+	account.User, account.Host = c.canonicalAccount(account.User, account.Host)
+	queue := []grantparser.Account{account}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		current.User, current.Host = c.canonicalAccount(current.User, current.Host)
+		if _, visited := grantsPerAccount[current]; visited {
+			continue // Protects against cycles in the role graph
+		}
+
+		grants, err := c.showGrants(ctx, db, current.User, current.Host)
+		if err != nil {
+			return nil, nil, err
+		}
+		grantsPerAccount[current] = grants
+		if current != account {
+			roles = append(roles, current)
+		}
+
+		for _, grant := range grants {
+			if grant.Level == grantparser.LevelRole && !grant.Revoke {
+				queue = append(queue, grant.Roles...)
+			}
+		}
+	}
+	return grantsPerAccount, roles, nil
+}
+
+type effectivePrivilegeKey struct {
+	privilege string
+	level     grantparser.Level
+	database  string
+	object    string
+	column    string
+}
+
+// rollUpPrivileges merges the privileges of all accounts, a privilege held by multiple accounts is listed once
+// with all accounts that hold it. USAGE is left out as it means no privileges.
+func (c *Config) rollUpPrivileges(accounts []grantparser.Account, grantsPerAccount map[grantparser.Account][]*grantparser.Grant) []effectivePrivilegeModel {
+	var keys []effectivePrivilegeKey
+	grantOption := make(map[effectivePrivilegeKey]bool)
+	grantedThrough := make(map[effectivePrivilegeKey][]AccountValue)
+
+	add := func(key effectivePrivilegeKey, account grantparser.Account, withGrantOption bool) {
+		if _, ok := grantedThrough[key]; !ok {
+			keys = append(keys, key)
+		}
+		grantedThrough[key] = append(grantedThrough[key], c.newAccountValue(account.User, account.Host))
+		grantOption[key] = grantOption[key] || withGrantOption
+	}
+
+	for _, account := range accounts {
+		for _, grant := range grantsPerAccount[account] {
+			if grant.Revoke || grant.Level == grantparser.LevelRole {
+				continue
+			}
+			object := grant.Object
+			if grant.Level == grantparser.LevelProxy {
+				object = grant.Proxied.String()
+			}
+			for _, privilege := range grant.Privileges {
+				if privilege.Name == "USAGE" {
+					continue
+				}
+				key := effectivePrivilegeKey{privilege: privilege.Name, level: grant.Level, database: grant.Database, object: object}
+				if len(privilege.Columns) == 0 {
+					add(key, account, grant.WithGrantOption)
+					continue
+				}
+				for _, column := range privilege.Columns {
+					key.column = column
+					add(key, account, grant.WithGrantOption)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		if keys[i].level != keys[j].level {
+			return keys[i].level < keys[j].level
+		}
+		if keys[i].database != keys[j].database {
+			return keys[i].database < keys[j].database
+		}
+		if keys[i].object != keys[j].object {
+			return keys[i].object < keys[j].object
+		}
+		if keys[i].column != keys[j].column {
+			return keys[i].column < keys[j].column
+		}
+		return keys[i].privilege < keys[j].privilege
+	})
+
+	privileges := []effectivePrivilegeModel{}
+	for _, key := range keys {
+		privileges = append(privileges, effectivePrivilegeModel{
+			Privilege:       types.StringValue(key.privilege),
+			Level:           types.StringValue(key.level.String()),
+			Database:        types.StringValue(key.database),
+			Object:          types.StringValue(key.object),
+			Column:          types.StringValue(key.column),
+			WithGrantOption: types.BoolValue(grantOption[key]),
+			GrantedThrough:  grantedThrough[key],
+		})
+	}
+	return privileges
+}
@@ -0,0 +1,215 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &flagsCheckDataSource{}
+	_ datasource.DataSourceWithConfigure = &flagsCheckDataSource{}
+)
+
+const (
+	flagStatusOk      = "ok"
+	flagStatusMissing = "missing"
+)
+
+// instanceFlag describes how an instance flag that the provider depends on can be detected on the server.
+type instanceFlag struct {
+	query    string // Needs to return a single value, no rows means the flag is missing
+	expected string
+}
+
+var instanceFlags = map[string]instanceFlag{
+	"cloudsql_mysql_audit": {
+		query:    "SELECT PLUGIN_STATUS FROM INFORMATION_SCHEMA.PLUGINS WHERE PLUGIN_NAME = 'cloudsql_mysql_audit'",
+		expected: "ACTIVE",
+	},
+	"cloudsql_iam_authentication": {
+		query:    "SELECT VARIABLE_VALUE FROM performance_schema.global_variables WHERE VARIABLE_NAME = 'cloudsql_iam_authentication'",
+		expected: "ON",
+	},
+}
+
+func instanceFlagNames() []string {
+	names := make([]string, 0, len(instanceFlags))
+	for name := range instanceFlags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newFlagsCheckDataSource() datasource.DataSource {
+	return &flagsCheckDataSource{}
+}
+
+type flagsCheckDataSourceModel struct {
+	Flags               []types.String            `tfsdk:"flags"`
+	Checks              map[string]flagCheckModel `tfsdk:"checks"`
+	AllOk               types.Bool                `tfsdk:"all_ok"`
+	AuditRulesAvailable types.Bool                `tfsdk:"audit_rules_available"`
+}
+
+type flagCheckModel struct {
+	Status types.String `tfsdk:"status"`
+	Value  types.String `tfsdk:"value"`
+}
+
+type flagsCheckDataSource struct {
+	db     dbPool
+	config *Config
+}
+
+func (d *flagsCheckDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flags_check"
+}
+
+func (d *flagsCheckDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description:         "Checks if the instance flags required by the provider features are enabled on the Cloud SQL MySQL instance",
+		MarkdownDescription: "Checks if the instance flags required by the provider features are enabled on the Cloud SQL MySQL instance",
+		Attributes: map[string]schema.Attribute{
+			"flags": schema.SetAttribute{
+				Description:         "The flags to check, all known flags are checked when not set. Possible values: " + strings.Join(instanceFlagNames(), ", "),
+				MarkdownDescription: "The flags to check, all known flags are checked when not set. Possible values: `" + strings.Join(instanceFlagNames(), "`, `") + "`",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(stringvalidator.OneOf(instanceFlagNames()...)),
+				},
+			},
+			"checks": schema.MapNestedAttribute{
+				Description:         "The result of the check per flag",
+				MarkdownDescription: "The result of the check per flag",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"status": schema.StringAttribute{
+							Description:         "`ok` when the flag is enabled, `missing` otherwise",
+							MarkdownDescription: "`ok` when the flag is enabled, `missing` otherwise",
+							Computed:            true,
+						},
+						"value": schema.StringAttribute{
+							Description:         "The value found on the server, null when not found",
+							MarkdownDescription: "The value found on the server, null when not found",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"all_ok": schema.BoolAttribute{
+				Description:         "True when all checked flags are enabled",
+				MarkdownDescription: "True when all checked flags are enabled",
+				Computed:            true,
+			},
+			"audit_rules_available": schema.BoolAttribute{
+				Description: "True when the audit stored procedures used by cloudsqlmysql_audit_rule are installed, " +
+					"which needs the cloudsql_mysql_audit flag",
+				MarkdownDescription: "True when the audit stored procedures used by `cloudsqlmysql_audit_rule` are installed, " +
+					"which needs the `cloudsql_mysql_audit` flag",
+				Computed: true,
+			},
+		},
+	}
+}
+
+func (d *flagsCheckDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = d.config.withReadTimeout(ctx)
+
+	var state flagsCheckDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.Flags == nil {
+		for _, name := range instanceFlagNames() {
+			state.Flags = append(state.Flags, types.StringValue(name))
+		}
+	}
+
+	allOk := true
+	state.Checks = make(map[string]flagCheckModel)
+	for _, flag := range state.Flags {
+		name := flag.ValueString()
+		check := flagCheckModel{
+			Status: types.StringValue(flagStatusMissing),
+			Value:  types.StringNull(),
+		}
+
+		var value string
+		err := queryRow(ctx, d.db, instanceFlags[name].query, nil, &value)
+		if err != nil && err != sql.ErrNoRows {
+			resp.Diagnostics.AddError(
+				"Error checking the instance flags",
+				"Could not check the instance flag '"+name+"', unexpected error: "+err.Error())
+			return
+		}
+		if err == nil {
+			check.Value = types.StringValue(value)
+			if strings.EqualFold(value, instanceFlags[name].expected) {
+				check.Status = types.StringValue(flagStatusOk)
+			}
+		}
+
+		if check.Status.ValueString() != flagStatusOk {
+			allOk = false
+		}
+		state.Checks[name] = check
+	}
+	state.AllOk = types.BoolValue(allOk)
+
+	auditRulesAvailable, err := d.config.detectAuditRules(ctx, d.db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error checking the instance flags",
+			"Could not check if the audit stored procedures are installed, unexpected error: "+err.Error())
+		return
+	}
+	state.AuditRulesAvailable = types.BoolValue(auditRulesAvailable)
+
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *flagsCheckDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to the Cloud SQL MySQL instance",
+			err.Error(),
+		)
+		return
+	}
+
+	d.db = db
+	d.config = config
+}
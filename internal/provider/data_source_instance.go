@@ -0,0 +1,263 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"google.golang.org/api/googleapi"
+	sqladmin "google.golang.org/api/sqladmin/v1beta4"
+)
+
+var (
+	_ datasource.DataSource              = &instanceDataSource{}
+	_ datasource.DataSourceWithConfigure = &instanceDataSource{}
+)
+
+func newInstanceDataSource() datasource.DataSource {
+	return &instanceDataSource{}
+}
+
+type instanceDataSourceModel struct {
+	ConnectionName     types.String        `tfsdk:"connection_name"`
+	Available          types.Bool          `tfsdk:"available"`
+	DatabaseVersion    types.String        `tfsdk:"database_version"`
+	State              types.String        `tfsdk:"state"`
+	IPAddresses        []instanceIPAddress `tfsdk:"ip_addresses"`
+	PublicIPEnabled    types.Bool          `tfsdk:"public_ip_enabled"`
+	PSCEnabled         types.Bool          `tfsdk:"psc_enabled"`
+	Flags              types.Map           `tfsdk:"flags"`
+	ConnectivityErrors []types.String      `tfsdk:"connectivity_errors"`
+}
+
+type instanceIPAddress struct {
+	Type      types.String `tfsdk:"type"`
+	IPAddress types.String `tfsdk:"ip_address"`
+}
+
+type instanceDataSource struct {
+	config *Config
+}
+
+func (d *instanceDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_instance"
+}
+
+func (d *instanceDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reads the instance settings from the Cloud SQL Admin API with the application default credentials and checks " +
+			"the private_ip and psc provider settings against them. When the credentials don't allow reading the instance, " +
+			"available is false and the other attributes are empty",
+		MarkdownDescription: "Reads the instance settings from the Cloud SQL Admin API with the application default credentials and checks " +
+			"the `private_ip` and `psc` provider settings against them. When the credentials don't allow reading the instance, " +
+			"`available` is `false` and the other attributes are empty",
+		Attributes: map[string]schema.Attribute{
+			"connection_name": schema.StringAttribute{
+				Description:         "The connection name of the instance, project:region:instance",
+				MarkdownDescription: "The connection name of the instance, `project:region:instance`",
+				Computed:            true,
+			},
+			"available": schema.BoolAttribute{
+				Description:         "True when the instance could be read from the Cloud SQL Admin API",
+				MarkdownDescription: "True when the instance could be read from the Cloud SQL Admin API",
+				Computed:            true,
+			},
+			"database_version": schema.StringAttribute{
+				Description:         "The database version, e.g. MYSQL_8_0_36",
+				MarkdownDescription: "The database version, e.g. `MYSQL_8_0_36`",
+				Computed:            true,
+			},
+			"state": schema.StringAttribute{
+				Description:         "The state of the instance, e.g. RUNNABLE",
+				MarkdownDescription: "The state of the instance, e.g. `RUNNABLE`",
+				Computed:            true,
+			},
+			"ip_addresses": schema.ListNestedAttribute{
+				Description:         "The IP addresses of the instance",
+				MarkdownDescription: "The IP addresses of the instance",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							Description:         "The type of the IP address: PRIMARY, PRIVATE or OUTGOING",
+							MarkdownDescription: "The type of the IP address: `PRIMARY`, `PRIVATE` or `OUTGOING`",
+							Computed:            true,
+						},
+						"ip_address": schema.StringAttribute{
+							Description:         "The IP address",
+							MarkdownDescription: "The IP address",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"public_ip_enabled": schema.BoolAttribute{
+				Description:         "True when the instance has a public IP address",
+				MarkdownDescription: "True when the instance has a public IP address",
+				Computed:            true,
+			},
+			"psc_enabled": schema.BoolAttribute{
+				Description:         "True when Private Service Connect is enabled on the instance",
+				MarkdownDescription: "True when Private Service Connect is enabled on the instance",
+				Computed:            true,
+			},
+			"flags": schema.MapAttribute{
+				Description:         "The database flags set on the instance",
+				MarkdownDescription: "The database flags set on the instance",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"connectivity_errors": schema.ListAttribute{
+				Description:         "The provider connection settings that don't match the instance, empty when the settings match",
+				MarkdownDescription: "The provider connection settings that don't match the instance, empty when the settings match",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *instanceDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = d.config.withReadTimeout(ctx)
+
+	var state instanceDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.ConnectionName = types.StringValue(d.config.connectionName)
+	state.Available = types.BoolValue(false)
+	state.Flags = types.MapNull(types.StringType)
+
+	project, instanceName, err := splitConnectionName(d.config.connectionName)
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading the Cloud SQL instance", err.Error())
+		return
+	}
+
+	instance, err := getInstance(ctx, project, instanceName)
+	if err != nil {
+		if !adminAPIUnavailable(err) {
+			resp.Diagnostics.AddError(
+				"Error reading the Cloud SQL instance",
+				"Could not read the instance '"+d.config.connectionName+"' from the Cloud SQL Admin API, unexpected error: "+err.Error())
+			return
+		}
+		resp.Diagnostics.AddWarning(
+			"Cloud SQL Admin API not available",
+			"The instance '"+d.config.connectionName+"' could not be read from the Cloud SQL Admin API, the connectivity settings are not checked: "+err.Error())
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		return
+	}
+
+	state.Available = types.BoolValue(true)
+	state.DatabaseVersion = types.StringValue(instance.DatabaseVersion)
+	state.State = types.StringValue(instance.State)
+
+	state.IPAddresses = []instanceIPAddress{}
+	hasPrivateIP := false
+	for _, address := range instance.IpAddresses {
+		state.IPAddresses = append(state.IPAddresses, instanceIPAddress{
+			Type:      types.StringValue(address.Type),
+			IPAddress: types.StringValue(address.IpAddress),
+		})
+		hasPrivateIP = hasPrivateIP || address.Type == "PRIVATE"
+	}
+
+	publicIPEnabled, pscEnabled := false, false
+	flags := make(map[string]string)
+	if instance.Settings != nil {
+		if ipConfiguration := instance.Settings.IpConfiguration; ipConfiguration != nil {
+			publicIPEnabled = ipConfiguration.Ipv4Enabled
+			pscEnabled = ipConfiguration.PscConfig != nil && ipConfiguration.PscConfig.PscEnabled
+		}
+		for _, flag := range instance.Settings.DatabaseFlags {
+			flags[flag.Name] = flag.Value
+		}
+	}
+	state.PublicIPEnabled = types.BoolValue(publicIPEnabled)
+	state.PSCEnabled = types.BoolValue(pscEnabled)
+
+	flagsValue, diags := types.MapValueFrom(ctx, types.StringType, flags)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.Flags = flagsValue
+
+	state.ConnectivityErrors = []types.String{}
+	for _, message := range d.connectivityErrors(hasPrivateIP, publicIPEnabled, pscEnabled) {
+		state.ConnectivityErrors = append(state.ConnectivityErrors, types.StringValue(message))
+		resp.Diagnostics.AddWarning("Provider connectivity settings don't match the instance", message)
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// connectivityErrors compares the private_ip and psc provider settings with the connectivity of the instance.
+func (d *instanceDataSource) connectivityErrors(hasPrivateIP, publicIPEnabled, pscEnabled bool) []string {
+	var messages []string
+	switch {
+	case d.config.psc && !pscEnabled:
+		messages = append(messages, "psc is set but Private Service Connect is not enabled on the instance")
+	case d.config.privateIP && !hasPrivateIP:
+		messages = append(messages, "private_ip is set but the instance has no private IP address")
+	case !d.config.psc && !d.config.privateIP && !publicIPEnabled:
+		messages = append(messages, "the provider connects over the public IP address but the instance has no public IP address, set private_ip or psc")
+	}
+	return messages
+}
+
+func (d *instanceDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.config = config
+}
+
+// splitConnectionName returns the project and instance name of a project:region:instance connection name.
+// The project can contain a domain, e.g. example.com:project:region:instance.
+func splitConnectionName(connectionName string) (string, string, error) {
+	parts := strings.Split(connectionName, ":")
+	if len(parts) < 3 {
+		return "", "", fmt.Errorf("the connection name '%s' is invalid, expected project:region:instance", connectionName)
+	}
+	return strings.Join(parts[:len(parts)-2], ":"), parts[len(parts)-1], nil
+}
+
+func getInstance(ctx context.Context, project, instance string) (*sqladmin.DatabaseInstance, error) {
+	service, err := sqladmin.NewService(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return service.Instances.Get(project, instance).Context(ctx).Do()
+}
+
+// adminAPIUnavailable returns true when the error means the credentials can't be used to read the instance.
+func adminAPIUnavailable(err error) bool {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusUnauthorized || apiErr.Code == http.StatusForbidden
+	}
+	// No application default credentials found
+	return strings.Contains(err.Error(), "could not find default credentials")
+}
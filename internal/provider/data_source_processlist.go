@@ -0,0 +1,215 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &processlistDataSource{}
+	_ datasource.DataSourceWithConfigure = &processlistDataSource{}
+)
+
+func newProcesslistDataSource() datasource.DataSource {
+	return &processlistDataSource{}
+}
+
+type processlistDataSourceModel struct {
+	User            types.String   `tfsdk:"user"`
+	Database        types.String   `tfsdk:"database"`
+	Processes       []processModel `tfsdk:"processes"`
+	ConnectionCount types.Int64    `tfsdk:"connection_count"`
+}
+
+type processModel struct {
+	Id       types.Int64  `tfsdk:"id"`
+	User     types.String `tfsdk:"user"`
+	Host     types.String `tfsdk:"host"`
+	Database types.String `tfsdk:"database"`
+	Command  types.String `tfsdk:"command"`
+	Time     types.Int64  `tfsdk:"time"`
+	State    types.String `tfsdk:"state"`
+}
+
+type processlistDataSource struct {
+	db     dbPool
+	config *Config
+}
+
+func (d *processlistDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_processlist"
+}
+
+func (d *processlistDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the connections to the instance from INFORMATION_SCHEMA.PROCESSLIST, the connection of the provider " +
+			"itself is left out. Without the PROCESS privilege only the connections of the provider user are visible",
+		MarkdownDescription: "Lists the connections to the instance from `INFORMATION_SCHEMA.PROCESSLIST`, the connection of the provider " +
+			"itself is left out. Without the `PROCESS` privilege only the connections of the provider user are visible",
+		Attributes: map[string]schema.Attribute{
+			"user": schema.StringAttribute{
+				Description:         "Only list the connections of this user",
+				MarkdownDescription: "Only list the connections of this user",
+				Optional:            true,
+			},
+			"database": schema.StringAttribute{
+				Description:         "Only list the connections that use this database as default database",
+				MarkdownDescription: "Only list the connections that use this database as default database",
+				Optional:            true,
+			},
+			"processes": schema.ListNestedAttribute{
+				Description:         "The connections, ordered by id",
+				MarkdownDescription: "The connections, ordered by id",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.Int64Attribute{
+							Description:         "The connection id",
+							MarkdownDescription: "The connection id",
+							Computed:            true,
+						},
+						"user": schema.StringAttribute{
+							Description:         "The user of the connection",
+							MarkdownDescription: "The user of the connection",
+							Computed:            true,
+						},
+						"host": schema.StringAttribute{
+							Description:         "The client host and port of the connection",
+							MarkdownDescription: "The client host and port of the connection",
+							Computed:            true,
+						},
+						"database": schema.StringAttribute{
+							Description:         "The default database of the connection, empty when none is selected",
+							MarkdownDescription: "The default database of the connection, empty when none is selected",
+							Computed:            true,
+						},
+						"command": schema.StringAttribute{
+							Description:         "The command the connection executes, Sleep for idle connections",
+							MarkdownDescription: "The command the connection executes, `Sleep` for idle connections",
+							Computed:            true,
+						},
+						"time": schema.Int64Attribute{
+							Description:         "The time in seconds the connection is in its current state",
+							MarkdownDescription: "The time in seconds the connection is in its current state",
+							Computed:            true,
+						},
+						"state": schema.StringAttribute{
+							Description:         "The state of the connection",
+							MarkdownDescription: "The state of the connection",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"connection_count": schema.Int64Attribute{
+				Description:         "The number of connections",
+				MarkdownDescription: "The number of connections",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *processlistDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = d.config.withReadTimeout(ctx)
+
+	var state processlistDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	query := "SELECT ID, USER, HOST, IFNULL(DB, ''), COMMAND, TIME, IFNULL(STATE, '') FROM INFORMATION_SCHEMA.PROCESSLIST"
+	conditions := []string{"ID <> CONNECTION_ID()"}
+	var args []any
+	if !state.User.IsNull() {
+		conditions = append(conditions, "USER = ?")
+		args = append(args, state.User.ValueString())
+	}
+	if !state.Database.IsNull() {
+		conditions = append(conditions, "DB = ?")
+		args = append(args, state.Database.ValueString())
+	}
+	query += " WHERE " + strings.Join(conditions, " AND ") + " ORDER BY ID"
+
+	processes, err := readProcesses(ctx, d.db, query, args...)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading the processlist",
+			"Could not read the processlist, unexpected error: "+err.Error())
+		return
+	}
+
+	state.Processes = processes
+	state.ConnectionCount = types.Int64Value(int64(len(processes)))
+
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *processlistDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to the Cloud SQL MySQL instance",
+			err.Error(),
+		)
+		return
+	}
+
+	d.db = db
+	d.config = config
+}
+
+func readProcesses(ctx context.Context, db dbExecutor, query string, args ...any) ([]processModel, error) {
+	ctx, cancel := statementContext(ctx)
+	defer cancel()
+
+	rows, err := queryContext(ctx, db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	processes := []processModel{}
+	for rows.Next() {
+		var (
+			id, seconds                                 int64
+			user, host, database, command, processState string
+		)
+		err = rows.Scan(&id, &user, &host, &database, &command, &seconds, &processState)
+		if err != nil {
+			return nil, err
+		}
+		processes = append(processes, processModel{
+			Id:       types.Int64Value(id),
+			User:     types.StringValue(user),
+			Host:     types.StringValue(host),
+			Database: types.StringValue(database),
+			Command:  types.StringValue(command),
+			Time:     types.Int64Value(seconds),
+			State:    types.StringValue(processState),
+		})
+	}
+	return processes, rows.Err()
+}
@@ -0,0 +1,261 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &roleEdgesDataSource{}
+	_ datasource.DataSourceWithConfigure = &roleEdgesDataSource{}
+)
+
+func newRoleEdgesDataSource() datasource.DataSource {
+	return &roleEdgesDataSource{}
+}
+
+type roleEdgesDataSourceModel struct {
+	Transitive      types.Bool      `tfsdk:"transitive"`
+	Edges           []roleEdgeModel `tfsdk:"edges"`
+	TransitiveEdges []roleEdgeModel `tfsdk:"transitive_edges"`
+}
+
+type roleEdgeModel struct {
+	FromUser        types.String `tfsdk:"from_user"`
+	FromHost        types.String `tfsdk:"from_host"`
+	ToUser          types.String `tfsdk:"to_user"`
+	ToHost          types.String `tfsdk:"to_host"`
+	WithAdminOption types.Bool   `tfsdk:"with_admin_option"`
+}
+
+type roleEdgesDataSource struct {
+	db     dbPool
+	config *Config
+}
+
+func (d *roleEdgesDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_role_edges"
+}
+
+func (d *roleEdgesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	edgeAttributes := map[string]schema.Attribute{
+		"from_user": schema.StringAttribute{
+			Description:         "The name of the role that is granted",
+			MarkdownDescription: "The name of the role that is granted",
+			Computed:            true,
+		},
+		"from_host": schema.StringAttribute{
+			Description:         "The host of the role that is granted",
+			MarkdownDescription: "The host of the role that is granted",
+			Computed:            true,
+		},
+		"to_user": schema.StringAttribute{
+			Description:         "The name of the user or role the role is granted to",
+			MarkdownDescription: "The name of the user or role the role is granted to",
+			Computed:            true,
+		},
+		"to_host": schema.StringAttribute{
+			Description:         "The host of the user or role the role is granted to",
+			MarkdownDescription: "The host of the user or role the role is granted to",
+			Computed:            true,
+		},
+		"with_admin_option": schema.BoolAttribute{
+			Description:         "True when the role is granted with the admin option, only set for direct edges",
+			MarkdownDescription: "True when the role is granted with the admin option, only set for direct edges",
+			Computed:            true,
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		Description:         "Lists the roles granted to users and other roles from `mysql.role_edges`",
+		MarkdownDescription: "Lists the roles granted to users and other roles from `mysql.role_edges`",
+		Attributes: map[string]schema.Attribute{
+			"transitive": schema.BoolAttribute{
+				Description:         "Also compute the transitive closure of the edges, roles granted through other roles",
+				MarkdownDescription: "Also compute the transitive closure of the edges, roles granted through other roles",
+				Optional:            true,
+			},
+			"edges": schema.ListNestedAttribute{
+				Description:         "The roles directly granted to users and roles",
+				MarkdownDescription: "The roles directly granted to users and roles",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: edgeAttributes,
+				},
+			},
+			"transitive_edges": schema.ListNestedAttribute{
+				Description:         "All roles granted to users and roles, directly or through other roles. Only filled in when `transitive` is true",
+				MarkdownDescription: "All roles granted to users and roles, directly or through other roles. Only filled in when `transitive` is true",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: edgeAttributes,
+				},
+			},
+		},
+	}
+}
+
+func (d *roleEdgesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = d.config.withReadTimeout(ctx)
+
+	var state roleEdgesDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	edges, err := readRoleEdges(ctx, d.db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading the role edges",
+			"Could not read the role edges, unexpected error: "+err.Error())
+		return
+	}
+
+	state.Edges = []roleEdgeModel{}
+	for _, edge := range edges {
+		state.Edges = append(state.Edges, edge.model())
+	}
+
+	state.TransitiveEdges = nil
+	if state.Transitive.ValueBool() {
+		state.TransitiveEdges = []roleEdgeModel{}
+		for _, edge := range roleEdgesClosure(edges) {
+			state.TransitiveEdges = append(state.TransitiveEdges, edge.model())
+		}
+	}
+
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *roleEdgesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to the Cloud SQL MySQL instance",
+			err.Error(),
+		)
+		return
+	}
+
+	d.db = db
+	d.config = config
+}
+
+type account struct {
+	User string
+	Host string
+}
+
+type roleEdge struct {
+	From            account
+	To              account
+	WithAdminOption bool
+	Transitive      bool
+}
+
+func (edge *roleEdge) model() roleEdgeModel {
+	model := roleEdgeModel{
+		FromUser:        types.StringValue(edge.From.User),
+		FromHost:        types.StringValue(edge.From.Host),
+		ToUser:          types.StringValue(edge.To.User),
+		ToHost:          types.StringValue(edge.To.Host),
+		WithAdminOption: types.BoolValue(edge.WithAdminOption),
+	}
+	if edge.Transitive {
+		model.WithAdminOption = types.BoolNull()
+	}
+	return model
+}
+
+func readRoleEdges(ctx context.Context, db dbExecutor) ([]roleEdge, error) {
+	ctx, cancel := statementContext(ctx)
+	defer cancel()
+
+	rows, err := queryContext(ctx, db, "SELECT FROM_USER, FROM_HOST, TO_USER, TO_HOST, WITH_ADMIN_OPTION FROM mysql.role_edges "+
+		"ORDER BY TO_USER, TO_HOST, FROM_USER, FROM_HOST")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var edges []roleEdge
+	for rows.Next() {
+		var edge roleEdge
+		var withAdminOption string
+		err = rows.Scan(&edge.From.User, &edge.From.Host, &edge.To.User, &edge.To.Host, &withAdminOption)
+		if err != nil {
+			return nil, err
+		}
+		edge.WithAdminOption = withAdminOption == "Y"
+		edges = append(edges, edge)
+	}
+	return edges, rows.Err()
+}
+
+// roleEdgesClosure returns all roles granted to every grantee, directly or through other roles.
+// Direct edges keep their admin option, edges only reachable through other roles are marked as transitive.
+func roleEdgesClosure(edges []roleEdge) []roleEdge {
+	grantedRoles := make(map[account][]roleEdge)
+	for _, edge := range edges {
+		grantedRoles[edge.To] = append(grantedRoles[edge.To], edge)
+	}
+
+	var grantees []account
+	for grantee := range grantedRoles {
+		grantees = append(grantees, grantee)
+	}
+	sort.Slice(grantees, func(i, j int) bool {
+		if grantees[i].User != grantees[j].User {
+			return grantees[i].User < grantees[j].User
+		}
+		return grantees[i].Host < grantees[j].Host
+	})
+
+	var closure []roleEdge
+	for _, grantee := range grantees {
+		visited := map[account]bool{grantee: true} // Protects against cycles in the role graph
+		queue := grantedRoles[grantee]
+		transitive := false
+		for len(queue) > 0 {
+			var next []roleEdge
+			for _, edge := range queue {
+				if visited[edge.From] {
+					continue
+				}
+				visited[edge.From] = true
+				closure = append(closure, roleEdge{
+					From:            edge.From,
+					To:              grantee,
+					WithAdminOption: edge.WithAdminOption && !transitive,
+					Transitive:      transitive,
+				})
+				next = append(next, grantedRoles[edge.From]...)
+			}
+			queue = next
+			transitive = true
+		}
+	}
+	return closure
+}
@@ -0,0 +1,208 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &tableDataSource{}
+	_ datasource.DataSourceWithConfigure = &tableDataSource{}
+)
+
+func newTableDataSource() datasource.DataSource {
+	return &tableDataSource{}
+}
+
+type tableDataSourceModel struct {
+	Database    types.String  `tfsdk:"database"`
+	Table       types.String  `tfsdk:"table"`
+	Columns     []columnModel `tfsdk:"columns"`
+	ColumnNames []string      `tfsdk:"column_names"`
+}
+
+type columnModel struct {
+	Name     types.String `tfsdk:"name"`
+	Type     types.String `tfsdk:"type"`
+	Nullable types.Bool   `tfsdk:"nullable"`
+	Key      types.String `tfsdk:"key"`
+}
+
+type tableDataSource struct {
+	db     dbPool
+	config *Config
+}
+
+func (d *tableDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_table"
+}
+
+func (d *tableDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reads the columns of a table or view from INFORMATION_SCHEMA.COLUMNS, e.g. to grant column privileges on " +
+			"the columns that exist. Only the columns the provider user has a privilege on are visible",
+		MarkdownDescription: "Reads the columns of a table or view from `INFORMATION_SCHEMA.COLUMNS`, e.g. to grant column privileges on " +
+			"the columns that exist. Only the columns the provider user has a privilege on are visible",
+		Attributes: map[string]schema.Attribute{
+			"database": schema.StringAttribute{
+				Description:         "The name of the database",
+				MarkdownDescription: "The name of the database",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, maxDatabaseNameLength),
+				},
+			},
+			"table": schema.StringAttribute{
+				Description:         "The name of the table or view",
+				MarkdownDescription: "The name of the table or view",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, maxDatabaseNameLength),
+				},
+			},
+			"columns": schema.ListNestedAttribute{
+				Description:         "The columns, in the order of the table definition",
+				MarkdownDescription: "The columns, in the order of the table definition",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description:         "The name of the column",
+							MarkdownDescription: "The name of the column",
+							Computed:            true,
+						},
+						"type": schema.StringAttribute{
+							Description:         "The type of the column, e.g. varchar(255) or int unsigned",
+							MarkdownDescription: "The type of the column, e.g. `varchar(255)` or `int unsigned`",
+							Computed:            true,
+						},
+						"nullable": schema.BoolAttribute{
+							Description:         "Whether the column accepts NULL values",
+							MarkdownDescription: "Whether the column accepts `NULL` values",
+							Computed:            true,
+						},
+						"key": schema.StringAttribute{
+							Description:         "The index of the column: PRI, UNI, MUL or empty when the column is not the first column of an index",
+							MarkdownDescription: "The index of the column: `PRI`, `UNI`, `MUL` or empty when the column is not the first column of an index",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"column_names": schema.ListAttribute{
+				Description:         "The names of the columns, in the order of the table definition",
+				MarkdownDescription: "The names of the columns, in the order of the table definition",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *tableDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = d.config.withReadTimeout(ctx)
+
+	var state tableDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	database := state.Database.ValueString()
+	table := state.Table.ValueString()
+	// Table names are stored in lowercase with the same lower_case_table_names settings as database names
+	columns, err := readColumns(ctx, d.db, d.config.databaseNameForLookup(database), d.config.databaseNameForLookup(table))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading the table columns",
+			"Could not read the columns of '"+database+"'.'"+table+"', unexpected error: "+err.Error())
+		return
+	}
+	if len(columns) == 0 {
+		resp.Diagnostics.AddError(
+			"Table not found",
+			"Table '"+database+"'.'"+table+"' not found, or the provider user has no privileges on it")
+		return
+	}
+
+	state.Columns = columns
+	state.ColumnNames = make([]string, len(columns))
+	for i, column := range columns {
+		state.ColumnNames[i] = column.Name.ValueString()
+	}
+
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *tableDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to the Cloud SQL MySQL instance",
+			err.Error(),
+		)
+		return
+	}
+
+	err = config.detectServerSettings(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read the Cloud SQL MySQL server settings",
+			err.Error(),
+		)
+		return
+	}
+
+	d.db = db
+	d.config = config
+}
+
+func readColumns(ctx context.Context, db dbExecutor, database, table string) ([]columnModel, error) {
+	ctx, cancel := statementContext(ctx)
+	defer cancel()
+
+	rows, err := queryContext(ctx, db, "SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_KEY FROM INFORMATION_SCHEMA.COLUMNS "+
+		"WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION", database, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []columnModel
+	for rows.Next() {
+		var name, columnType, nullable, key string
+		err = rows.Scan(&name, &columnType, &nullable, &key)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, columnModel{
+			Name:     types.StringValue(name),
+			Type:     types.StringValue(columnType),
+			Nullable: types.BoolValue(nullable == "YES"),
+			Key:      types.StringValue(key),
+		})
+	}
+	return columns, rows.Err()
+}
@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &tableExistsDataSource{}
+	_ datasource.DataSourceWithConfigure = &tableExistsDataSource{}
+)
+
+func newTableExistsDataSource() datasource.DataSource {
+	return &tableExistsDataSource{}
+}
+
+type tableExistsDataSourceModel struct {
+	Database   types.String `tfsdk:"database"`
+	Name       types.String `tfsdk:"name"`
+	Exists     types.Bool   `tfsdk:"exists"`
+	Engine     types.String `tfsdk:"engine"`
+	CreateTime types.String `tfsdk:"create_time"`
+}
+
+type tableExistsDataSource struct {
+	db     dbPool
+	config *Config
+}
+
+func (d *tableExistsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_table_exists"
+}
+
+func (d *tableExistsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Checks whether a table or view exists in INFORMATION_SCHEMA.TABLES, e.g. to only grant privileges or run " +
+			"migrations once the tables of an application exist. Unlike cloudsqlmysql_table a missing table is not an error. Only " +
+			"the tables the provider user has a privilege on are visible",
+		MarkdownDescription: "Checks whether a table or view exists in `INFORMATION_SCHEMA.TABLES`, e.g. to only grant privileges or run " +
+			"migrations once the tables of an application exist. Unlike `cloudsqlmysql_table` a missing table is not an error. Only " +
+			"the tables the provider user has a privilege on are visible",
+		Attributes: map[string]schema.Attribute{
+			"database": schema.StringAttribute{
+				Description:         "The name of the database",
+				MarkdownDescription: "The name of the database",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, maxDatabaseNameLength),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description:         "The name of the table or view",
+				MarkdownDescription: "The name of the table or view",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, maxDatabaseNameLength),
+				},
+			},
+			"exists": schema.BoolAttribute{
+				Description:         "Whether the table or view exists",
+				MarkdownDescription: "Whether the table or view exists",
+				Computed:            true,
+			},
+			"engine": schema.StringAttribute{
+				Description:         "The storage engine of the table, e.g. InnoDB. Null for views and when the table doesn't exist",
+				MarkdownDescription: "The storage engine of the table, e.g. `InnoDB`. Null for views and when the table doesn't exist",
+				Computed:            true,
+			},
+			"create_time": schema.StringAttribute{
+				Description:         "The time the table was created in RFC 3339 format. Null when the server doesn't report it and when the table doesn't exist",
+				MarkdownDescription: "The time the table was created in RFC 3339 format. Null when the server doesn't report it and when the table doesn't exist",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *tableExistsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = d.config.withReadTimeout(ctx)
+
+	var state tableExistsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	database := state.Database.ValueString()
+	table := state.Name.ValueString()
+	// UNIX_TIMESTAMP doesn't depend on the time_zone of the session
+	var engine sql.NullString
+	var createTime sql.NullInt64
+	err := d.config.queryRowPrepared(ctx, d.db, "SELECT ENGINE, UNIX_TIMESTAMP(CREATE_TIME) FROM INFORMATION_SCHEMA.TABLES "+
+		"WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?",
+		[]any{d.config.databaseNameForLookup(database), d.config.databaseNameForLookup(table)}, &engine, &createTime)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		state.Exists = types.BoolValue(false)
+		state.Engine = types.StringNull()
+		state.CreateTime = types.StringNull()
+	case err != nil:
+		resp.Diagnostics.AddError(
+			"Error reading the table",
+			"Could not check whether '"+database+"'.'"+table+"' exists, unexpected error: "+err.Error())
+		return
+	default:
+		state.Exists = types.BoolValue(true)
+		state.Engine = types.StringNull()
+		if engine.Valid {
+			state.Engine = types.StringValue(engine.String)
+		}
+		state.CreateTime = types.StringNull()
+		if createTime.Valid {
+			state.CreateTime = types.StringValue(time.Unix(createTime.Int64, 0).UTC().Format(time.RFC3339))
+		}
+	}
+
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *tableExistsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to the Cloud SQL MySQL instance",
+			err.Error(),
+		)
+		return
+	}
+
+	err = config.detectServerSettings(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read the Cloud SQL MySQL server settings",
+			err.Error(),
+		)
+		return
+	}
+
+	d.db = db
+	d.config = config
+}
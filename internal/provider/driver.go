@@ -0,0 +1,293 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/cloudsqlconn"
+	mysqlconn "cloud.google.com/go/cloudsqlconn/mysql/mysql"
+	"github.com/go-sql-driver/mysql"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// registerDriver registers a MySQL driver that connects through the Cloud SQL connector, like
+// mysqlconn.RegisterDriver does. The TLS state of every new connection is logged, and when requireTLS is set
+// connections without a completed TLS handshake are refused. The connector verifies the server certificate
+// during the handshake, a completed handshake means the server identity is verified. A driver that is registered
+// again only gets the dial function of the new dialer, database/sql has no way to replace a driver.
+func registerDriver(name string, requireTLS bool, opts ...cloudsqlconn.Option) (*cloudsqlconn.Dialer, error) {
+	dialer, err := cloudsqlconn.NewDialer(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	mysql.RegisterDialContext(name, func(ctx context.Context, addr string) (net.Conn, error) {
+		conn, err := dialer.Dial(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		state, ok := tlsConnectionState(conn)
+		if !ok || !state.HandshakeComplete || len(state.PeerCertificates) == 0 {
+			if requireTLS {
+				_ = conn.Close()
+				return nil, errors.New("the connection to " + addr + " is not a verified TLS connection and require_tls is set")
+			}
+			tflog.Debug(ctx, "Connected to "+addr+" without a verified TLS connection")
+			return mysqlconn.LivenessCheckConn{Conn: conn}, nil
+		}
+
+		tflog.Debug(ctx, fmt.Sprintf("Connected to %s with %s, cipher suite %s, server certificate %s", addr,
+			tls.VersionName(state.Version), tls.CipherSuiteName(state.CipherSuite), state.PeerCertificates[0].Subject))
+		return mysqlconn.LivenessCheckConn{Conn: conn}, nil
+	})
+	if !slices.Contains(sql.Drivers(), name) {
+		sql.Register(name, &mysql.MySQLDriver{})
+	}
+	return dialer, nil
+}
+
+// sharedConnector is a Cloud SQL connector with its driver, shared by the providers of the process that use the
+// same connector settings. Every connector refreshes the certificates of its instances in the background, sharing
+// it keeps many provider aliases from running a refresh per alias.
+type sharedConnector struct {
+	fingerprint string
+	driverName  string
+	dialer      *cloudsqlconn.Dialer
+	refs        int // The configs using the connector, it is closed when the last one is closed
+}
+
+var (
+	connectors       = make(map[string]*sharedConnector) // The open connectors by fingerprint
+	connectorDrivers = make(map[string]string)           // The driver names by fingerprint, also of the closed connectors
+	connectorsMutex  sync.Mutex
+)
+
+// connectorFingerprint identifies the settings a connector is created with, the providers with the same fingerprint
+// share the connector.
+func connectorFingerprint(requireTLS, privateIP, psc bool, proxy string, proxyFallbackDirect bool, refreshRetries int64, lazyRefresh bool) string {
+	return fmt.Sprintf("require_tls=%t private_ip=%t psc=%t proxy=%s proxy_fallback_direct=%t refresh_retries=%d lazy_refresh=%t",
+		requireTLS, privateIP, psc, proxy, proxyFallbackDirect, refreshRetries, lazyRefresh)
+}
+
+// acquireConnector returns the connector of the fingerprint, the connector and its driver are created on first use.
+// The connector is released with release when the config using it is closed.
+func acquireConnector(fingerprint string, requireTLS bool, opts ...cloudsqlconn.Option) (*sharedConnector, error) {
+	connectorsMutex.Lock()
+	defer connectorsMutex.Unlock()
+
+	if connector, ok := connectors[fingerprint]; ok {
+		connector.refs++
+		return connector, nil
+	}
+
+	name, ok := connectorDrivers[fingerprint]
+	if !ok {
+		name = "cloudsql-mysql"
+		if len(connectorDrivers) > 0 {
+			name = fmt.Sprintf("cloudsql-mysql-%d", len(connectorDrivers))
+		}
+	}
+	dialer, err := registerDriver(name, requireTLS, opts...)
+	if err != nil {
+		return nil, err
+	}
+	connectorDrivers[fingerprint] = name
+
+	connector := &sharedConnector{fingerprint: fingerprint, driverName: name, dialer: dialer, refs: 1}
+	connectors[fingerprint] = connector
+	return connector, nil
+}
+
+// release closes the dialer when no other config uses the connector, stopping its background refreshes.
+func (c *sharedConnector) release() error {
+	connectorsMutex.Lock()
+	defer connectorsMutex.Unlock()
+
+	c.refs--
+	if c.refs > 0 {
+		return nil
+	}
+	delete(connectors, c.fingerprint)
+	return c.dialer.Close()
+}
+
+// checkConnectorRefresh waits for the first certificate refresh of the connector for the instance, so refresh
+// problems are reported as a warning when the provider is configured instead of as dial errors later on. The refresh
+// result is cached by the dialer, the connections opened afterwards reuse it. The wait is limited by connect_timeout.
+func checkConnectorRefresh(ctx context.Context, dialer *cloudsqlconn.Dialer, connectionName string, timeout time.Duration) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	refreshCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// EngineVersion blocks until the refresh of the instance metadata and the ephemeral certificate completed
+	_, err := dialer.EngineVersion(refreshCtx, connectionName)
+	if err != nil && adminAPIQuotaError(err) {
+		diags.AddWarning(
+			"Cloud SQL Admin API quota exceeded",
+			"The Cloud SQL connector could not refresh the connection information of '"+connectionName+"' because the "+
+				"Cloud SQL Admin API quota of the project is exhausted, connecting to the instance will fail until the quota "+
+				"recovers. This is not an authentication problem.\n\n"+connectorRefreshRemediation(err, timeout)+"\n\nError: "+err.Error(),
+		)
+	} else if err != nil {
+		diags.AddWarning(
+			"Cloud SQL connector refresh failed",
+			"The Cloud SQL connector could not refresh the connection information of '"+connectionName+"', "+
+				"connecting to the instance will fail until this is resolved.\n\n"+
+				connectorRefreshRemediation(err, timeout)+"\n\nError: "+err.Error(),
+		)
+	}
+	return diags
+}
+
+// connectorRefreshRemediation returns how to resolve the common causes of a failed refresh.
+func connectorRefreshRemediation(err error, timeout time.Duration) string {
+	message := strings.ToLower(err.Error())
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "The refresh did not complete within " + timeout.String() + ", check that the Cloud SQL Admin API " +
+			"(sqladmin.googleapis.com) can be reached from this machine, e.g. through the configured proxy."
+	case adminAPIQuotaError(err):
+		return "Many provider aliases refreshing their certificates at the same time exhaust the per-minute quota. " +
+			"Set refresh_jitter to spread the refreshes and refresh_retries to retry the rejected requests, " +
+			"or request a higher quota for sqladmin.googleapis.com."
+	case strings.Contains(message, "service_disabled") || strings.Contains(message, "accessnotconfigured") ||
+		strings.Contains(message, "has not been used in project"):
+		return "Enable the Cloud SQL Admin API (sqladmin.googleapis.com) in the project of the credentials: " +
+			"gcloud services enable sqladmin.googleapis.com"
+	case strings.Contains(message, "could not find default credentials") || strings.Contains(message, "invalid_grant"):
+		return "Configure the application default credentials, e.g. with gcloud auth application-default login, " +
+			"or set GOOGLE_APPLICATION_CREDENTIALS."
+	case strings.Contains(message, "403") || strings.Contains(message, "not_authorized") || strings.Contains(message, "forbidden"):
+		return "Grant the role roles/cloudsql.client (Cloud SQL Client) on the project of the instance to the principal of the " +
+			"application default credentials."
+	case strings.Contains(message, "404") || strings.Contains(message, "not found"):
+		return "Check the connection_name, it needs to be in the format project:region:instance."
+	default:
+		return "Check that the Cloud SQL Admin API is enabled and that the principal of the application default credentials " +
+			"has the role roles/cloudsql.client."
+	}
+}
+
+// tlsConnectionState returns the TLS state of the connection. The connector wraps the TLS connection, the
+// wrappers embed the net.Conn they wrap as the Conn field.
+func tlsConnectionState(conn net.Conn) (tls.ConnectionState, bool) {
+	for conn != nil {
+		if tlsConn, ok := conn.(interface{ ConnectionState() tls.ConnectionState }); ok {
+			return tlsConn.ConnectionState(), true
+		}
+
+		value := reflect.ValueOf(conn)
+		if value.Kind() == reflect.Pointer {
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.Struct {
+			break
+		}
+		field := value.FieldByName("Conn")
+		if !field.IsValid() || !field.CanInterface() {
+			break
+		}
+		conn, _ = field.Interface().(net.Conn)
+	}
+	return tls.ConnectionState{}, false
+}
+
+// directTLSConfigName returns the name the TLS configuration of address is registered with in the MySQL driver. The
+// registry of the driver is global, the name is derived from the certificates so provider aliases with different
+// certificates don't replace each other's configuration.
+func directTLSConfigName(certificatePEM, keyPEM, caPEM, serverName string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{certificatePEM, keyPEM, caPEM, serverName}, "\x00")))
+	return "cloudsqlmysql-direct-" + hex.EncodeToString(sum[:8])
+}
+
+// registerDirectTLSConfig registers the TLS configuration for connections to address and returns the DSN parameter
+// that selects it, empty when no certificates are configured. Cloud SQL server certificates only carry the instance
+// in the common name, which the standard verification ignores, so a server name is checked against the common name
+// after verifying the chain.
+func registerDirectTLSConfig(certificatePEM, keyPEM, caPEM, serverName string) (string, error) {
+	if certificatePEM == "" && caPEM == "" {
+		return "", nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if certificatePEM != "" {
+		certificate, err := tls.X509KeyPair([]byte(certificatePEM), []byte(keyPEM))
+		if err != nil {
+			return "", fmt.Errorf("invalid client certificate or key: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	switch {
+	case caPEM == "":
+		// Only the client certificate is configured, there is nothing to verify the server against. The provider
+		// refuses this when require_tls is set.
+		tlsConfig.InsecureSkipVerify = true
+	case serverName == "":
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM([]byte(caPEM)) {
+			return "", errors.New("the CA certificate contains no PEM encoded certificates")
+		}
+	default:
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM([]byte(caPEM)) {
+			return "", errors.New("the CA certificate contains no PEM encoded certificates")
+		}
+		tlsConfig.InsecureSkipVerify = true // Replaced by VerifyPeerCertificate
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyServerCertificate(rawCerts, roots, serverName)
+		}
+	}
+
+	name := directTLSConfigName(certificatePEM, keyPEM, caPEM, serverName)
+	if err := mysql.RegisterTLSConfig(name, tlsConfig); err != nil {
+		return "", err
+	}
+	return "&tls=" + name, nil
+}
+
+// verifyServerCertificate verifies the certificate chain of the server against the roots, and that the server
+// certificate is issued to the server name in its subject alternative names or its common name.
+func verifyServerCertificate(rawCerts [][]byte, roots *x509.CertPool, serverName string) error {
+	if len(rawCerts) == 0 {
+		return errors.New("the server presented no certificate")
+	}
+
+	var certificates []*x509.Certificate
+	for _, rawCert := range rawCerts {
+		certificate, err := x509.ParseCertificate(rawCert)
+		if err != nil {
+			return err
+		}
+		certificates = append(certificates, certificate)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, certificate := range certificates[1:] {
+		intermediates.AddCert(certificate)
+	}
+	leaf := certificates[0]
+	if _, err := leaf.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates}); err != nil {
+		return err
+	}
+
+	if leaf.Subject.CommonName != serverName && leaf.VerifyHostname(serverName) != nil {
+		return fmt.Errorf("the server certificate is issued to %q, expected %q", leaf.Subject.CommonName, serverName)
+	}
+	return nil
+}
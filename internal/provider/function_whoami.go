@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.Function = &whoamiFunction{}
+
+var whoamiAttributeTypes = map[string]attr.Type{
+	"username":        types.StringType,
+	"connection_name": types.StringType,
+}
+
+// whoamiFunction returns the username and connection name of the provider, never the password. The values are the ones
+// Configure resolved, including the environment variables unless disable_env_fallback is set.
+type whoamiFunction struct {
+	provider *CloudSqlMysqlProvider
+}
+
+func newWhoamiFunction(p *CloudSqlMysqlProvider) func() function.Function {
+	return func() function.Function {
+		return &whoamiFunction{provider: p}
+	}
+}
+
+func (f *whoamiFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "whoami"
+}
+
+func (f *whoamiFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Returns the username and connection name the provider uses",
+		Description: "Returns an object with the username and connection name the provider uses, the password is never returned. " +
+			"The values are the ones the provider configuration resolved, including the environment variables unless " +
+			"disable_env_fallback is set. Terraform can call functions before the provider is configured, both are null in that case.",
+		MarkdownDescription: "Returns an object with the `username` and `connection_name` the provider uses, the password is never returned. " +
+			"The values are the ones the provider configuration resolved, including the environment variables unless " +
+			"`disable_env_fallback` is set. Terraform can call functions before the provider is configured, both are null in that case.",
+		Return: function.ObjectReturn{
+			AttributeTypes: whoamiAttributeTypes,
+		},
+	}
+}
+
+func (f *whoamiFunction) Run(ctx context.Context, _ function.RunRequest, resp *function.RunResponse) {
+	username := types.StringNull()
+	connectionName := types.StringNull()
+	if f.provider.username != "" {
+		username = types.StringValue(f.provider.username)
+	}
+	if f.provider.connectionName != "" {
+		connectionName = types.StringValue(f.provider.connectionName)
+	}
+
+	result, diags := types.ObjectValue(whoamiAttributeTypes, map[string]attr.Value{
+		"username":        username,
+		"connection_name": connectionName,
+	})
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, result))
+}
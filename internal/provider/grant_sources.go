@@ -0,0 +1,300 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"terraform-provider-cloudsqlmysql/internal/grantparser"
+	"terraform-provider-cloudsqlmysql/internal/sqlgen"
+)
+
+// The sources the grants of an account can be read from, set with read_source.
+const (
+	readSourceShowGrants        = "show_grants"
+	readSourceInformationSchema = "information_schema"
+	readSourceMySQLTables       = "mysql_tables"
+)
+
+var readSources = []string{readSourceShowGrants, readSourceInformationSchema, readSourceMySQLTables}
+
+// defaultReadPageSize is the number of mysql.db rows read per query when read_page_size is not set.
+const defaultReadPageSize = 1000
+
+// mysqlDBPrivilegeColumns are the privilege columns of mysql.db, the same on the supported server versions. They are
+// selected by name so a page of mysql.db only holds the columns that are read.
+var mysqlDBPrivilegeColumns = []string{
+	"Select_priv", "Insert_priv", "Update_priv", "Delete_priv", "Create_priv", "Drop_priv", "Grant_priv", "References_priv",
+	"Index_priv", "Alter_priv", "Create_tmp_table_priv", "Lock_tables_priv", "Create_view_priv", "Show_view_priv",
+	"Create_routine_priv", "Alter_routine_priv", "Execute_priv", "Event_priv", "Trigger_priv",
+}
+
+// mysqlDBPageQuery reads a page of the mysql.db rows of an account. The primary key is Host, Db and User, so the
+// rows of the account are paginated on Db, which compares binary.
+var mysqlDBPageQuery = "SELECT Db, " + strings.Join(mysqlDBPrivilegeColumns, ", ") +
+	" FROM mysql.db WHERE User = ? AND Host = ? AND Db > ? ORDER BY Db LIMIT ?"
+
+// privilegeColumnNames are the privilege columns of mysql.user and mysql.db that don't follow the naming of the
+// privileges. The other columns are the privilege name with underscores and the _priv suffix, e.g. Show_view_priv.
+var privilegeColumnNames = map[string]string{
+	"Create_tmp_table_priv": "CREATE TEMPORARY TABLES",
+	"Show_db_priv":          "SHOW DATABASES",
+	"Repl_slave_priv":       "REPLICATION SLAVE",
+	"Repl_client_priv":      "REPLICATION CLIENT",
+}
+
+// readGrants returns the global, database and routine level grants of the account from the read_source. The grants
+// are returned like SHOW GRANTS returns them, so the callers compare them the same way for every source.
+func (c *Config) readGrants(ctx context.Context, db dbExecutor, user, host string) ([]*grantparser.Grant, error) {
+	switch c.readSource {
+	case readSourceInformationSchema:
+		return c.informationSchemaGrants(ctx, db, user, host)
+	case readSourceMySQLTables:
+		return c.mysqlTableGrants(ctx, db, user, host)
+	}
+	return c.showGrants(ctx, db, user, host)
+}
+
+// informationSchemaGrants reads the global and database level grants from INFORMATION_SCHEMA.USER_PRIVILEGES and
+// SCHEMA_PRIVILEGES. INFORMATION_SCHEMA has no view of the routine privileges and doesn't show partial revokes. The
+// views only show the rows of other accounts to users with SELECT on the mysql schema.
+func (c *Config) informationSchemaGrants(ctx context.Context, db dbExecutor, user, host string) ([]*grantparser.Grant, error) {
+	user, host = c.canonicalAccount(user, host)
+	grantee := grantparser.Account{User: user, Host: host}
+
+	global := &grantparser.Grant{Level: grantparser.LevelGlobal, Grantees: []grantparser.Account{grantee}}
+	err := c.queryRowsPrepared(ctx, db, "SELECT PRIVILEGE_TYPE, IS_GRANTABLE FROM INFORMATION_SCHEMA.USER_PRIVILEGES WHERE GRANTEE = ?",
+		[]any{sqlgen.Account(user, host)}, func(rows *sql.Rows) error {
+			var privilege string
+			var grantable sql.NullString
+			if err := rows.Scan(&privilege, &grantable); err != nil {
+				return err
+			}
+			global.Privileges = append(global.Privileges, grantparser.Privilege{Name: privilege})
+			global.WithGrantOption = global.WithGrantOption || grantable.String == "YES"
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	if len(global.Privileges) == 0 {
+		// Every account has at least USAGE on *.*, without SELECT on mysql the view only shows the current account.
+		var grantees int
+		err = queryRow(ctx, db, "SELECT COUNT(DISTINCT GRANTEE) FROM INFORMATION_SCHEMA.USER_PRIVILEGES", nil, &grantees)
+		if err != nil {
+			return nil, err
+		}
+		if grantees <= 1 {
+			return nil, fmt.Errorf("INFORMATION_SCHEMA.USER_PRIVILEGES only shows the privileges of the provider user, "+
+				"the grants of '%s'@'%s' can't be read: read_source information_schema requires SELECT on the mysql schema, "+
+				"grant it to the provider user or use read_source show_grants", user, host)
+		}
+		return nil, fmt.Errorf("there is no such grant defined for user '%s' on host '%s'", user, host)
+	}
+
+	grants := []*grantparser.Grant{global}
+	databases := make(map[string]*grantparser.Grant)
+	err = c.queryRowsPrepared(ctx, db, "SELECT TABLE_SCHEMA, PRIVILEGE_TYPE, IS_GRANTABLE FROM INFORMATION_SCHEMA.SCHEMA_PRIVILEGES WHERE GRANTEE = ?",
+		[]any{sqlgen.Account(user, host)}, func(rows *sql.Rows) error {
+			var database, privilege string
+			var grantable sql.NullString
+			if err := rows.Scan(&database, &privilege, &grantable); err != nil {
+				return err
+			}
+			grant, ok := databases[database]
+			if !ok {
+				grant = &grantparser.Grant{Level: grantparser.LevelDatabase, Database: database, Grantees: []grantparser.Account{grantee}}
+				databases[database] = grant
+				grants = append(grants, grant)
+			}
+			grant.Privileges = append(grant.Privileges, grantparser.Privilege{Name: privilege})
+			grant.WithGrantOption = grant.WithGrantOption || grantable.String == "YES"
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+// mysqlTableGrants reads the global, database and routine level grants from the privilege columns of mysql.user
+// and mysql.db, mysql.global_grants and mysql.procs_priv. The partial revokes are read from the Restrictions in
+// User_attributes of mysql.user.
+func (c *Config) mysqlTableGrants(ctx context.Context, db dbExecutor, user, host string) ([]*grantparser.Grant, error) {
+	user, host = c.canonicalAccount(user, host)
+	grantee := []grantparser.Account{{User: user, Host: host}}
+	args := []any{user, host}
+
+	var grants []*grantparser.Grant
+	err := c.queryRowsPrepared(ctx, db, "SELECT * FROM mysql.user WHERE User = ? AND Host = ?", args, func(rows *sql.Rows) error {
+		values, err := scanPrivilegeColumns(rows)
+		if err != nil {
+			return err
+		}
+		global := privilegeColumnsGrant(values)
+		global.Level = grantparser.LevelGlobal
+		global.Grantees = grantee
+		grants = append(grants, global)
+
+		var attributes struct {
+			Restrictions []struct {
+				Database   string
+				Privileges []string
+			}
+		}
+		if values["User_attributes"] != "" {
+			if err := json.Unmarshal([]byte(values["User_attributes"]), &attributes); err != nil {
+				return fmt.Errorf("parsing User_attributes: %w", err)
+			}
+		}
+		for _, restriction := range attributes.Restrictions {
+			revoke := &grantparser.Grant{Revoke: true, Level: grantparser.LevelDatabase, Database: restriction.Database, Grantees: grantee}
+			for _, privilege := range restriction.Privileges {
+				revoke.Privileges = append(revoke.Privileges, grantparser.Privilege{Name: privilege})
+			}
+			grants = append(grants, revoke)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(grants) == 0 {
+		return nil, fmt.Errorf("there is no such grant defined for user '%s' on host '%s'", user, host)
+	}
+	global := grants[0]
+	if len(global.Privileges) == 0 {
+		global.Privileges = []grantparser.Privilege{{Name: "USAGE"}}
+	}
+
+	if !c.serverVersion.less(mysql80) {
+		// The dynamic privileges are granted separately, SHOW GRANTS also lists them in a statement of their own
+		dynamic := &grantparser.Grant{Level: grantparser.LevelGlobal, Grantees: grantee}
+		err = c.queryRowsPrepared(ctx, db, "SELECT PRIV, WITH_GRANT_OPTION FROM mysql.global_grants WHERE USER = ? AND HOST = ?", args, func(rows *sql.Rows) error {
+			var privilege string
+			var withGrantOption sql.NullString
+			if err := rows.Scan(&privilege, &withGrantOption); err != nil {
+				return err
+			}
+			dynamic.Privileges = append(dynamic.Privileges, grantparser.Privilege{Name: privilege})
+			dynamic.WithGrantOption = dynamic.WithGrantOption || withGrantOption.String == "Y"
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(dynamic.Privileges) > 0 {
+			grants = append(grants, dynamic)
+		}
+	}
+
+	pageSize := c.readPageSize
+	if pageSize == 0 {
+		pageSize = defaultReadPageSize
+	}
+	// Only the rows with privileges are kept, a page of rows is read at a time
+	for lastDatabase, pageRows := "", pageSize; pageRows == pageSize; {
+		pageRows = 0
+		err = c.queryRowsPrepared(ctx, db, mysqlDBPageQuery, []any{user, host, lastDatabase, pageSize}, func(rows *sql.Rows) error {
+			values, err := scanPrivilegeColumns(rows)
+			if err != nil {
+				return err
+			}
+			pageRows++
+			lastDatabase = values["Db"]
+			grant := privilegeColumnsGrant(values)
+			if len(grant.Privileges) == 0 && !grant.WithGrantOption {
+				return nil
+			}
+			if len(grant.Privileges) == 0 {
+				grant.Privileges = []grantparser.Privilege{{Name: "USAGE"}}
+			}
+			grant.Level = grantparser.LevelDatabase
+			grant.Database = values["Db"]
+			grant.Grantees = grantee
+			grants = append(grants, grant)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = c.queryRowsPrepared(ctx, db, "SELECT Db, Routine_name, Routine_type, Proc_priv FROM mysql.procs_priv WHERE User = ? AND Host = ?", args, func(rows *sql.Rows) error {
+		var database, routine, objectType string
+		var privileges sql.NullString
+		if err := rows.Scan(&database, &routine, &objectType, &privileges); err != nil {
+			return err
+		}
+		grant := &grantparser.Grant{Level: grantparser.LevelRoutine, ObjectType: objectType, Database: database, Object: routine, Grantees: grantee}
+		for _, privilege := range strings.Split(privileges.String, ",") {
+			switch {
+			case privilege == "":
+			case strings.EqualFold(privilege, "Grant"):
+				grant.WithGrantOption = true
+			default:
+				grant.Privileges = append(grant.Privileges, grantparser.Privilege{Name: strings.ToUpper(privilege)})
+			}
+		}
+		if len(grant.Privileges) == 0 {
+			grant.Privileges = []grantparser.Privilege{{Name: "USAGE"}}
+		}
+		grants = append(grants, grant)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+// scanPrivilegeColumns scans a row of mysql.user or mysql.db into a map by column name. Some managed MySQL variants
+// return NULL in privilege columns, NULL is scanned as an empty string so the privilege is read like N.
+func scanPrivilegeColumns(rows *sql.Rows) (map[string]string, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]sql.NullString, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err = rows.Scan(pointers...); err != nil {
+		return nil, err
+	}
+
+	row := make(map[string]string, len(columns))
+	for i, column := range columns {
+		row[column] = values[i].String
+	}
+	return row, nil
+}
+
+// privilegeColumnsGrant returns a grant with the privileges of the privilege columns set to Y, Grant_priv is the
+// grant option.
+func privilegeColumnsGrant(values map[string]string) *grantparser.Grant {
+	grant := &grantparser.Grant{}
+	for column, value := range values {
+		if !strings.HasSuffix(column, "_priv") || value != "Y" {
+			continue
+		}
+		if column == "Grant_priv" {
+			grant.WithGrantOption = true
+			continue
+		}
+		name, ok := privilegeColumnNames[column]
+		if !ok {
+			name = strings.ToUpper(strings.ReplaceAll(strings.TrimSuffix(column, "_priv"), "_", " "))
+		}
+		grant.Privileges = append(grant.Privileges, grantparser.Privilege{Name: name})
+	}
+	// The columns are in a map, the privileges are sorted to return them in the same order on every read
+	sort.Slice(grant.Privileges, func(i, j int) bool {
+		return grant.Privileges[i].Name < grant.Privileges[j].Name
+	})
+	return grant
+}
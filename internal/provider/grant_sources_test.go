@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+
+	"terraform-provider-cloudsqlmysql/internal/grantparser"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestMySQLTableGrantsNullColumns(t *testing.T) {
+	// Some managed MySQL variants return NULL instead of N in the privilege columns
+	db, mock := newMockDB(t)
+	config := &Config{serverVersion: serverVersion{major: 8}}
+	args := []driver.Value{"app", "%"}
+
+	mock.ExpectPrepare("SELECT * FROM mysql.user WHERE User = ? AND Host = ?").ExpectQuery().WithArgs(args...).WillReturnRows(
+		sqlmock.NewRows([]string{"Host", "User", "Select_priv", "Insert_priv", "Process_priv", "Grant_priv", "User_attributes"}).
+			AddRow("%", "app", nil, nil, "Y", nil, nil))
+	mock.ExpectPrepare("SELECT PRIV, WITH_GRANT_OPTION FROM mysql.global_grants WHERE USER = ? AND HOST = ?").ExpectQuery().WithArgs(args...).WillReturnRows(
+		sqlmock.NewRows([]string{"PRIV", "WITH_GRANT_OPTION"}).AddRow("BACKUP_ADMIN", nil))
+	mock.ExpectPrepare(mysqlDBPageQuery).ExpectQuery().WithArgs("app", "%", "", int64(defaultReadPageSize)).WillReturnRows(
+		sqlmock.NewRows([]string{"Db", "Select_priv", "Insert_priv", "Delete_priv", "Grant_priv"}).
+			AddRow("app", "Y", nil, "N", nil).
+			AddRow("other", nil, nil, nil, nil))
+	mock.ExpectPrepare("SELECT Db, Routine_name, Routine_type, Proc_priv FROM mysql.procs_priv WHERE User = ? AND Host = ?").ExpectQuery().WithArgs(args...).WillReturnRows(
+		sqlmock.NewRows([]string{"Db", "Routine_name", "Routine_type", "Proc_priv"}).AddRow("app", "refresh", "PROCEDURE", nil))
+
+	grants, err := config.mysqlTableGrants(context.Background(), db, "app", "%")
+	if err != nil {
+		t.Fatalf("mysqlTableGrants returned error: %v", err)
+	}
+
+	grantee := []grantparser.Account{{User: "app", Host: "%"}}
+	want := []*grantparser.Grant{
+		{Level: grantparser.LevelGlobal, Privileges: []grantparser.Privilege{{Name: "PROCESS"}}, Grantees: grantee},
+		{Level: grantparser.LevelGlobal, Privileges: []grantparser.Privilege{{Name: "BACKUP_ADMIN"}}, Grantees: grantee},
+		{Level: grantparser.LevelDatabase, Database: "app", Privileges: []grantparser.Privilege{{Name: "SELECT"}}, Grantees: grantee},
+		{
+			Level:      grantparser.LevelRoutine,
+			ObjectType: "PROCEDURE",
+			Database:   "app",
+			Object:     "refresh",
+			Privileges: []grantparser.Privilege{{Name: "USAGE"}},
+			Grantees:   grantee,
+		},
+	}
+	if len(grants) != len(want) {
+		t.Fatalf("mysqlTableGrants returned %d grants, want %d", len(grants), len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(grants[i], want[i]) {
+			t.Errorf("grant %d is %+v, want %+v", i, grants[i], want[i])
+		}
+	}
+}
+
+func TestMySQLTableGrantsPages(t *testing.T) {
+	db, mock := newMockDB(t)
+	config := &Config{readPageSize: 2}
+	args := []driver.Value{"app", "%"}
+	columns := []string{"Db", "Select_priv", "Grant_priv"}
+
+	mock.ExpectPrepare("SELECT * FROM mysql.user WHERE User = ? AND Host = ?").ExpectQuery().WithArgs(args...).WillReturnRows(
+		sqlmock.NewRows([]string{"Host", "User", "Select_priv"}).AddRow("%", "app", "N"))
+	prepared := mock.ExpectPrepare(mysqlDBPageQuery)
+	prepared.ExpectQuery().WithArgs("app", "%", "", int64(2)).WillReturnRows(
+		sqlmock.NewRows(columns).AddRow("a", "Y", "N").AddRow("b", "N", "N"))
+	prepared.ExpectQuery().WithArgs("app", "%", "b", int64(2)).WillReturnRows(
+		sqlmock.NewRows(columns).AddRow("c", "Y", "Y"))
+	mock.ExpectPrepare("SELECT Db, Routine_name, Routine_type, Proc_priv FROM mysql.procs_priv WHERE User = ? AND Host = ?").ExpectQuery().WithArgs(args...).WillReturnRows(
+		sqlmock.NewRows([]string{"Db", "Routine_name", "Routine_type", "Proc_priv"}))
+
+	grants, err := config.mysqlTableGrants(context.Background(), db, "app", "%")
+	if err != nil {
+		t.Fatalf("mysqlTableGrants returned error: %v", err)
+	}
+	var databases []string
+	for _, grant := range grants[1:] {
+		databases = append(databases, grant.Database)
+	}
+	if strings.Join(databases, ",") != "a,c" {
+		t.Errorf("mysqlTableGrants returned the database grants on %q, want a and c", databases)
+	}
+}
+
+func TestPrivilegeColumnsGrant(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]string
+		want   *grantparser.Grant
+	}{
+		{
+			name:   "null columns",
+			values: map[string]string{"Db": "app", "Select_priv": "", "Insert_priv": "", "Grant_priv": ""},
+			want:   &grantparser.Grant{},
+		},
+		{
+			name:   "mixed columns",
+			values: map[string]string{"Db": "app", "Select_priv": "Y", "Create_tmp_table_priv": "Y", "Show_view_priv": "", "Grant_priv": "Y"},
+			want: &grantparser.Grant{
+				Privileges:      []grantparser.Privilege{{Name: "CREATE TEMPORARY TABLES"}, {Name: "SELECT"}},
+				WithGrantOption: true,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := privilegeColumnsGrant(test.values); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("privilegeColumnsGrant() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestInformationSchemaGrantsNullGrantable(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectPrepare("SELECT PRIVILEGE_TYPE, IS_GRANTABLE FROM INFORMATION_SCHEMA.USER_PRIVILEGES WHERE GRANTEE = ?").ExpectQuery().
+		WithArgs("'app'@'%'").WillReturnRows(sqlmock.NewRows([]string{"PRIVILEGE_TYPE", "IS_GRANTABLE"}).AddRow("USAGE", nil))
+	mock.ExpectPrepare("SELECT TABLE_SCHEMA, PRIVILEGE_TYPE, IS_GRANTABLE FROM INFORMATION_SCHEMA.SCHEMA_PRIVILEGES WHERE GRANTEE = ?").ExpectQuery().
+		WithArgs("'app'@'%'").WillReturnRows(sqlmock.NewRows([]string{"TABLE_SCHEMA", "PRIVILEGE_TYPE", "IS_GRANTABLE"}).
+		AddRow("app", "SELECT", nil).AddRow("app", "INSERT", "YES"))
+
+	grants, err := (&Config{}).informationSchemaGrants(context.Background(), db, "app", "%")
+	if err != nil {
+		t.Fatalf("informationSchemaGrants returned error: %v", err)
+	}
+	if len(grants) != 2 || grants[0].WithGrantOption || !grants[1].WithGrantOption || len(grants[1].Privileges) != 2 {
+		t.Errorf("informationSchemaGrants returned %+v", grants)
+	}
+}
+
+func TestInformationSchemaGrantsWithoutMySQLSchemaAccess(t *testing.T) {
+	// Without SELECT on mysql the view only shows the rows of the provider user
+	db, mock := newMockDB(t)
+	mock.ExpectPrepare("SELECT PRIVILEGE_TYPE, IS_GRANTABLE FROM INFORMATION_SCHEMA.USER_PRIVILEGES WHERE GRANTEE = ?").ExpectQuery().
+		WithArgs("'app'@'%'").WillReturnRows(sqlmock.NewRows([]string{"PRIVILEGE_TYPE", "IS_GRANTABLE"}))
+	mock.ExpectQuery("SELECT COUNT(DISTINCT GRANTEE) FROM INFORMATION_SCHEMA.USER_PRIVILEGES").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(DISTINCT GRANTEE)"}).AddRow(1))
+
+	_, err := (&Config{}).informationSchemaGrants(context.Background(), db, "app", "%")
+	if err == nil || !strings.Contains(err.Error(), "requires SELECT on the mysql schema") {
+		t.Errorf("informationSchemaGrants returned error %v, want the missing privilege", err)
+	}
+}
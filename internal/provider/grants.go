@@ -0,0 +1,291 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"terraform-provider-cloudsqlmysql/internal/grantparser"
+	"terraform-provider-cloudsqlmysql/internal/sqlgen"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	// mysqlErrNonexistingGrant is ER_NONEXISTING_GRANT: the account doesn't exist or has no privileges on the level.
+	mysqlErrNonexistingGrant = 1141
+	// mysqlErrNonexistingTableGrant is ER_NONEXISTING_TABLE_GRANT, for table and column privileges.
+	mysqlErrNonexistingTableGrant = 1147
+	// mysqlErrCannotUser is ER_CANNOT_USER: the operation failed for an account that doesn't exist.
+	mysqlErrCannotUser = 1396
+	// mysqlErrNonexistingProcGrant is ER_NONEXISTING_PROC_GRANT, for routine privileges.
+	mysqlErrNonexistingProcGrant = 1403
+)
+
+// showGrants returns the parsed grants of the account using SHOW GRANTS.
+func (c *Config) showGrants(ctx context.Context, db dbExecutor, user, host string) ([]*grantparser.Grant, error) {
+	return c.queryGrants(ctx, db, "SHOW GRANTS FOR "+c.quoteAccount(user, host))
+}
+
+// showGrantsUsing returns the grants of the account with the privileges of the roles merged in, as if the roles
+// were active. The roles need to be quoted accounts granted to the account.
+func (c *Config) showGrantsUsing(ctx context.Context, db dbExecutor, user, host string, roles []string) ([]*grantparser.Grant, error) {
+	return c.queryGrants(ctx, db, "SHOW GRANTS FOR "+c.quoteAccount(user, host)+" USING "+strings.Join(roles, ", "))
+}
+
+// accountMissingError checks if the error of a REVOKE or SHOW GRANTS means there is nothing to revoke: the account
+// was dropped, e.g. a role destroyed before its grants, or it no longer holds the privileges.
+func accountMissingError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	switch mysqlErr.Number {
+	case mysqlErrNonexistingGrant, mysqlErrNonexistingTableGrant, mysqlErrCannotUser, mysqlErrNonexistingProcGrant:
+		return true
+	}
+	return false
+}
+
+// accountDropped checks if the account no longer exists. SHOW GRANTS lists at least USAGE for every account, it
+// only fails with ER_NONEXISTING_GRANT when the account is gone.
+func (c *Config) accountDropped(ctx context.Context, db dbExecutor, user, host string) bool {
+	_, err := c.showGrants(ctx, db, user, host)
+	return accountMissingError(err)
+}
+
+// queryGrants runs the SHOW GRANTS query and parses the statements it returns. The account is part of the statement,
+// SHOW GRANTS takes no arguments, so queryRowsPrepared sends it as a text query.
+func (c *Config) queryGrants(ctx context.Context, db dbExecutor, query string) ([]*grantparser.Grant, error) {
+	var statements []string
+	err := c.queryRowsPrepared(ctx, db, query, nil, func(rows *sql.Rows) error {
+		var statement string
+		if err := rows.Scan(&statement); err != nil {
+			return err
+		}
+		statements = append(statements, statement)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grantparser.ParseAll(statements)
+}
+
+// readDatabaseGrant returns the database level grant of the account from the read_source, nil is returned when
+// the account has no privileges on the database.
+func readDatabaseGrant(ctx context.Context, db dbExecutor, config *Config, user, host, database string) (*grantparser.Grant, error) {
+	grants, err := config.readGrants(ctx, db, user, host)
+	if err != nil {
+		return nil, err
+	}
+	return findDatabaseGrant(grants, config, database), nil
+}
+
+// grantDifferences returns how the grant read back after an apply differs from the privileges that were granted and
+// revoked, empty when the server matches. The grant is nil when the account has no privileges on the level.
+func grantDifferences(grant *grantparser.Grant, granted, revoked []string, withGrantOption bool) []string {
+	var held []string
+	heldWithGrantOption := false
+	if grant != nil {
+		held = grant.PrivilegeNames()
+		heldWithGrantOption = grant.WithGrantOption
+	}
+	holds := func(privilege string) bool {
+		for _, name := range held {
+			if privilegeNamesEqual(name, privilege) || privilegeNamesEqual(name, "ALL PRIVILEGES") {
+				return true
+			}
+		}
+		return false
+	}
+
+	var differences []string
+	for _, privilege := range granted {
+		if !holds(privilege) {
+			differences = append(differences, privilege+" was granted but is not held")
+		}
+	}
+	for _, privilege := range revoked {
+		if holds(privilege) {
+			differences = append(differences, privilege+" was revoked but is still held")
+		}
+	}
+	if len(granted) > 0 && withGrantOption && !heldWithGrantOption {
+		differences = append(differences, "the grant option was granted but is not held")
+	}
+	return differences
+}
+
+// verifyDatabaseGrant reads the database level grant back after an apply with verify_after_apply, an error lists the
+// differences with the privileges that were granted and revoked. Cloud SQL can leave out privileges without an error.
+func verifyDatabaseGrant(ctx context.Context, db dbExecutor, config *Config, user, host, database string, granted, revoked []string, withGrantOption bool) error {
+	grant, err := readDatabaseGrant(ctx, db, config, user, host, database)
+	if err != nil {
+		return fmt.Errorf("reading the grants back: %w", err)
+	}
+	if differences := grantDifferences(grant, granted, revoked, withGrantOption); len(differences) > 0 {
+		return verifyError(config.quoteAccount(user, host), sqlgen.DatabaseLevel(database), differences)
+	}
+	return nil
+}
+
+// verifyError is the error of a grant that doesn't match after an apply.
+func verifyError(account, level string, differences []string) error {
+	return fmt.Errorf("the grants of %s on %s read back after the apply don't match, the server may have left out "+
+		"privileges the provider user can't grant: %s", account, level, strings.Join(differences, "; "))
+}
+
+// findDatabaseGrant returns the database level grant on the database, nil is returned when there is none.
+func findDatabaseGrant(grants []*grantparser.Grant, config *Config, database string) *grantparser.Grant {
+	for _, grant := range grants {
+		if !grant.Revoke && grant.Level == grantparser.LevelDatabase && config.databaseNamesEqual(grant.Database, database) {
+			return grant
+		}
+	}
+	return nil
+}
+
+// routineGrant is a routine of a schema and the privileges the account has on it, Grant is nil when the account
+// has no privileges on the routine.
+type routineGrant struct {
+	ObjectType string
+	Name       string
+	Grant      *grantparser.Grant
+}
+
+// target returns the privilege level of the routine for GRANT and REVOKE statements.
+func (g routineGrant) target(database string) string {
+	return sqlgen.RoutineLevel(g.ObjectType, database, g.Name)
+}
+
+// readRoutineGrants returns the routines of the object types in the database with the routine level grants of
+// the account. MySQL has no wildcard for routine level grants, so they are aggregated over the existing routines.
+func readRoutineGrants(ctx context.Context, db dbExecutor, config *Config, user, host, database string, objectTypes []string) ([]routineGrant, error) {
+	query := "SELECT ROUTINE_TYPE, ROUTINE_NAME FROM INFORMATION_SCHEMA.ROUTINES WHERE ROUTINE_SCHEMA = ? AND ROUTINE_TYPE IN (?" +
+		strings.Repeat(", ?", len(objectTypes)-1) + ") ORDER BY ROUTINE_TYPE, ROUTINE_NAME"
+	args := []any{database}
+	for _, objectType := range objectTypes {
+		args = append(args, objectType)
+	}
+
+	var routines []routineGrant
+	err := config.queryRowsPrepared(ctx, db, query, args, func(rows *sql.Rows) error {
+		var routine routineGrant
+		if err := rows.Scan(&routine.ObjectType, &routine.Name); err != nil {
+			return err
+		}
+		routines = append(routines, routine)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(routines) == 0 {
+		return routines, nil
+	}
+
+	readGrants := config.readGrants
+	if config.readSource == readSourceInformationSchema {
+		// INFORMATION_SCHEMA has no view of the routine privileges
+		readGrants = func(ctx context.Context, db dbExecutor, user, host string) ([]*grantparser.Grant, error) {
+			return config.showGrants(ctx, db, user, host)
+		}
+	}
+	grants, err := readGrants(ctx, db, user, host)
+	if err != nil {
+		return nil, err
+	}
+	for i := range routines {
+		for _, grant := range grants {
+			// Routine names are not case-sensitive in MySQL
+			if !grant.Revoke && grant.Level == grantparser.LevelRoutine && grant.ObjectType == routines[i].ObjectType &&
+				config.databaseNamesEqual(grant.Database, database) && strings.EqualFold(grant.Object, routines[i].Name) {
+				routines[i].Grant = grant
+				break
+			}
+		}
+	}
+	return routines, nil
+}
+
+// aggregateRoutinePrivileges returns the privileges the account has on all routines, and whether all routines
+// with grants have the grant option. granted is false when the account has no privileges on any routine. ALL
+// PRIVILEGES is expanded per routine, so it intersects with the privileges of the other routines.
+func aggregateRoutinePrivileges(routines []routineGrant, config *Config) (privileges []string, withGrantOption bool, granted bool) {
+	withGrantOption = true
+	for i, routine := range routines {
+		var names []string
+		if routine.Grant != nil {
+			names = config.expandAllPrivileges(nil, routine.Grant.PrivilegeNames(), levelRoutine)
+			withGrantOption = withGrantOption && routine.Grant.WithGrantOption
+			granted = true
+		}
+		if i == 0 {
+			privileges = names
+		} else {
+			privileges = privilegesIntersection(privileges, names)
+		}
+	}
+	return privileges, withGrantOption && granted, granted
+}
+
+// grantOptionPrivilege is revoked like a privilege to remove WITH GRANT OPTION.
+const grantOptionPrivilege = "GRANT OPTION"
+
+// globalGrantOption checks if the account has the grant option on *.* that is not partially revoked on the database.
+// It lets the account grant its privileges on the database regardless of the grant option on the database.
+func globalGrantOption(grants []*grantparser.Grant, config *Config, database string) bool {
+	global := false
+	for _, grant := range grants {
+		switch {
+		case grant.Level == grantparser.LevelGlobal && !grant.Revoke && grant.WithGrantOption:
+			global = true
+		case grant.Level == grantparser.LevelDatabase && grant.Revoke && config.databaseNamesEqual(grant.Database, database) &&
+			grant.HasPrivilege(grantOptionPrivilege):
+			return false
+		}
+	}
+	return global
+}
+
+// globalDatabasePrivileges returns the privileges granted on *.* that also apply to the database. Privileges that
+// only exist on the global level are left out, and so are the privileges revoked from the database with partial revokes.
+func globalDatabasePrivileges(grants []*grantparser.Grant, config *Config, database string) []string {
+	var global, revoked []string
+	for _, grant := range grants {
+		switch {
+		case grant.Level == grantparser.LevelGlobal && !grant.Revoke:
+			// Expanded first, a partial revoke takes single privileges away from ALL PRIVILEGES
+			global = append(global, config.expandAllPrivileges(nil, grant.PrivilegeNames(), levelDatabase)...)
+		case grant.Level == grantparser.LevelDatabase && grant.Revoke && config.databaseNamesEqual(grant.Database, database):
+			revoked = append(revoked, grant.PrivilegeNames()...)
+		}
+	}
+
+	var privileges []string
+	for _, privilege := range withoutPrivileges(global, revoked) {
+		if privilegeLevelError(privilege, levelDatabase, "database") == "" && !privilegeNamesEqual(privilege, "USAGE") {
+			privileges = append(privileges, privilege)
+		}
+	}
+	return privileges
+}
+
+// splitRoutineGrantOption returns the names of the routines with grants that have the grant option and of those
+// that don't. MySQL stores the grant option per routine, so routines granted at different times can disagree.
+func splitRoutineGrantOption(routines []routineGrant) (with []string, without []string) {
+	for _, routine := range routines {
+		if routine.Grant == nil {
+			continue
+		}
+		if routine.Grant.WithGrantOption {
+			with = append(with, routine.ObjectType+" "+routine.Name)
+		} else {
+			without = append(without, routine.ObjectType+" "+routine.Name)
+		}
+	}
+	return with, without
+}
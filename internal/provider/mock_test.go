@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// newMockDB returns a pool of sqlmock that matches the SQL text exactly, the expectations are checked when the
+// test ends.
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Unable to create the mock database: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+	return db, mock
+}
+
+// expectConnectionID expects the SELECT CONNECTION_ID() execOnConn runs before every statement.
+func expectConnectionID(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT CONNECTION_ID()").WillReturnRows(sqlmock.NewRows([]string{"CONNECTION_ID()"}).AddRow(42))
+}
+
+// expectShowGrants expects SHOW GRANTS for the account returning the statements.
+func expectShowGrants(mock sqlmock.Sqlmock, account string, statements ...string) {
+	rows := sqlmock.NewRows([]string{"Grants"})
+	for _, statement := range statements {
+		rows.AddRow(statement)
+	}
+	mock.ExpectQuery("SHOW GRANTS FOR " + account).WillReturnRows(rows)
+}
+
+// roundTripDriver is a database/sql driver that waits latency on every round trip to the server and counts the round
+// trips to compare prepared statement reuse with ad-hoc queries. Like the MySQL driver it sends a query without
+// arguments as text in one round trip, and prepares a statement for a query with arguments and closes it again. A
+// query answers the rows of its results, or a single row of one column when it has none.
+type roundTripDriver struct {
+	latency    time.Duration
+	results    map[string]roundTripResult
+	roundTrips atomic.Int64
+}
+
+// roundTripResult are the rows a query of the roundTripDriver answers.
+type roundTripResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (d *roundTripDriver) roundTrip() {
+	d.roundTrips.Add(1)
+	time.Sleep(d.latency)
+}
+
+func (d *roundTripDriver) rows(query string) driver.Rows {
+	result, ok := d.results[query]
+	if !ok {
+		result = roundTripResult{columns: []string{"1"}, rows: [][]driver.Value{{int64(1)}}}
+	}
+	return &roundTripRows{result: result}
+}
+
+func (d *roundTripDriver) Connect(context.Context) (driver.Conn, error) { return roundTripConn{d}, nil }
+func (d *roundTripDriver) Driver() driver.Driver                        { return nil }
+
+type roundTripConn struct{ d *roundTripDriver }
+
+func (c roundTripConn) Prepare(query string) (driver.Stmt, error) {
+	c.d.roundTrip()
+	return roundTripStmt{d: c.d, query: query}, nil
+}
+func (c roundTripConn) Close() error              { return nil }
+func (c roundTripConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+func (c roundTripConn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	if len(args) > 0 {
+		return nil, driver.ErrSkip
+	}
+	c.d.roundTrip()
+	return c.d.rows(query), nil
+}
+
+type roundTripStmt struct {
+	d     *roundTripDriver
+	query string
+}
+
+func (s roundTripStmt) Close() error {
+	s.d.roundTrip()
+	return nil
+}
+func (s roundTripStmt) NumInput() int                              { return -1 }
+func (s roundTripStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s roundTripStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.d.roundTrip()
+	return s.d.rows(s.query), nil
+}
+
+type roundTripRows struct {
+	result roundTripResult
+	next   int
+}
+
+func (r *roundTripRows) Columns() []string { return r.result.columns }
+func (r *roundTripRows) Close() error      { return nil }
+func (r *roundTripRows) Next(dest []driver.Value) error {
+	if r.next == len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.next])
+	r.next++
+	return nil
+}
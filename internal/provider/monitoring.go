@@ -0,0 +1,249 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"terraform-provider-cloudsqlmysql/internal/grantparser"
+	"terraform-provider-cloudsqlmysql/internal/sqlgen"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// monitoringPrivileges are the privileges a monitoring agent needs on a level, the database is empty for *.*.
+type monitoringPrivileges struct {
+	database   string
+	privileges []string
+}
+
+// performanceSchemaPrivileges are the privileges to read the processlist and the performance_schema tables, the
+// privileges of cloudsqlmysql_monitoring_grant.
+var performanceSchemaPrivileges = []monitoringPrivileges{
+	{privileges: []string{"PROCESS"}},
+	{database: "performance_schema", privileges: []string{"SELECT"}},
+}
+
+// monitoringAgents are the privileges the agent of cloudsqlmysql_monitoring_user is granted, from the setup
+// instructions of the agents.
+var monitoringAgents = map[string][]monitoringPrivileges{
+	"datadog": {
+		{privileges: []string{"PROCESS", "REPLICATION CLIENT"}},
+		{database: "performance_schema", privileges: []string{"SELECT"}},
+	},
+	"pmm": {
+		{privileges: []string{"SELECT", "PROCESS", "REPLICATION CLIENT", "RELOAD"}},
+	},
+	"cloudsql_insights": performanceSchemaPrivileges,
+}
+
+func monitoringAgentNames() []string {
+	names := make([]string, 0, len(monitoringAgents))
+	for name := range monitoringAgents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// level returns the privilege level for GRANT and REVOKE statements.
+func (p monitoringPrivileges) level() string {
+	if p.database == "" {
+		return "*.*"
+	}
+	return sqlgen.DatabaseLevel(p.database)
+}
+
+// held returns the privileges of p the grants hold. SHOW GRANTS lists the dynamic privileges on *.* in a grant of
+// their own, so the grants on the level are merged.
+func (p monitoringPrivileges) held(grants []*grantparser.Grant, config *Config) []string {
+	merged := &grantparser.Grant{}
+	for _, grant := range grants {
+		if grant.Revoke {
+			continue
+		}
+		if (p.database == "" && grant.Level == grantparser.LevelGlobal) ||
+			(p.database != "" && grant.Level == grantparser.LevelDatabase && config.databaseNamesEqual(grant.Database, p.database)) {
+			merged.Privileges = append(merged.Privileges, grant.Privileges...)
+		}
+	}
+
+	var held []string
+	for _, privilege := range p.privileges {
+		if len(grantDifferences(merged, []string{privilege}, nil, false)) == 0 {
+			held = append(held, privilege)
+		}
+	}
+	return held
+}
+
+// monitoringPrivilegesHeld checks if the grants hold all the privileges.
+func monitoringPrivilegesHeld(grants []*grantparser.Grant, config *Config, required []monitoringPrivileges) bool {
+	for _, p := range required {
+		if len(p.held(grants, config)) != len(p.privileges) {
+			return false
+		}
+	}
+	return true
+}
+
+// verifyMonitoringPrivileges returns an error when the grants read back after an apply don't hold all the privileges.
+func verifyMonitoringPrivileges(account string, grants []*grantparser.Grant, config *Config, required []monitoringPrivileges) error {
+	for _, p := range required {
+		var differences []string
+		for _, privilege := range withoutPrivileges(p.privileges, p.held(grants, config)) {
+			differences = append(differences, privilege+" was granted but is not held")
+		}
+		if len(differences) > 0 {
+			return verifyError(account, p.level(), differences)
+		}
+	}
+	return nil
+}
+
+// checkMonitoringCapabilities checks that the provider user can grant the privileges, and warns when they include
+// performance_schema while it is disabled. On Cloud SQL only the members of cloudsqlsuperuser hold them with the
+// grant option.
+func checkMonitoringCapabilities(ctx context.Context, db dbPool, config *Config, required []monitoringPrivileges) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, p := range required {
+		if p.database != "performance_schema" {
+			continue
+		}
+		enabled, err := performanceSchemaEnabled(ctx, db, config)
+		if err != nil {
+			diags.AddError(
+				"Error checking the monitoring capabilities",
+				"Could not read @@performance_schema, unexpected error: "+err.Error(),
+			)
+			return diags
+		}
+		if !enabled {
+			diags.AddWarning(
+				"performance_schema is disabled",
+				"The privileges are granted, but the tables of performance_schema stay empty until the performance_schema flag of "+
+					"the Cloud SQL instance is set to on, which restarts the instance",
+			)
+		}
+		break
+	}
+
+	var current string
+	err := config.queryRowPrepared(ctx, db, "SELECT CURRENT_USER()", nil, &current)
+	if err != nil {
+		diags.AddError(
+			"Error checking the monitoring capabilities",
+			"Could not read the provider user, unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+	// CURRENT_USER() returns user@host unquoted, the host never contains an @
+	at := strings.LastIndex(current, "@")
+	if at < 0 {
+		diags.AddError("Error checking the monitoring capabilities", "Unexpected value of CURRENT_USER(): "+current)
+		return diags
+	}
+	providerAccount := grantparser.Account{User: current[:at], Host: current[at+1:]}
+
+	// The provider user may hold the privileges through roles, e.g. cloudsqlsuperuser on MySQL 8.0
+	grantsPerAccount, _, err := config.collectGrantsWithRoles(ctx, db, providerAccount)
+	if err != nil {
+		diags.AddError(
+			"Error checking the monitoring capabilities",
+			"Could not read the grants of the provider user "+providerAccount.String()+", unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+	var grantable []*grantparser.Grant
+	for _, grants := range grantsPerAccount {
+		for _, grant := range grants {
+			if grant.WithGrantOption {
+				grantable = append(grantable, grant)
+			}
+		}
+	}
+
+	var missing []string
+	for _, p := range required {
+		held := p.held(grantable, config)
+		if p.database != "" {
+			// The privileges on *.* can be granted on every database
+			held = append(held, monitoringPrivileges{privileges: p.privileges}.held(grantable, config)...)
+		}
+		for _, privilege := range withoutPrivileges(p.privileges, held) {
+			missing = append(missing, privilege+" on "+p.level())
+		}
+	}
+	if len(missing) > 0 {
+		diags.AddError(
+			"Provider user can't grant the monitoring privileges",
+			"The provider user "+providerAccount.String()+" needs "+strings.Join(missing, ", ")+" WITH GRANT OPTION to grant "+
+				"them. On Cloud SQL the users created through the Cloud SQL Admin API are members of cloudsqlsuperuser, which holds them. "+
+				"See "+cloudSQLPrivilegesDocumentation,
+		)
+	}
+	return diags
+}
+
+// performanceSchemaEnabled checks if performance_schema is enabled, on Cloud SQL with the performance_schema flag.
+func performanceSchemaEnabled(ctx context.Context, db dbPool, config *Config) (bool, error) {
+	var enabled bool
+	err := config.queryRowPrepared(ctx, db, "SELECT @@GLOBAL.performance_schema", nil, &enabled)
+	return enabled, err
+}
+
+// grantMonitoringPrivileges grants the privileges to the account and reads them back, Cloud SQL can leave out
+// privileges without an error.
+func grantMonitoringPrivileges(ctx context.Context, db dbPool, config *Config, user, host string, required []monitoringPrivileges) error {
+	account := config.quoteAccount(user, host)
+	for _, p := range required {
+		_, err := execContext(ctx, db, sqlgen.Grant(p.privileges, p.level(), account, false))
+		if err != nil {
+			return err
+		}
+	}
+
+	grants, err := config.readGrants(ctx, db, user, host)
+	if err != nil {
+		return err
+	}
+	return verifyMonitoringPrivileges(account, grants, config, required)
+}
+
+// revokeMonitoringPrivileges revokes the privileges the account still holds, MySQL fails to revoke privileges that
+// aren't granted.
+func revokeMonitoringPrivileges(ctx context.Context, db dbPool, config *Config, user, host string, privileges []monitoringPrivileges) error {
+	grants, err := config.readGrants(ctx, db, user, host)
+	if err != nil {
+		return err
+	}
+	for _, p := range privileges {
+		held := p.held(grants, config)
+		if len(held) == 0 {
+			continue
+		}
+		_, err = execContext(ctx, db, sqlgen.Revoke(held, p.level(), config.quoteAccount(user, host)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// monitoringPrivilegesDifference returns the privileges of a that are not in b on the same level.
+func monitoringPrivilegesDifference(a, b []monitoringPrivileges) []monitoringPrivileges {
+	var difference []monitoringPrivileges
+	for _, pa := range a {
+		remaining := pa.privileges
+		for _, pb := range b {
+			if pa.database == pb.database {
+				remaining = withoutPrivileges(remaining, pb.privileges)
+			}
+		}
+		if len(remaining) > 0 {
+			difference = append(difference, monitoringPrivileges{database: pa.database, privileges: remaining})
+		}
+	}
+	return difference
+}
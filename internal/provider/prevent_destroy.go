@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// preventDestroyMaxRows limits the rows of a failed prevent_destroy_sql assertion shown in the diagnostic.
+const preventDestroyMaxRows = 10
+
+func preventDestroySQLAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Description: "A SELECT statement that is executed before the resource is destroyed. The destroy is refused when it returns rows, " +
+			"the rows are shown in the error",
+		MarkdownDescription: "A `SELECT` statement that is executed before the resource is destroyed. The destroy is refused when it returns rows, " +
+			"the rows are shown in the error",
+		Optional: true,
+	}
+}
+
+// checkPreventDestroySQL runs the prevent_destroy_sql assertion, an error is returned when it returns rows.
+func checkPreventDestroySQL(ctx context.Context, db dbExecutor, query types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if query.IsNull() || query.ValueString() == "" {
+		return diags
+	}
+
+	ctx, cancel := statementContext(ctx)
+	defer cancel()
+	rows, err := queryContext(ctx, db, query.ValueString())
+	if err != nil {
+		diags.AddError(
+			"Error running prevent_destroy_sql",
+			"Could not run the prevent_destroy_sql assertion, unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		diags.AddError(
+			"Error running prevent_destroy_sql",
+			"Could not run the prevent_destroy_sql assertion, unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+
+	var lines []string
+	count := 0
+	for rows.Next() {
+		count++
+		if count > preventDestroyMaxRows {
+			continue
+		}
+
+		values := make([]sql.NullString, len(columns))
+		dest := make([]any, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err = rows.Scan(dest...); err != nil {
+			diags.AddError(
+				"Error running prevent_destroy_sql",
+				"Could not read the result of the prevent_destroy_sql assertion, unexpected error: "+err.Error(),
+			)
+			return diags
+		}
+
+		fields := make([]string, len(columns))
+		for i, column := range columns {
+			value := "NULL"
+			if values[i].Valid {
+				value = values[i].String
+			}
+			fields[i] = column + "=" + value
+		}
+		lines = append(lines, strings.Join(fields, ", "))
+	}
+	if err = rows.Err(); err != nil {
+		diags.AddError(
+			"Error running prevent_destroy_sql",
+			"Could not read the result of the prevent_destroy_sql assertion, unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+
+	if count > 0 {
+		if count > preventDestroyMaxRows {
+			lines = append(lines, fmt.Sprintf("... and %d more rows", count-preventDestroyMaxRows))
+		}
+		diags.AddError(
+			"Destroy prevented by prevent_destroy_sql",
+			fmt.Sprintf("The prevent_destroy_sql assertion returned %d rows, the resource is not destroyed:\n\n%s", count, strings.Join(lines, "\n")),
+		)
+	}
+	return diags
+}
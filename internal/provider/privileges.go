@@ -0,0 +1,397 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// privilegeLevels are the levels a privilege can be granted on, as a bit mask.
+type privilegeLevels int
+
+const (
+	levelGlobal privilegeLevels = 1 << iota
+	levelDatabase
+	levelTable
+	levelColumn
+	levelRoutine
+	levelProxy
+)
+
+// privilegeDefinition describes in which MySQL versions a privilege exists and on which levels it can be granted.
+type privilegeDefinition struct {
+	levels  privilegeLevels
+	added   serverVersion // Zero means available in all supported versions
+	removed serverVersion // Zero means not removed
+}
+
+var (
+	mysql80 = serverVersion{major: 8}
+	mysql84 = serverVersion{major: 8, minor: 4}
+)
+
+func dynamicPrivilege(added serverVersion) privilegeDefinition {
+	return privilegeDefinition{levels: levelGlobal, added: added}
+}
+
+// privilegeDefinitions contains the static and dynamic privileges of MySQL 5.7, 8.0 and 8.4.
+var privilegeDefinitions = map[string]privilegeDefinition{
+	"ALL PRIVILEGES":          {levels: levelGlobal | levelDatabase | levelTable},
+	"ALTER":                   {levels: levelGlobal | levelDatabase | levelTable},
+	"ALTER ROUTINE":           {levels: levelGlobal | levelDatabase | levelRoutine},
+	"CREATE":                  {levels: levelGlobal | levelDatabase | levelTable},
+	"CREATE ROLE":             {levels: levelGlobal, added: mysql80},
+	"CREATE ROUTINE":          {levels: levelGlobal | levelDatabase},
+	"CREATE TABLESPACE":       {levels: levelGlobal},
+	"CREATE TEMPORARY TABLES": {levels: levelGlobal | levelDatabase},
+	"CREATE USER":             {levels: levelGlobal},
+	"CREATE VIEW":             {levels: levelGlobal | levelDatabase | levelTable},
+	"DELETE":                  {levels: levelGlobal | levelDatabase | levelTable},
+	"DROP":                    {levels: levelGlobal | levelDatabase | levelTable},
+	"DROP ROLE":               {levels: levelGlobal, added: mysql80},
+	"EVENT":                   {levels: levelGlobal | levelDatabase},
+	"EXECUTE":                 {levels: levelGlobal | levelDatabase | levelRoutine},
+	"FILE":                    {levels: levelGlobal},
+	"GRANT OPTION":            {levels: levelGlobal | levelDatabase | levelTable | levelRoutine | levelProxy},
+	"INDEX":                   {levels: levelGlobal | levelDatabase | levelTable},
+	"INSERT":                  {levels: levelGlobal | levelDatabase | levelTable | levelColumn},
+	"LOCK TABLES":             {levels: levelGlobal | levelDatabase},
+	"PROCESS":                 {levels: levelGlobal},
+	"PROXY":                   {levels: levelProxy},
+	"REFERENCES":              {levels: levelGlobal | levelDatabase | levelTable | levelColumn},
+	"RELOAD":                  {levels: levelGlobal},
+	"REPLICATION CLIENT":      {levels: levelGlobal},
+	"REPLICATION SLAVE":       {levels: levelGlobal},
+	"SELECT":                  {levels: levelGlobal | levelDatabase | levelTable | levelColumn},
+	"SHOW DATABASES":          {levels: levelGlobal},
+	"SHOW VIEW":               {levels: levelGlobal | levelDatabase | levelTable},
+	"SHUTDOWN":                {levels: levelGlobal},
+	"SUPER":                   {levels: levelGlobal},
+	"TRIGGER":                 {levels: levelGlobal | levelDatabase | levelTable},
+	"UPDATE":                  {levels: levelGlobal | levelDatabase | levelTable | levelColumn},
+	"USAGE":                   {levels: levelGlobal | levelDatabase | levelTable},
+
+	"ALLOW_NONEXISTENT_DEFINER":    dynamicPrivilege(serverVersion{major: 8, minor: 2}),
+	"APPLICATION_PASSWORD_ADMIN":   dynamicPrivilege(mysql80),
+	"AUDIT_ABORT_EXEMPT":           dynamicPrivilege(serverVersion{major: 8, patch: 28}),
+	"AUDIT_ADMIN":                  dynamicPrivilege(mysql80),
+	"AUTHENTICATION_POLICY_ADMIN":  dynamicPrivilege(serverVersion{major: 8, patch: 27}),
+	"BACKUP_ADMIN":                 dynamicPrivilege(mysql80),
+	"BINLOG_ADMIN":                 dynamicPrivilege(mysql80),
+	"BINLOG_ENCRYPTION_ADMIN":      dynamicPrivilege(mysql80),
+	"CLONE_ADMIN":                  dynamicPrivilege(mysql80),
+	"CONNECTION_ADMIN":             dynamicPrivilege(mysql80),
+	"ENCRYPTION_KEY_ADMIN":         dynamicPrivilege(mysql80),
+	"FIREWALL_EXEMPT":              dynamicPrivilege(mysql80),
+	"FLUSH_OPTIMIZER_COSTS":        dynamicPrivilege(mysql80),
+	"FLUSH_PRIVILEGES":             dynamicPrivilege(mysql84),
+	"FLUSH_STATUS":                 dynamicPrivilege(mysql80),
+	"FLUSH_TABLES":                 dynamicPrivilege(mysql80),
+	"FLUSH_USER_RESOURCES":         dynamicPrivilege(mysql80),
+	"GROUP_REPLICATION_ADMIN":      dynamicPrivilege(mysql80),
+	"GROUP_REPLICATION_STREAM":     dynamicPrivilege(mysql80),
+	"INNODB_REDO_LOG_ARCHIVE":      dynamicPrivilege(mysql80),
+	"INNODB_REDO_LOG_ENABLE":       dynamicPrivilege(mysql80),
+	"OPTIMIZE_LOCAL_TABLE":         dynamicPrivilege(mysql84),
+	"PASSWORDLESS_USER_ADMIN":      dynamicPrivilege(mysql80),
+	"PERSIST_RO_VARIABLES_ADMIN":   dynamicPrivilege(mysql80),
+	"REPLICATION_APPLIER":          dynamicPrivilege(mysql80),
+	"REPLICATION_SLAVE_ADMIN":      dynamicPrivilege(mysql80),
+	"RESOURCE_GROUP_ADMIN":         dynamicPrivilege(mysql80),
+	"RESOURCE_GROUP_USER":          dynamicPrivilege(mysql80),
+	"ROLE_ADMIN":                   dynamicPrivilege(mysql80),
+	"SENSITIVE_VARIABLES_OBSERVER": dynamicPrivilege(serverVersion{major: 8, patch: 29}),
+	"SERVICE_CONNECTION_ADMIN":     dynamicPrivilege(mysql80),
+	"SESSION_VARIABLES_ADMIN":      dynamicPrivilege(mysql80),
+	"SET_ANY_DEFINER":              dynamicPrivilege(serverVersion{major: 8, minor: 2}),
+	"SET_USER_ID":                  {levels: levelGlobal, added: mysql80, removed: mysql84},
+	"SHOW_ROUTINE":                 dynamicPrivilege(mysql80),
+	"SYSTEM_USER":                  dynamicPrivilege(mysql80),
+	"SYSTEM_VARIABLES_ADMIN":       dynamicPrivilege(mysql80),
+	"TABLE_ENCRYPTION_ADMIN":       dynamicPrivilege(mysql80),
+	"TELEMETRY_LOG_ADMIN":          dynamicPrivilege(mysql80),
+	"TRANSACTION_GTID_TAG":         dynamicPrivilege(serverVersion{major: 8, minor: 3}),
+	"XA_RECOVER_ADMIN":             dynamicPrivilege(mysql80),
+}
+
+// normalizePrivilege uppercases the privilege and collapses whitespace, ALL is a synonym of ALL PRIVILEGES.
+func normalizePrivilege(privilege string) string {
+	privilege = strings.ToUpper(strings.Join(strings.Fields(privilege), " "))
+	if privilege == "ALL" {
+		return "ALL PRIVILEGES"
+	}
+	return privilege
+}
+
+// privilegeNamesEqual compares privilege names, ALL is a synonym of ALL PRIVILEGES.
+func privilegeNamesEqual(a, b string) bool {
+	return normalizePrivilege(a) == normalizePrivilege(b)
+}
+
+// allPrivileges returns the privileges ALL PRIVILEGES stands for on the level in the server version, sorted by name.
+func (c *Config) allPrivileges(level privilegeLevels) []string {
+	var privileges []string
+	for name, definition := range privilegeDefinitions {
+		switch {
+		case definition.levels&level == 0:
+		case name == "ALL PRIVILEGES" || name == "GRANT OPTION" || name == "USAGE":
+		case privilegeVersionError(name, c.serverVersion) != "":
+		default:
+			privileges = append(privileges, name)
+		}
+	}
+	sort.Strings(privileges)
+	return privileges
+}
+
+// expandAllPrivileges returns the privileges read from the server in the terms of the state. SHOW GRANTS lists ALL
+// PRIVILEGES whenever the account holds every privilege of the level, also when they were granted one by one, so
+// it is expanded into those privileges. When the state manages ALL PRIVILEGES and the server holds every privilege
+// of the level, they are collapsed into the spelling of the state instead, keeping the ones the state also lists.
+func (c *Config) expandAllPrivileges(statePrivileges, serverPrivileges []string, level privilegeLevels) []string {
+	all := c.allPrivileges(level)
+	var expanded []string
+	for _, privilege := range serverPrivileges {
+		if privilegeNamesEqual(privilege, "ALL PRIVILEGES") {
+			expanded = append(expanded, all...)
+			continue
+		}
+		expanded = append(expanded, privilege)
+	}
+	expanded = uniquePrivileges(expanded)
+
+	if len(privilegesIntersection(statePrivileges, []string{"ALL PRIVILEGES"})) == 0 || len(withoutPrivileges(all, expanded)) > 0 {
+		return expanded
+	}
+	collapsed := append(withoutPrivileges(expanded, all), "ALL PRIVILEGES")
+	return append(collapsed, privilegesIntersection(all, statePrivileges)...)
+}
+
+// reconcilePrivileges returns the privileges found on the server, keeping the spelling of the privileges in state
+// so equivalent privileges don't show up as a difference.
+func reconcilePrivileges(statePrivileges []types.String, serverPrivileges []string) []types.String {
+	var privileges []types.String
+	for _, serverPrivilege := range serverPrivileges {
+		found := false
+		for _, statePrivilege := range statePrivileges {
+			if privilegeNamesEqual(statePrivilege.ValueString(), serverPrivilege) {
+				privileges = append(privileges, statePrivilege)
+				found = true
+				break
+			}
+		}
+		if !found {
+			privileges = append(privileges, types.StringValue(serverPrivilege))
+		}
+	}
+	return privileges
+}
+
+// managedPrivileges returns the privileges of the state that are still granted on the server, the privileges
+// granted outside of Terraform are left out.
+func managedPrivileges(statePrivileges []types.String, serverPrivileges []string) []types.String {
+	privileges := []types.String{}
+	for _, statePrivilege := range statePrivileges {
+		for _, serverPrivilege := range serverPrivileges {
+			if privilegeNamesEqual(statePrivilege.ValueString(), serverPrivilege) {
+				privileges = append(privileges, statePrivilege)
+				break
+			}
+		}
+	}
+	return privileges
+}
+
+// privilegesIntersection returns the privileges of a that are also in b.
+func privilegesIntersection(a, b []string) []string {
+	var intersection []string
+	for _, privilegeA := range a {
+		for _, privilegeB := range b {
+			if privilegeNamesEqual(privilegeA, privilegeB) {
+				intersection = append(intersection, privilegeA)
+				break
+			}
+		}
+	}
+	return intersection
+}
+
+// withoutPrivileges returns the privileges that are not in removed.
+func withoutPrivileges(privileges, removed []string) []string {
+	var remaining []string
+	for _, privilege := range privileges {
+		if len(privilegesIntersection([]string{privilege}, removed)) == 0 {
+			remaining = append(remaining, privilege)
+		}
+	}
+	return remaining
+}
+
+// uniquePrivileges returns the privileges without duplicates, the first spelling of a privilege is kept.
+func uniquePrivileges(privileges []string) []string {
+	var unique []string
+	for _, privilege := range privileges {
+		if len(privilegesIntersection([]string{privilege}, unique)) == 0 {
+			unique = append(unique, privilege)
+		}
+	}
+	return unique
+}
+
+// cloudSQLPrivilegesDocumentation describes the privileges that are available to the accounts of a Cloud SQL instance.
+const cloudSQLPrivilegesDocumentation = "https://cloud.google.com/sql/docs/mysql/users#cloudsqlsuperuser"
+
+// cloudSQLRestrictedPrivileges are not available to any account on Cloud SQL, not even to the members of
+// cloudsqlsuperuser, so granting them always fails.
+var cloudSQLRestrictedPrivileges = []string{"FILE", "SHUTDOWN", "SUPER"}
+
+// cloudSQLRestrictionError returns an error message when Cloud SQL doesn't allow granting the privilege.
+func cloudSQLRestrictionError(privilege string) string {
+	for _, restricted := range cloudSQLRestrictedPrivileges {
+		if privilegeNamesEqual(privilege, restricted) {
+			return fmt.Sprintf("%q is not available on Cloud SQL, no account has it so it can't be granted. "+
+				"See %s for the privileges that can be granted", privilege, cloudSQLPrivilegesDocumentation)
+		}
+	}
+	return ""
+}
+
+// privilegeLevelError returns an error message when the privilege is unknown or can't be granted on the level.
+func privilegeLevelError(privilege string, level privilegeLevels, levelName string) string {
+	definition, ok := privilegeDefinitions[normalizePrivilege(privilege)]
+	if !ok {
+		return fmt.Sprintf("%q is not a known MySQL privilege", privilege)
+	}
+	if definition.levels&level == 0 {
+		return fmt.Sprintf("%q can't be granted on %s level", privilege, levelName)
+	}
+	return ""
+}
+
+// knownPrivilege checks if the privilege is one of privilegeDefinitions.
+func knownPrivilege(privilege string) bool {
+	_, ok := privilegeDefinitions[normalizePrivilege(privilege)]
+	return ok
+}
+
+// unknownPrivilegeError returns an error message when the privilege is neither a known MySQL privilege nor one of
+// additional_allowed_privileges. The validators can't read the provider configuration, so they leave unknown
+// privileges to this check when planning.
+func (c *Config) unknownPrivilegeError(privilege string) string {
+	if knownPrivilege(privilege) {
+		return ""
+	}
+	for _, allowed := range c.additionalAllowedPrivileges {
+		if privilegeNamesEqual(privilege, allowed) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("%q is not a known MySQL privilege. Add it to `additional_allowed_privileges` in the provider "+
+		"configuration when the instance has it", privilege)
+}
+
+// privilegeVersionError returns an error message when the privilege is not available in the server version.
+func privilegeVersionError(privilege string, version serverVersion) string {
+	definition, ok := privilegeDefinitions[normalizePrivilege(privilege)]
+	if !ok {
+		return ""
+	}
+	if !definition.added.isZero() && version.less(definition.added) {
+		return fmt.Sprintf("%q requires MySQL %s or later, the server runs MySQL %s", privilege, definition.added, version)
+	}
+	if !definition.removed.isZero() && !version.less(definition.removed) {
+		return fmt.Sprintf("%q was removed in MySQL %s, the server runs MySQL %s", privilege, definition.removed, version)
+	}
+	return ""
+}
+
+var _ validator.Set = privilegesValidator{}
+
+// privilegesValidator validates that the known privileges in the set can be granted on the level. The checks of
+// unknown privileges and of the server version are done when planning, once the provider is configured.
+type privilegesValidator struct {
+	level     privilegeLevels
+	levelName string
+	// grantOptionAttribute is the attribute GRANT OPTION is set with instead, empty when the resource can't grant it.
+	grantOptionAttribute string
+}
+
+func (v privilegesValidator) Description(_ context.Context) string {
+	return "privileges must be valid MySQL privileges that can be granted on " + v.levelName + " level"
+}
+
+func (v privilegesValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v privilegesValidator) ValidateSet(ctx context.Context, req validator.SetRequest, resp *validator.SetResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	var privileges []string
+	resp.Diagnostics.Append(req.ConfigValue.ElementsAs(ctx, &privileges, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The set only removes exact duplicates, spellings of the same privilege would be granted twice
+	seen := make(map[string]string)
+	for _, privilege := range privileges {
+		if other, ok := seen[normalizePrivilege(privilege)]; ok {
+			resp.Diagnostics.AddAttributeError(req.Path, "Duplicate privilege",
+				fmt.Sprintf("%q and %q are the same privilege, list it once", other, privilege))
+			continue
+		}
+		seen[normalizePrivilege(privilege)] = privilege
+
+		if normalizePrivilege(privilege) == "GRANT OPTION" {
+			resp.Diagnostics.AddAttributeError(req.Path, "GRANT OPTION is not a privilege", v.grantOptionMessage())
+			continue
+		}
+		if message := cloudSQLRestrictionError(privilege); message != "" {
+			resp.Diagnostics.AddAttributeError(req.Path, "Privilege not available on Cloud SQL", message)
+			continue
+		}
+		if !knownPrivilege(privilege) {
+			// Checked against additional_allowed_privileges by unknownPrivilegeError
+			continue
+		}
+		if message := privilegeLevelError(privilege, v.level, v.levelName); message != "" {
+			resp.Diagnostics.AddAttributeError(req.Path, "Invalid privilege", message)
+		}
+	}
+}
+
+// grantOptionMessage explains why GRANT OPTION can't be listed. MySQL stores it as a flag of the grant, it is never
+// read back as one of the privileges, so listing it would show up as a change on every plan.
+func (v privilegesValidator) grantOptionMessage() string {
+	message := "MySQL stores GRANT OPTION as a flag of the grant instead of as one of its privileges, it is never read back " +
+		"from the server as a privilege. "
+	if v.grantOptionAttribute != "" {
+		return message + "Remove it from the privileges and set `" + v.grantOptionAttribute + " = true` instead."
+	}
+	return message + "Remove it from the privileges, use `cloudsqlmysql_grant_database` with `with_grant_option = true` to grant it."
+}
+
+// privilegePresets are the privilege lists the preset attribute of cloudsqlmysql_grant_database expands to. The reader
+// and writer presets are the same tiers as the ones of cloudsqlmysql_schema_baseline.
+var privilegePresets = map[string][]string{
+	"reader": defaultReaderPrivileges,
+	"writer": defaultWriterPrivileges,
+	"ddl_admin": {"CREATE", "ALTER", "DROP", "INDEX", "REFERENCES", "CREATE VIEW", "SHOW VIEW", "CREATE ROUTINE",
+		"ALTER ROUTINE", "EVENT", "TRIGGER"},
+}
+
+func privilegePresetNames() []string {
+	names := make([]string, 0, len(privilegePresets))
+	for name := range privilegePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
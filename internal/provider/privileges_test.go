@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// databasePrivileges57 are the privileges ALL PRIVILEGES stands for on a database in MySQL 5.7.
+var databasePrivileges57 = []string{
+	"ALTER", "ALTER ROUTINE", "CREATE", "CREATE ROUTINE", "CREATE TEMPORARY TABLES", "CREATE VIEW", "DELETE", "DROP",
+	"EVENT", "EXECUTE", "INDEX", "INSERT", "LOCK TABLES", "REFERENCES", "SELECT", "SHOW VIEW", "TRIGGER", "UPDATE",
+}
+
+func TestAllPrivileges(t *testing.T) {
+	config := &Config{serverVersion: serverVersion{major: 8, minor: 4}}
+	if got := config.allPrivileges(levelDatabase); !reflect.DeepEqual(got, databasePrivileges57) {
+		t.Errorf("allPrivileges(levelDatabase) = %v, want %v", got, databasePrivileges57)
+	}
+	if got, want := config.allPrivileges(levelRoutine), []string{"ALTER ROUTINE", "EXECUTE"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("allPrivileges(levelRoutine) = %v, want %v", got, want)
+	}
+}
+
+func TestExpandAllPrivileges(t *testing.T) {
+	config := &Config{serverVersion: serverVersion{major: 8}}
+	tests := []struct {
+		name   string
+		state  []string
+		server []string
+		want   []string
+	}{
+		{
+			name:   "without all privileges",
+			state:  []string{"SELECT"},
+			server: []string{"SELECT", "INSERT"},
+			want:   []string{"SELECT", "INSERT"},
+		},
+		{
+			name:   "expanded for single privileges",
+			state:  []string{"SELECT", "INSERT"},
+			server: []string{"ALL PRIVILEGES"},
+			want:   databasePrivileges57,
+		},
+		{
+			name:   "collapsed for all in state",
+			state:  []string{"ALL"},
+			server: []string{"ALL PRIVILEGES"},
+			want:   []string{"ALL PRIVILEGES"},
+		},
+		{
+			name:   "collapsed keeping the privileges of the state",
+			state:  []string{"ALL PRIVILEGES", "select"},
+			server: []string{"ALL PRIVILEGES"},
+			want:   []string{"ALL PRIVILEGES", "SELECT"},
+		},
+		{
+			name:   "not collapsed when a privilege is missing",
+			state:  []string{"ALL PRIVILEGES"},
+			server: withoutPrivileges(databasePrivileges57, []string{"DROP"}),
+			want:   withoutPrivileges(databasePrivileges57, []string{"DROP"}),
+		},
+		{
+			name:   "collapsed when every privilege is listed",
+			state:  []string{"ALL PRIVILEGES"},
+			server: databasePrivileges57,
+			want:   []string{"ALL PRIVILEGES"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := config.expandAllPrivileges(test.state, test.server, levelDatabase)
+			sort.Strings(got)
+			want := append([]string{}, test.want...)
+			sort.Strings(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("expandAllPrivileges(%v, %v) = %v, want %v", test.state, test.server, got, want)
+			}
+		})
+	}
+}
+
+func TestReconcileExpandedPrivileges(t *testing.T) {
+	config := &Config{serverVersion: serverVersion{major: 8}}
+	state := []types.String{types.StringValue("select"), types.StringValue("INSERT")}
+
+	// SHOW GRANTS collapses the privileges into ALL PRIVILEGES, the managed privileges are still held
+	server := config.expandAllPrivileges(privilegesAsStrings(state), []string{"ALL PRIVILEGES"}, levelDatabase)
+	if got := managedPrivileges(state, server); !reflect.DeepEqual(got, state) {
+		t.Errorf("managedPrivileges() = %v, want %v", got, state)
+	}
+	if got := reconcilePrivileges(state, server); len(got) != len(databasePrivileges57) || got[0] != types.StringValue("ALTER") {
+		t.Errorf("reconcilePrivileges() = %v, want the database privileges", got)
+	}
+}
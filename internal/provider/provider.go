@@ -3,18 +3,28 @@ package provider
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"net"
 	"net/url"
 	"os"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"terraform-provider-cloudsqlmysql/internal/sqlgen"
 
 	"cloud.google.com/go/cloudsqlconn"
-	"cloud.google.com/go/cloudsqlconn/mysql/mysql"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/metaschema"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
@@ -24,20 +34,96 @@ import (
 )
 
 var (
-	_ provider.Provider = &CloudSqlMysqlProvider{}
+	_ provider.Provider               = &CloudSqlMysqlProvider{}
+	_ provider.ProviderWithFunctions  = &CloudSqlMysqlProvider{}
+	_ provider.ProviderWithMetaSchema = &CloudSqlMysqlProvider{}
 )
 
 type CloudSqlMysqlProvider struct {
 	version string
+	// connectionFactory replaces the Cloud SQL connector when set with WithConnectionFactory
+	connectionFactory ConnectionFactory
+	// protocol5 leaves out the resources and data sources protocol version 5 can't serve, set by NewProtocol5
+	protocol5 bool
+
+	// Set by Configure, used by the whoami function
+	username       string
+	connectionName string
 }
 
 type CloudSqlMysqlProviderModel struct {
 	ConnectionName types.String `tfsdk:"connection_name"`
-	Username       types.String `tfsdk:"username"`
-	Password       types.String `tfsdk:"password"`
-	Proxy          types.String `tfsdk:"proxy"`
-	PrivateIP      types.Bool   `tfsdk:"private_ip"`
-	PSC            types.Bool   `tfsdk:"psc"`
+	// FallbackConnectionNames are tried in order when the instance of ConnectionName can't be reached.
+	FallbackConnectionNames []types.String `tfsdk:"fallback_connection_names"`
+	Username                types.String   `tfsdk:"username"`
+	Password                types.String   `tfsdk:"password"`
+	// DisableEnvFallback ignores the CLOUDSQL_MYSQL_* environment variables, only the configuration is used.
+	DisableEnvFallback types.Bool `tfsdk:"disable_env_fallback"`
+	// PasswordVersion is bumped when the password is rotated, it is only sent as the password_version connection attribute.
+	PasswordVersion types.Int64  `tfsdk:"password_version"`
+	Proxy           types.String `tfsdk:"proxy"`
+	// Address is dialed with the stock MySQL driver instead of the Cloud SQL connector, the TLS attributes apply to it.
+	Address              types.String `tfsdk:"address"`
+	TLSClientCertificate types.String `tfsdk:"tls_client_certificate"`
+	TLSClientKey         types.String `tfsdk:"tls_client_key"`
+	TLSCACertificate     types.String `tfsdk:"tls_ca_certificate"`
+	TLSServerName        types.String `tfsdk:"tls_server_name"`
+	// ProxyFallbackDirect dials the instance directly when the proxy can't be reached.
+	ProxyFallbackDirect types.Bool `tfsdk:"proxy_fallback_direct"`
+	PrivateIP           types.Bool `tfsdk:"private_ip"`
+	PSC                 types.Bool `tfsdk:"psc"`
+	// LazyRefresh refreshes the certificate of the Cloud SQL connector when a connection is dialed instead of in the
+	// background.
+	LazyRefresh types.Bool `tfsdk:"lazy_refresh"`
+	// RefreshJitter is the maximum random wait in seconds before the first certificate refresh.
+	RefreshJitter types.Int64 `tfsdk:"refresh_jitter"`
+	// RefreshRetries is the number of retries of the Cloud SQL Admin API requests rejected by the quota.
+	RefreshRetries types.Int64 `tfsdk:"refresh_retries"`
+	// RequireTLS refuses connections that aren't verified TLS connections and disables cleartext authentication.
+	RequireTLS types.Bool `tfsdk:"require_tls"`
+	// SessionVariables are applied with SET on every new connection before statements are executed.
+	SessionVariables types.Map    `tfsdk:"session_variables"`
+	WorkspaceName    types.String `tfsdk:"workspace_name"`
+	// AdditionalAllowedPrivileges are accepted by the privilege validation on top of the known MySQL privileges.
+	AdditionalAllowedPrivileges types.Set `tfsdk:"additional_allowed_privileges"`
+	// AdvisoryLockName is the name of the advisory lock, provider aliases on the same instance need different names.
+	AdvisoryLockName types.String `tfsdk:"advisory_lock_name"`
+	// AdvisoryLockTimeout enables the advisory lock that serializes the writes of concurrent runs, in seconds.
+	AdvisoryLockTimeout types.Int64 `tfsdk:"advisory_lock_timeout"`
+	// AllowAnonymousAccounts disables the guardrails that refuse grants to and roles with an empty user name.
+	AllowAnonymousAccounts types.Bool `tfsdk:"allow_anonymous_accounts"`
+	// AllowSystemSchemas disables the guardrails that refuse changes to the MySQL system schemas.
+	AllowSystemSchemas types.Bool `tfsdk:"allow_system_schemas"`
+	// AllowIndexDDL enables cloudsqlmysql_index, which runs DDL on the tables of the applications.
+	AllowIndexDDL types.Bool `tfsdk:"allow_index_ddl"`
+	// LogSQL logs the statements at INFO level, passwords and parameter values are left out.
+	LogSQL types.Bool `tfsdk:"log_sql"`
+	// AuditRuleRetries is the number of retries when an audit stored procedure reports a busy error.
+	AuditRuleRetries types.Int64 `tfsdk:"audit_rule_retries"`
+	// AuditRuleLimit is the maximum number of audit rules the new rules are checked against.
+	AuditRuleLimit types.Int64 `tfsdk:"audit_rule_limit"`
+	// VerifyAfterApply reads the grants back after every apply and fails when they don't match.
+	VerifyAfterApply types.Bool `tfsdk:"verify_after_apply"`
+	// SurfaceSQLWarnings reports the warnings of the executed statements as warning diagnostics.
+	SurfaceSQLWarnings types.Bool `tfsdk:"surface_sql_warnings"`
+	// ReadPageSize is the number of mysql.db rows read per query with read_source mysql_tables.
+	ReadPageSize types.Int64 `tfsdk:"read_page_size"`
+	// ReadSource decides where the grants of the accounts are read from.
+	ReadSource types.String `tfsdk:"read_source"`
+	// CaseSensitivity decides how the case of account names is canonicalized.
+	CaseSensitivity types.String `tfsdk:"case_sensitivity"`
+	// Database is the default database of the connections that don't connect to a specific database.
+	Database types.String `tfsdk:"database"`
+	// ConnectTimeout limits connecting to the instance and the first queries, in seconds.
+	ConnectTimeout types.Int64 `tfsdk:"connect_timeout"`
+	// ReadTimeout limits every statement of reads, in seconds.
+	ReadTimeout types.Int64 `tfsdk:"read_timeout"`
+	// WriteTimeout limits every statement of creates, updates and deletes, in seconds.
+	WriteTimeout types.Int64 `tfsdk:"write_timeout"`
+	// TimeZone is set as the time_zone session variable on every connection.
+	TimeZone types.String `tfsdk:"time_zone"`
+	// MaxExecutionTime is set as the max_execution_time session variable on every connection.
+	MaxExecutionTime types.Int64 `tfsdk:"max_execution_time"`
 	// IAMAuthentication types.Bool   `tfsdk:"iam_authentication"` # Not supporting IAM authentication for now.
 }
 
@@ -46,6 +132,20 @@ func (p *CloudSqlMysqlProvider) Metadata(ctx context.Context, req provider.Metad
 	resp.Version = p.version
 }
 
+// MetaSchema is the schema of the provider_meta block modules can set, the module name is added to the log lines of
+// the resources and data sources of the module.
+func (p *CloudSqlMysqlProvider) MetaSchema(_ context.Context, _ provider.MetaSchemaRequest, resp *provider.MetaSchemaResponse) {
+	resp.Schema = metaschema.Schema{
+		Attributes: map[string]metaschema.Attribute{
+			"module_name": metaschema.StringAttribute{
+				Description:         "The name of the module, added as module_name to the log lines of the provider including the statements logged by log_sql",
+				MarkdownDescription: "The name of the module, added as `module_name` to the log lines of the provider including the statements logged by `log_sql`",
+				Optional:            true,
+			},
+		},
+	}
+}
+
 func (p *CloudSqlMysqlProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description:         "The cloudsqlmysql provider makes it possible to grant permissions on MySQL databases and add rules for MySQL Audit Plugin. More info: https://cloud.google.com/sql/docs/mysql/db-audit",
@@ -60,16 +160,55 @@ func (p *CloudSqlMysqlProvider) Schema(_ context.Context, _ provider.SchemaReque
 						"`connection_name` must have the format of `<project>:<region>:<instance>`"),
 				},
 			},
+			"fallback_connection_names": schema.ListAttribute{
+				Description: "The connection names of the instances that are tried in order when the instance of connection_name can't be " +
+					"reached within connect_timeout, e.g. cross-region replicas that are promoted during a failover. " +
+					"The instance that is connected to is logged",
+				MarkdownDescription: "The connection names of the instances that are tried in order when the instance of `connection_name` can't be " +
+					"reached within `connect_timeout`, e.g. cross-region replicas that are promoted during a failover. " +
+					"The instance that is connected to is logged",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(stringvalidator.RegexMatches(regexp.MustCompile(`^[a-z0-9\-]+\:[a-z0-9\-]+\:[a-z0-9\-]+$`),
+						"`fallback_connection_names` must have the format of `<project>:<region>:<instance>`")),
+				},
+			},
+			"disable_env_fallback": schema.BoolAttribute{
+				Description: "Ignore the CLOUDSQL_MYSQL_CONNECTION_NAME, CLOUDSQL_MYSQL_USERNAME and CLOUDSQL_MYSQL_PASSWORD environment variables, " +
+					"so the connection name and the credentials are only read from the configuration. Default: false",
+				MarkdownDescription: "Ignore the `CLOUDSQL_MYSQL_CONNECTION_NAME`, `CLOUDSQL_MYSQL_USERNAME` and `CLOUDSQL_MYSQL_PASSWORD` environment variables, " +
+					"so the connection name and the credentials are only read from the configuration. Default: `false`",
+				Optional: true,
+			},
 			"username": schema.StringAttribute{
 				Description:         "The username to use to authenticate with the Cloud SQL MySQL instance",
 				MarkdownDescription: "The username to use to authenticate with the Cloud SQL MySQL instance",
 				Optional:            true,
 			},
+			"log_sql": schema.BoolAttribute{
+				Description: "Log the SQL statements that change the instance and the grant and database lookups at INFO level, with their duration and the number of affected rows, " +
+					"without the values of parameters and password literals. When the provider server stops a summary is logged with the number of statements, " +
+					"retries, the total SQL time and the connections opened, also without log_sql when the CLOUDSQL_MYSQL_SQL_SUMMARY environment variable is true. Default: false",
+				MarkdownDescription: "Log the SQL statements that change the instance and the grant and database lookups at `INFO` level, with their duration and the number of affected rows, " +
+					"without the values of parameters and password literals. When the provider server stops a summary is logged with the number of statements, " +
+					"retries, the total SQL time and the connections opened, also without `log_sql` when the `CLOUDSQL_MYSQL_SQL_SUMMARY` environment variable is `true`. Default: `false`",
+				Optional: true,
+			},
 			"password": schema.StringAttribute{
-				Description:         "The password to use to authenticate using the built-in database authentication",
-				MarkdownDescription: "The password to use to authenticate using the built-in database authentication",
-				Optional:            true,
-				Sensitive:           true,
+				Description: "The password to use to authenticate using the built-in database authentication. A reference to a Secret Manager " +
+					"secret version, sm://projects/<project>/secrets/<secret>/versions/<version>, is resolved with the application default credentials",
+				MarkdownDescription: "The password to use to authenticate using the built-in database authentication. A reference to a Secret Manager " +
+					"secret version, `sm://projects/<project>/secrets/<secret>/versions/<version>`, is resolved with the application default credentials",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"password_version": schema.Int64Attribute{
+				Description: "Version of the password, bump it when the password is rotated to force new connections that authenticate with the new password. " +
+					"The version is sent as the `password_version` connection attribute",
+				MarkdownDescription: "Version of the password, bump it when the password is rotated to force new connections that authenticate with the new password. " +
+					"The version is sent as the `password_version` connection attribute",
+				Optional: true,
 			},
 			"proxy": schema.StringAttribute{
 				Description:         "Proxy socks url if used. Format needs to be `socks5://<ip>:<port>`",
@@ -80,6 +219,60 @@ func (p *CloudSqlMysqlProvider) Schema(_ context.Context, _ provider.SchemaReque
 						"`proxy` must have the format of `socks5://<ip>:<port>`"),
 				},
 			},
+			"proxy_fallback_direct": schema.BoolAttribute{
+				Description: "Connect directly to the instance when the dials through the proxy still fail after " + strconv.Itoa(proxyDialRetries) + " retries. " +
+					"The path that connected is logged. Default: false",
+				MarkdownDescription: "Connect directly to the instance when the dials through the `proxy` still fail after " + strconv.Itoa(proxyDialRetries) + " retries. " +
+					"The path that connected is logged. Default: `false`",
+				Optional: true,
+			},
+			"address": schema.StringAttribute{
+				Description: "Connect to this host:port with the MySQL driver instead of the Cloud SQL connector, for setups outside of the connector " +
+					"like a Cloud SQL Auth Proxy v1 listener or the instance IP with client certificates. proxy, private_ip and psc don't apply to it",
+				MarkdownDescription: "Connect to this `host:port` with the MySQL driver instead of the Cloud SQL connector, for setups outside of the connector " +
+					"like a Cloud SQL Auth Proxy v1 listener or the instance IP with client certificates. `proxy`, `private_ip` and `psc` don't apply to it",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^.+:\d+$`), "`address` must have the format of `<host>:<port>`"),
+				},
+			},
+			"tls_client_certificate": schema.StringAttribute{
+				Description:         "The PEM encoded client certificate that is presented to the server when connecting to address, requires tls_client_key",
+				MarkdownDescription: "The PEM encoded client certificate that is presented to the server when connecting to `address`, requires `tls_client_key`",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("address"), path.MatchRoot("tls_client_key")),
+				},
+			},
+			"tls_client_key": schema.StringAttribute{
+				Description:         "The PEM encoded private key of tls_client_certificate",
+				MarkdownDescription: "The PEM encoded private key of `tls_client_certificate`",
+				Optional:            true,
+				Sensitive:           true,
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("address"), path.MatchRoot("tls_client_certificate")),
+				},
+			},
+			"tls_ca_certificate": schema.StringAttribute{
+				Description: "The PEM encoded CA certificate the server certificate of address is verified against. " +
+					"Without it the connection to address only uses TLS when the client certificate is set, without verifying the server, which require_tls refuses",
+				MarkdownDescription: "The PEM encoded CA certificate the server certificate of `address` is verified against. " +
+					"Without it the connection to `address` only uses TLS when the client certificate is set, without verifying the server, which `require_tls` refuses",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("address")),
+				},
+			},
+			"tls_server_name": schema.StringAttribute{
+				Description: "The name the server certificate of address is issued to. Cloud SQL server certificates are issued to " +
+					"<project>:<instance>. Default: the host of address",
+				MarkdownDescription: "The name the server certificate of `address` is issued to. Cloud SQL server certificates are issued to " +
+					"`<project>:<instance>`. Default: the host of `address`",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("tls_ca_certificate")),
+				},
+			},
 			// "iam_authentication": schema.BoolAttribute{
 			// 	MarkdownDescription: "Enables the use of IAM authentication. The `password` field needs to be used to fill in the access token",
 			// 	Optional:            true,
@@ -94,6 +287,265 @@ func (p *CloudSqlMysqlProvider) Schema(_ context.Context, _ provider.SchemaReque
 				MarkdownDescription: "Use the Private Service Connect endpoint of the Cloud SQL MySQL instance to connect to",
 				Optional:            true,
 			},
+			"require_tls": schema.BoolAttribute{
+				Description: "Refuse connections that aren't TLS connections with a verified server certificate, also for custom dialers like `proxy` and for address, " +
+					"which then requires tls_ca_certificate, and disable the cleartext authentication plugin. The negotiated TLS version and cipher suite are logged at debug level. Default: false",
+				MarkdownDescription: "Refuse connections that aren't TLS connections with a verified server certificate, also for custom dialers like `proxy` and for `address`, " +
+					"which then requires `tls_ca_certificate`, and disable the cleartext authentication plugin. The negotiated TLS version and cipher suite are logged at debug level. Default: `false`",
+				Optional: true,
+			},
+			"session_variables": schema.MapAttribute{
+				Description: "Session variables that are set on every connection before statements are executed, e.g. `foreign_key_checks = \"0\"`. " +
+					"Values are used as-is in the `SET` statement, so string values need to be quoted like `time_zone = \"'UTC'\"`",
+				MarkdownDescription: "Session variables that are set on every connection before statements are executed, e.g. `foreign_key_checks = \"0\"`. " +
+					"Values are used as-is in the `SET` statement, so string values need to be quoted like `time_zone = \"'UTC'\"`",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(
+						stringvalidator.RegexMatches(regexp.MustCompile(`^[a-z_][a-z0-9_]*$`),
+							"session variable names must be lowercase and only contain letters, digits and underscores"),
+						stringvalidator.NoneOf(dsnReservedParams...),
+					),
+				},
+			},
+			"max_execution_time": schema.Int64Attribute{
+				Description: "The maximum time in milliseconds of the SELECT queries of the provider, set as the max_execution_time session variable. " +
+					"Refreshes on a busy instance fail fast instead of queueing behind locks, statements that change the instance are not limited. " +
+					"Default: the server setting",
+				MarkdownDescription: "The maximum time in milliseconds of the `SELECT` queries of the provider, set as the `max_execution_time` session variable. " +
+					"Refreshes on a busy instance fail fast instead of queueing behind locks, statements that change the instance are not limited. " +
+					"Default: the server setting",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"time_zone": schema.StringAttribute{
+				Description: "The time zone of the sessions of the provider, e.g. UTC or +00:00, so timestamps in statements and queries like " +
+					"prevent_destroy_sql don't depend on the server default. Named time zones need the time zone tables of the instance",
+				MarkdownDescription: "The time zone of the sessions of the provider, e.g. `UTC` or `+00:00`, so timestamps in statements and queries like " +
+					"`prevent_destroy_sql` don't depend on the server default. Named time zones need the time zone tables of the instance",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^(SYSTEM|[+-]\d{1,2}:\d{2}|[A-Za-z][A-Za-z0-9_+\-]*(/[A-Za-z0-9_+\-]+)*)$`),
+						"`time_zone` must be SYSTEM, an offset like +01:00 or a named time zone like Europe/Amsterdam"),
+				},
+			},
+			"advisory_lock_name": schema.StringAttribute{
+				Description: "The name of the advisory lock taken when advisory_lock_timeout is set. Provider aliases that write to the same " +
+					"instance in one run wait for each other's lock until advisory_lock_timeout when they use the same name, give every alias " +
+					"a name of its own. Default: " + defaultAdvisoryLockName,
+				MarkdownDescription: "The name of the advisory lock taken when `advisory_lock_timeout` is set. Provider aliases that write to the same " +
+					"instance in one run wait for each other's lock until `advisory_lock_timeout` when they use the same name, give every alias " +
+					"a name of its own. Default: `" + defaultAdvisoryLockName + "`",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, 64),
+					stringvalidator.AlsoRequires(path.MatchRoot("advisory_lock_timeout")),
+				},
+			},
+			"advisory_lock_timeout": schema.Int64Attribute{
+				Description: "When set, the provider takes the advisory lock advisory_lock_name with GET_LOCK before its first write and " +
+					"holds it until the run ends, so concurrent Terraform runs against the same instance don't interleave their grants. " +
+					"The time in seconds to wait for the lock held by another run",
+				MarkdownDescription: "When set, the provider takes the advisory lock `advisory_lock_name` with `GET_LOCK` before its first write and " +
+					"holds it until the run ends, so concurrent Terraform runs against the same instance don't interleave their grants. " +
+					"The time in seconds to wait for the lock held by another run",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"additional_allowed_privileges": schema.SetAttribute{
+				Description: "Privileges that are accepted on top of the MySQL privileges known to the provider, e.g. dynamic privileges of " +
+					"a Cloud SQL edition. They are accepted on every level and server version, MySQL rejects them when they don't apply. " +
+					"Other unknown privileges still fail the plan, so typos are caught",
+				MarkdownDescription: "Privileges that are accepted on top of the MySQL privileges known to the provider, e.g. dynamic privileges of " +
+					"a Cloud SQL edition. They are accepted on every level and server version, MySQL rejects them when they don't apply. " +
+					"Other unknown privileges still fail the plan, so typos are caught",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"allow_anonymous_accounts": schema.BoolAttribute{
+				Description: "Allow grants to and roles with an empty user name, e.g. ''@'localhost'. These are anonymous accounts " +
+					"that match every user connecting from the host. Default: false",
+				MarkdownDescription: "Allow grants to and roles with an empty user name, e.g. `''@'localhost'`. These are anonymous accounts " +
+					"that match every user connecting from the host. Default: `false`",
+				Optional: true,
+			},
+			"allow_index_ddl": schema.BoolAttribute{
+				Description: "Allow cloudsqlmysql_index to create and drop indexes. Creating an index on a large table can take long " +
+					"and slow down the instance, so the resource has to be enabled explicitly. Default: false",
+				MarkdownDescription: "Allow `cloudsqlmysql_index` to create and drop indexes. Creating an index on a large table can take long " +
+					"and slow down the instance, so the resource has to be enabled explicitly. Default: `false`",
+				Optional: true,
+			},
+			"allow_system_schemas": schema.BoolAttribute{
+				Description:         "Allow grants and other changes on the MySQL system schemas: " + strings.Join(systemSchemas, ", ") + ". Default: false",
+				MarkdownDescription: "Allow grants and other changes on the MySQL system schemas: `" + strings.Join(systemSchemas, "`, `") + "`. Default: `false`",
+				Optional:            true,
+			},
+			"audit_rule_limit": schema.Int64Attribute{
+				Description: "The maximum number of audit rules the audit plugin of the instance accepts. When set, the number of rules on the " +
+					"instance is checked when planning new audit rules: a warning is shown from " + strconv.Itoa(auditRuleLimitWarningPercentage) +
+					"% of the limit and the plan fails when the new rules exceed it",
+				MarkdownDescription: "The maximum number of audit rules the audit plugin of the instance accepts. When set, the number of rules on the " +
+					"instance is checked when planning new audit rules: a warning is shown from " + strconv.Itoa(auditRuleLimitWarningPercentage) +
+					"% of the limit and the plan fails when the new rules exceed it",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"audit_rule_retries": schema.Int64Attribute{
+				Description: "The number of times a call to the audit rule stored procedures is retried with exponential backoff " +
+					"when the audit plugin reports that its tables are locked or busy. Default: " + strconv.Itoa(defaultAuditRuleRetries),
+				MarkdownDescription: "The number of times a call to the audit rule stored procedures is retried with exponential backoff " +
+					"when the audit plugin reports that its tables are locked or busy. Default: `" + strconv.Itoa(defaultAuditRuleRetries) + "`",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.Between(0, 10),
+				},
+			},
+			"case_sensitivity": schema.StringAttribute{
+				Description: "How the case of account names is canonicalized by all resources and data sources before they are used in statements " +
+					"and compared: mysql lowercases host names and keeps user names like MySQL compares them, sensitive uses both as configured and " +
+					"insensitive lowercases both. Default: mysql",
+				MarkdownDescription: "How the case of account names is canonicalized by all resources and data sources before they are used in statements " +
+					"and compared: `mysql` lowercases host names and keeps user names like MySQL compares them, `sensitive` uses both as configured and " +
+					"`insensitive` lowercases both. Default: `mysql`",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(caseSensitivities...),
+				},
+			},
+			"database": schema.StringAttribute{
+				Description: "The default database of the connections, unqualified names in statements refer to it. The resources and data sources " +
+					"qualify the names they use, so it only matters to the statements of the server, e.g. the ones a trigger or procedure runs. " +
+					"When not set the connections have no default database",
+				MarkdownDescription: "The default database of the connections, unqualified names in statements refer to it. The resources and data sources " +
+					"qualify the names they use, so it only matters to the statements of the server, e.g. the ones a trigger or procedure runs. " +
+					"When not set the connections have no default database",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, maxDatabaseNameLength),
+				},
+			},
+			"connect_timeout": schema.Int64Attribute{
+				Description: "The time in seconds to wait for a connection to the instance and for the first query on it, so broken networking " +
+					"fails within a predictable time. Default: " + strconv.Itoa(int(defaultConnectTimeout.Seconds())),
+				MarkdownDescription: "The time in seconds to wait for a connection to the instance and for the first query on it, so broken networking " +
+					"fails within a predictable time. Default: `" + strconv.Itoa(int(defaultConnectTimeout.Seconds())) + "`",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"lazy_refresh": schema.BoolAttribute{
+				Description: "Refresh the connection information and the ephemeral certificate of the Cloud SQL connector only when a " +
+					"connection is dialed and the certificate expired, instead of in the background. Reduces the Cloud SQL Admin API " +
+					"requests of short runs, e.g. in Cloud Build or GitHub Actions. Default: false",
+				MarkdownDescription: "Refresh the connection information and the ephemeral certificate of the Cloud SQL connector only when a " +
+					"connection is dialed and the certificate expired, instead of in the background. Reduces the Cloud SQL Admin API " +
+					"requests of short runs, e.g. in Cloud Build or GitHub Actions. Default: `false`",
+				Optional: true,
+			},
+			"refresh_jitter": schema.Int64Attribute{
+				Description: "The maximum time in seconds to wait at random before the first certificate refresh of the Cloud SQL connector, " +
+					"so the refreshes of many provider aliases configured at the same time don't exhaust the Cloud SQL Admin API quota together",
+				MarkdownDescription: "The maximum time in seconds to wait at random before the first certificate refresh of the Cloud SQL connector, " +
+					"so the refreshes of many provider aliases configured at the same time don't exhaust the Cloud SQL Admin API quota together",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"refresh_retries": schema.Int64Attribute{
+				Description: "The number of times a Cloud SQL Admin API request of the Cloud SQL connector is retried with exponential backoff " +
+					"and jitter when the API answers that the quota is exhausted (HTTP 429) or that it is unavailable (HTTP 503). When not set " +
+					"the requests are not retried",
+				MarkdownDescription: "The number of times a Cloud SQL Admin API request of the Cloud SQL connector is retried with exponential backoff " +
+					"and jitter when the API answers that the quota is exhausted (HTTP `429`) or that it is unavailable (HTTP `503`). When not set " +
+					"the requests are not retried",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"read_page_size": schema.Int64Attribute{
+				Description: "The number of rows of mysql.db read per query with read_source mysql_tables. The database grants of an account " +
+					"are read in pages ordered by database, so accounts with grants on many databases don't load them in one result. " +
+					"Default: " + strconv.Itoa(defaultReadPageSize),
+				MarkdownDescription: "The number of rows of `mysql.db` read per query with `read_source` `mysql_tables`. The database grants of an account " +
+					"are read in pages ordered by database, so accounts with grants on many databases don't load them in one result. " +
+					"Default: `" + strconv.Itoa(defaultReadPageSize) + "`",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"read_source": schema.StringAttribute{
+				Description: "Where the grants of the accounts are read from to detect drift: show_grants uses SHOW GRANTS, information_schema " +
+					"reads INFORMATION_SCHEMA.USER_PRIVILEGES and SCHEMA_PRIVILEGES, which only show other accounts to a provider user with SELECT on the mysql schema, and mysql_tables " +
+					"reads mysql.user, mysql.db, mysql.global_grants and mysql.procs_priv. INFORMATION_SCHEMA has no routine privileges and " +
+					"no partial revokes, the routine grants are read with SHOW GRANTS, which needs the same privilege. cloudsqlmysql_grant_copy and the " +
+					"cloudsqlmysql_effective_privileges data source always use SHOW GRANTS. Default: show_grants",
+				MarkdownDescription: "Where the grants of the accounts are read from to detect drift: `show_grants` uses `SHOW GRANTS`, `information_schema` " +
+					"reads `INFORMATION_SCHEMA.USER_PRIVILEGES` and `SCHEMA_PRIVILEGES`, which only show other accounts to a provider user with `SELECT` on the `mysql` schema, and `mysql_tables` " +
+					"reads `mysql.user`, `mysql.db`, `mysql.global_grants` and `mysql.procs_priv`. `INFORMATION_SCHEMA` has no routine privileges and " +
+					"no partial revokes, the routine grants are read with `SHOW GRANTS`, which needs the same privilege. `cloudsqlmysql_grant_copy` and the " +
+					"`cloudsqlmysql_effective_privileges` data source always use `SHOW GRANTS`. Default: `show_grants`",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(readSources...),
+				},
+			},
+			"read_timeout": schema.Int64Attribute{
+				Description: "The time in seconds every statement of a refresh or data source read may take, e.g. to give slow audits " +
+					"of many grants more time than writes. Default: no timeout",
+				MarkdownDescription: "The time in seconds every statement of a refresh or data source read may take, e.g. to give slow audits " +
+					"of many grants more time than writes. Default: no timeout",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"write_timeout": schema.Int64Attribute{
+				Description: "The time in seconds every statement of a create, update or delete may take, e.g. to fail fast when GRANT " +
+					"statements wait on metadata locks during an incident. A statement that times out is killed with KILL QUERY. Default: no timeout",
+				MarkdownDescription: "The time in seconds every statement of a create, update or delete may take, e.g. to fail fast when `GRANT` " +
+					"statements wait on metadata locks during an incident. A statement that times out is killed with `KILL QUERY`. Default: no timeout",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"verify_after_apply": schema.BoolAttribute{
+				Description: "When true the grants are read back after every change of cloudsqlmysql_grant_database, cloudsqlmysql_access_map " +
+					"and the grants of cloudsqlmysql_schema_baseline, the apply fails with the differences when the privileges on the server " +
+					"don't match, e.g. when Cloud SQL leaves out privileges the provider user can't grant without an error. Default: false",
+				MarkdownDescription: "When `true` the grants are read back after every change of `cloudsqlmysql_grant_database`, `cloudsqlmysql_access_map` " +
+					"and the grants of `cloudsqlmysql_schema_baseline`, the apply fails with the differences when the privileges on the server " +
+					"don't match, e.g. when Cloud SQL leaves out privileges the provider user can't grant without an error. Default: `false`",
+				Optional: true,
+			},
+			"surface_sql_warnings": schema.BoolAttribute{
+				Description: "When true the warnings of every statement that changes the instance are read with SHOW WARNINGS and shown as " +
+					"warnings of the apply, e.g. deprecated syntax or truncated values. Default: false",
+				MarkdownDescription: "When `true` the warnings of every statement that changes the instance are read with `SHOW WARNINGS` and shown as " +
+					"warnings of the apply, e.g. deprecated syntax or truncated values. Default: `false`",
+				Optional: true,
+			},
+			"workspace_name": schema.StringAttribute{
+				Description:         "The name of the Terraform workspace, added as the `workspace` connection attribute to identify the provider sessions in the processlist",
+				MarkdownDescription: "The name of the Terraform workspace, added as the `workspace` connection attribute to identify the provider sessions in the processlist",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^[^,:]+$`),
+						"`workspace_name` can't contain commas or colons"),
+				},
+			},
 		},
 	}
 }
@@ -112,6 +564,15 @@ func (p *CloudSqlMysqlProvider) Configure(ctx context.Context, req provider.Conf
 			"The provider cannot create the Cloud SQL Mysql client as there is an unknown configuration value for the `connection_name`")
 	}
 
+	for _, fallback := range config.FallbackConnectionNames {
+		if fallback.IsUnknown() {
+			resp.Diagnostics.AddAttributeError(path.Root("fallback_connection_names"),
+				"Unknown Cloud SQL MySQL fallback connection name",
+				"The provider cannot create the Cloud SQL Mysql client as there is an unknown configuration value in the `fallback_connection_names`")
+			break
+		}
+	}
+
 	// username and password are required for now as long IAM authentication is not supported.
 	if config.Username.IsUnknown() {
 		resp.Diagnostics.AddAttributeError(path.Root("username"),
@@ -129,9 +590,27 @@ func (p *CloudSqlMysqlProvider) Configure(ctx context.Context, req provider.Conf
 		return
 	}
 
-	connectionName := os.Getenv("CLOUDSQL_MYSQL_CONNECTION_NAME")
-	username := os.Getenv("CLOUDSQL_MYSQL_USERNAME")
-	password := os.Getenv("CLOUDSQL_MYSQL_PASSWORD")
+	connectTimeout := defaultConnectTimeout
+	if !config.ConnectTimeout.IsNull() {
+		connectTimeout = time.Duration(config.ConnectTimeout.ValueInt64()) * time.Second
+	}
+
+	if p.connectionFactory != nil {
+		// The connections are brought by the embedder, the Cloud SQL connection settings are not used
+		dbConfig := newProviderConfig(&config, p.connectionFactory, connectTimeout)
+		resp.ResourceData = dbConfig
+		resp.DataSourceData = dbConfig
+		return
+	}
+
+	var connectionName, username, password string
+	if !config.DisableEnvFallback.ValueBool() {
+		connectionName = os.Getenv("CLOUDSQL_MYSQL_CONNECTION_NAME")
+		username = os.Getenv("CLOUDSQL_MYSQL_USERNAME")
+		password = os.Getenv("CLOUDSQL_MYSQL_PASSWORD")
+	} else {
+		tflog.Debug(ctx, "`disable_env_fallback` is set, the environment variables are ignored")
+	}
 
 	if !config.ConnectionName.IsNull() {
 		connectionName = config.ConnectionName.ValueString()
@@ -145,11 +624,11 @@ func (p *CloudSqlMysqlProvider) Configure(ctx context.Context, req provider.Conf
 		password = config.Password.ValueString()
 	}
 
-	if connectionName == "" {
+	if connectionName == "" && config.Address.IsNull() {
 		resp.Diagnostics.AddAttributeError(path.Root("connection_name"),
 			"Missing Cloud SQL MySQL connection name",
 			"The provider cannot create the Cloud SQL MySQL connection as there is a missing or empty value for the Cloud SQL MySQL connection name. "+
-				"Set the connection name value in the configuration or use the CLOUDSQL_MYSQL_CONNECTION_NAME environment variable. ")
+				"Set the connection name value in the configuration or use the CLOUDSQL_MYSQL_CONNECTION_NAME environment variable, or set `address` to connect without the Cloud SQL connector. ")
 	}
 
 	if username == "" {
@@ -170,6 +649,90 @@ func (p *CloudSqlMysqlProvider) Configure(ctx context.Context, req provider.Conf
 		return
 	}
 
+	if isSecretManagerReference(password) {
+		secret, err := accessSecretVersion(ctx, password)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("password"),
+				"Unable to read the Cloud SQL MySQL password from Secret Manager",
+				"The provider cannot resolve the Secret Manager reference of the password, unexpected error: "+err.Error())
+			return
+		}
+		password = secret
+	}
+
+	sessionVariables := make(map[string]string)
+	if !config.SessionVariables.IsNull() && !config.SessionVariables.IsUnknown() {
+		resp.Diagnostics.Append(config.SessionVariables.ElementsAs(ctx, &sessionVariables, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if !config.TimeZone.IsNull() {
+		if _, ok := sessionVariables["time_zone"]; ok {
+			resp.Diagnostics.AddAttributeError(path.Root("time_zone"),
+				"Conflicting time zone",
+				"The time zone is set with both `time_zone` and `session_variables`, remove `time_zone` from `session_variables`.")
+			return
+		}
+		sessionVariables["time_zone"] = sqlgen.String(config.TimeZone.ValueString())
+	}
+	if !config.MaxExecutionTime.IsNull() {
+		if _, ok := sessionVariables["max_execution_time"]; ok {
+			resp.Diagnostics.AddAttributeError(path.Root("max_execution_time"),
+				"Conflicting max execution time",
+				"The max execution time is set with both `max_execution_time` and `session_variables`, remove `max_execution_time` from `session_variables`.")
+			return
+		}
+		sessionVariables["max_execution_time"] = strconv.FormatInt(config.MaxExecutionTime.ValueInt64(), 10)
+	}
+
+	connectionAttributes := map[string]string{
+		"program_name": "terraform-provider-cloudsqlmysql/" + p.version,
+	}
+	if !config.WorkspaceName.IsNull() {
+		connectionAttributes["workspace"] = config.WorkspaceName.ValueString()
+	}
+	if !config.PasswordVersion.IsNull() {
+		connectionAttributes["password_version"] = strconv.FormatInt(config.PasswordVersion.ValueInt64(), 10)
+	}
+
+	dsnParams := connectionAttributesDSNParam(connectionAttributes) + sessionVariablesDSNParams(sessionVariables)
+	if config.RequireTLS.ValueBool() {
+		dsnParams += "&allowCleartextPasswords=false"
+	}
+
+	if !config.Address.IsNull() {
+		// The stock MySQL driver connects to the address, the Cloud SQL connector is not used
+		tlsParam, err := registerDirectTLSConfig(config.TLSClientCertificate.ValueString(), config.TLSClientKey.ValueString(),
+			config.TLSCACertificate.ValueString(), config.TLSServerName.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Invalid TLS configuration",
+				"The TLS certificates of the provider configuration can't be used: "+err.Error())
+			return
+		}
+		if tlsParam == "" && config.RequireTLS.ValueBool() {
+			resp.Diagnostics.AddAttributeError(path.Root("tls_ca_certificate"),
+				"Missing TLS configuration",
+				"`require_tls` is set and the provider connects to `address` without the Cloud SQL connector, "+
+					"set `tls_ca_certificate` to verify the server certificate.")
+			return
+		}
+
+		dataSourceNameTemplate := fmt.Sprintf("%s:%s@tcp(%s)/%%s?parseTime=true&timeout=%s", username, password, config.Address.ValueString(), connectTimeout) +
+			tlsParam + dsnParams
+
+		dbConfig := newProviderConfig(&config, mysqlConnectionFactory(dataSourceNameTemplate), connectTimeout)
+		dbConfig.connectionName = connectionName
+
+		resp.ResourceData = dbConfig
+		resp.DataSourceData = dbConfig
+
+		p.username = username
+		p.connectionName = connectionName
+		return
+	}
+
 	var dialOptions []cloudsqlconn.DialOption
 	// dialOptions = append(dialOptions, cloudsqlconn.WithDialIAMAuthN(username == "")) // enable IAM authentication when username is not set
 
@@ -187,53 +750,192 @@ func (p *CloudSqlMysqlProvider) Configure(ctx context.Context, req provider.Conf
 
 	if !config.Proxy.IsNull() {
 		tflog.Debug(ctx, "`proxy` is not null")
-		options = append(options, cloudsqlconn.WithDialFunc(createDialer(config.Proxy.ValueString(), ctx)))
+		options = append(options, cloudsqlconn.WithDialFunc(createDialer(config.Proxy.ValueString(), config.ProxyFallbackDirect.ValueBool(), ctx)))
+	}
+
+	if config.LazyRefresh.ValueBool() {
+		options = append(options, cloudsqlconn.WithLazyRefresh())
+	}
+
+	if config.RefreshRetries.ValueInt64() > 0 {
+		client, err := adminAPIHTTPClient(ctx, int(config.RefreshRetries.ValueInt64()))
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to create Cloud SQL MySQL connection",
+				"An unexpected error occurred when creating the Cloud SQL Admin API client.\n\n"+
+					"Error: "+err.Error(),
+			)
+			return
+		}
+		options = append(options, cloudsqlconn.WithHTTPClient(client))
 	}
 
-	_, err := mysql.RegisterDriver("cloudsql-mysql", options...)
+	fingerprint := connectorFingerprint(config.RequireTLS.ValueBool(), config.PrivateIP.ValueBool(), config.PSC.ValueBool(),
+		config.Proxy.ValueString(), config.ProxyFallbackDirect.ValueBool(), config.RefreshRetries.ValueInt64(), config.LazyRefresh.ValueBool())
+	connector, err := acquireConnector(fingerprint, config.RequireTLS.ValueBool(), options...)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to create Cloud SQL MySQL connection",
 			"An unexpected error occurred when creating the Cloud SQL connection.\n\n"+
 				"Error: "+err.Error(),
 		)
+		return
+	}
+	waitRefreshJitter(ctx, time.Duration(config.RefreshJitter.ValueInt64())*time.Second)
+	resp.Diagnostics.Append(checkConnectorRefresh(ctx, connector.dialer, connectionName, connectTimeout)...)
+
+	connectionNames := []string{connectionName}
+	for _, fallback := range config.FallbackConnectionNames {
+		connectionNames = append(connectionNames, fallback.ValueString())
 	}
 
-	dataSourceNameTemplate := fmt.Sprintf("%s:%s@cloudsql-mysql(%s)/%%s?parseTime=true", username, password, connectionName)
+	var targets []connectionTarget
+	for _, name := range connectionNames {
+		dataSourceNameTemplate := fmt.Sprintf("%s:%s@%s(%s)/%%s?parseTime=true&timeout=%s", username, password, connector.driverName, name, connectTimeout) +
+			dsnParams
+		targets = append(targets, connectionTarget{connectionName: name, dsnTemplate: dataSourceNameTemplate})
+	}
 
-	dbConfig := newConfig(dataSourceNameTemplate)
+	dbConfig := newProviderConfig(&config, cloudSQLConnectionFactory(connector.driverName, targets, connectTimeout), connectTimeout)
+	dbConfig.connector = connector
+	dbConfig.connectionName = connectionName
+	dbConfig.privateIP = config.PrivateIP.ValueBool()
+	dbConfig.psc = config.PSC.ValueBool()
 
 	resp.ResourceData = dbConfig
 	resp.DataSourceData = dbConfig
+
+	p.username = username
+	p.connectionName = connectionName
+}
+
+// newProviderConfig returns the Config shared with the resources and data sources, with the settings that don't
+// depend on how the connections are made.
+func newProviderConfig(config *CloudSqlMysqlProviderModel, connectionFactory ConnectionFactory, connectTimeout time.Duration) *Config {
+	dbConfig := newConfig(connectionFactory)
+	dbConfig.allowSystemSchemas = config.AllowSystemSchemas.ValueBool()
+	dbConfig.allowIndexDDL = config.AllowIndexDDL.ValueBool()
+	dbConfig.allowAnonymousAccounts = config.AllowAnonymousAccounts.ValueBool()
+	dbConfig.defaultDatabase = config.Database.ValueString()
+	for _, element := range config.AdditionalAllowedPrivileges.Elements() {
+		if privilege, ok := element.(types.String); ok {
+			dbConfig.additionalAllowedPrivileges = append(dbConfig.additionalAllowedPrivileges, privilege.ValueString())
+		}
+	}
+	dbConfig.logSQL = config.LogSQL.ValueBool()
+	dbConfig.caseSensitivity = caseSensitivityMySQL
+	if !config.CaseSensitivity.IsNull() {
+		dbConfig.caseSensitivity = config.CaseSensitivity.ValueString()
+	}
+	dbConfig.auditRuleRetries = defaultAuditRuleRetries
+	if !config.AuditRuleRetries.IsNull() {
+		dbConfig.auditRuleRetries = int(config.AuditRuleRetries.ValueInt64())
+	}
+	dbConfig.auditRuleLimit = int(config.AuditRuleLimit.ValueInt64())
+	dbConfig.connectTimeout = connectTimeout
+	dbConfig.readTimeout = time.Duration(config.ReadTimeout.ValueInt64()) * time.Second
+	dbConfig.writeTimeout = time.Duration(config.WriteTimeout.ValueInt64()) * time.Second
+	dbConfig.verifyAfterApply = config.VerifyAfterApply.ValueBool()
+	dbConfig.surfaceSQLWarnings = config.SurfaceSQLWarnings.ValueBool()
+	dbConfig.readSource = readSourceShowGrants
+	if !config.ReadSource.IsNull() {
+		dbConfig.readSource = config.ReadSource.ValueString()
+	}
+	dbConfig.readPageSize = defaultReadPageSize
+	if !config.ReadPageSize.IsNull() {
+		dbConfig.readPageSize = int(config.ReadPageSize.ValueInt64())
+	}
+	dbConfig.advisoryLockTimeout = time.Duration(config.AdvisoryLockTimeout.ValueInt64()) * time.Second
+	dbConfig.advisoryLockName = defaultAdvisoryLockName
+	if !config.AdvisoryLockName.IsNull() {
+		dbConfig.advisoryLockName = config.AdvisoryLockName.ValueString()
+	}
+	return dbConfig
 }
 
 func (p *CloudSqlMysqlProvider) Resources(ctx context.Context) []func() resource.Resource {
-	return []func() resource.Resource{
+	resources := []func() resource.Resource{
 		NewRoleResource,
 		newDatabaseGrantResource,
 		newAuditRuleResource,
+		newAuditRuleSetResource,
+		newSchemaBaselineResource,
+		newGrantBundleResource,
+		newAccessMapResource,
+		newGrantCopyResource,
+		newDatabaseReadOnlyResource,
+		newUserPasswordResource,
+		newMonitoringGrantResource,
+		newMonitoringUserResource,
+		newIndexResource,
 	}
+	if p.protocol5 {
+		supported, _ := protocol5Resources(ctx, resources)
+		return supported
+	}
+	return resources
 }
 
-func (p *CloudSqlMysqlProvider) DataSources(_ context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{
+func (p *CloudSqlMysqlProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	dataSources := []func() datasource.DataSource{
 		NewDatabaseDataSource,
+		newDatabaseExistsDataSource,
+		newFlagsCheckDataSource,
+		newRoleEdgesDataSource,
+		newEffectivePrivilegesDataSource,
+		newInstanceDataSource,
+		newProcesslistDataSource,
+		newConnectionStatsDataSource,
+		newTableDataSource,
+		newTableExistsDataSource,
+		newAccessReportDataSource,
+	}
+	if p.protocol5 {
+		supported, _ := protocol5DataSources(ctx, dataSources)
+		return supported
 	}
+	return dataSources
 }
 
 func (p *CloudSqlMysqlProvider) Functions(ctx context.Context) []func() function.Function {
-	return []func() function.Function{}
+	return []func() function.Function{
+		newWhoamiFunction(p),
+	}
+}
+
+// Option changes how the provider returned by New works, for embedding the provider in other tools.
+type Option func(*CloudSqlMysqlProvider)
+
+// WithConnectionFactory makes the provider obtain its connections from the factory instead of the Cloud SQL
+// connector, e.g. to run it against sqlmock or a MySQL container. The connection_name, username, password and the
+// other connector settings are ignored.
+func WithConnectionFactory(connectionFactory ConnectionFactory) Option {
+	return func(p *CloudSqlMysqlProvider) {
+		p.connectionFactory = connectionFactory
+	}
 }
 
-func New(version string) func() provider.Provider {
+func New(version string, options ...Option) func() provider.Provider {
 	return func() provider.Provider {
-		return &CloudSqlMysqlProvider{
+		p := &CloudSqlMysqlProvider{
 			version: version,
 		}
+		for _, option := range options {
+			option(p)
+		}
+		return p
 	}
 }
 
-func createDialer(proxyInput string, ctxProvider context.Context) func(ctx context.Context, network, addr string) (net.Conn, error) {
+const (
+	proxyDialRetries    = 3
+	proxyDialRetryDelay = 250 * time.Millisecond
+)
+
+// createDialer returns a dial function that connects through the SOCKS proxy. Failed dials are retried with
+// exponential backoff and jitter, as a proxy that is briefly unavailable shouldn't fail the apply. When fallbackDirect
+// is set the address is dialed directly after the retries failed.
+func createDialer(proxyInput string, fallbackDirect bool, ctxProvider context.Context) func(ctx context.Context, network, addr string) (net.Conn, error) {
 	return func(ctx context.Context, network, address string) (net.Conn, error) {
 		tflog.Info(ctxProvider, "Creating Dialer with proxy: "+proxyInput)
 		if len(proxyInput) == 0 {
@@ -249,11 +951,86 @@ func createDialer(proxyInput string, ctxProvider context.Context) func(ctx conte
 			return nil, err
 		}
 
-		if xd, ok := d.(proxy.ContextDialer); ok {
-			return xd.DialContext(ctx, network, address)
+		delay := proxyDialRetryDelay
+		for attempt := 0; ; attempt++ {
+			var conn net.Conn
+			if xd, ok := d.(proxy.ContextDialer); ok {
+				conn, err = xd.DialContext(ctx, network, address)
+			} else {
+				tflog.Warn(ctxProvider, "net.Conn created without context.Context")
+				conn, err = d.Dial(network, address) // TODO: force use of context?
+			}
+			if err == nil {
+				tflog.Debug(ctx, "Connected to "+address+" through the proxy "+proxyURL.Host)
+				return conn, nil
+			}
+			if attempt >= proxyDialRetries || ctx.Err() != nil {
+				break
+			}
+
+			recordRetry()
+			jittered := delay/2 + time.Duration(rand.Int63n(int64(delay)))
+			tflog.Info(ctx, fmt.Sprintf("Dialing %s through the proxy failed, retrying in %s (retry %d of %d): %s",
+				address, jittered, attempt+1, proxyDialRetries, err.Error()))
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(jittered):
+			}
+			delay *= 2
+		}
+
+		if !fallbackDirect {
+			return nil, fmt.Errorf("unable to connect to %s through the proxy %s: %w", address, proxyURL.Host, err)
+		}
+
+		tflog.Warn(ctx, "Dialing "+address+" through the proxy failed, falling back to a direct connection: "+err.Error())
+		var direct net.Dialer
+		conn, directErr := direct.DialContext(ctx, network, address)
+		if directErr != nil {
+			return nil, fmt.Errorf("unable to connect to %s through the proxy %s (%w) and directly (%w)", address, proxyURL.Host, err, directErr)
 		}
+		tflog.Info(ctx, "Connected to "+address+" directly, the proxy "+proxyURL.Host+" is unavailable")
+		return conn, nil
+	}
+}
 
-		tflog.Warn(ctxProvider, "net.Conn created without context.Context")
-		return d.Dial(network, address) // TODO: force use of context?
+// dsnReservedParams are lowercase DSN parameters interpreted by the MySQL driver itself, these can't be used as session variable names.
+var dsnReservedParams = []string{"charset", "collation", "compress", "loc", "strict", "timeout", "tls"}
+
+// sessionVariablesDSNParams formats the session variables as DSN parameters. The MySQL driver executes a SET statement
+// for every unknown parameter when a new connection is opened. The result is escaped to be used in the DSN template.
+func sessionVariablesDSNParams(sessionVariables map[string]string) string {
+	names := make([]string, 0, len(sessionVariables))
+	for name := range sessionVariables {
+		names = append(names, name)
+	}
+	sort.Strings(names) // Keep the DSN stable, it's used as key in the connection registry
+
+	var params strings.Builder
+	for _, name := range names {
+		params.WriteString("&" + name + "=" + url.QueryEscape(sessionVariables[name]))
+	}
+	return escapeDSNTemplate(params.String())
+}
+
+// connectionAttributesDSNParam formats the connection attributes that are sent to the server when connecting,
+// these show up in performance_schema.session_connect_attrs.
+func connectionAttributesDSNParam(attributes map[string]string) string {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var pairs []string
+	for _, name := range names {
+		pairs = append(pairs, name+":"+attributes[name])
 	}
+	return escapeDSNTemplate("&connectionAttributes=" + url.QueryEscape(strings.Join(pairs, ",")))
+}
+
+// escapeDSNTemplate escapes the percent signs of encoded values, the DSN template is formatted with the database name.
+func escapeDSNTemplate(value string) string {
+	return strings.ReplaceAll(value, "%", "%%")
 }
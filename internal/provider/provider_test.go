@@ -0,0 +1,23 @@
+package provider
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+// testAccProtoV6ProviderFactories are the provider factories of the acceptance tests. The provider block of the
+// test configurations is empty, the connection is configured with the CLOUDSQL_MYSQL_* environment variables.
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"cloudsqlmysql": NewProtocol6("test"),
+}
+
+// testAccPreCheck fails the acceptance test when the environment variables of the connection are not set.
+func testAccPreCheck(t *testing.T) {
+	for _, name := range []string{"CLOUDSQL_MYSQL_CONNECTION_NAME", "CLOUDSQL_MYSQL_USERNAME", "CLOUDSQL_MYSQL_PASSWORD"} {
+		if os.Getenv(name) == "" {
+			t.Fatal(name + " must be set for acceptance tests")
+		}
+	}
+}
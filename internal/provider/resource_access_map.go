@@ -0,0 +1,491 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"terraform-provider-cloudsqlmysql/internal/sqlgen"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ resource.Resource               = &accessMapResource{}
+	_ resource.ResourceWithConfigure  = &accessMapResource{}
+	_ resource.ResourceWithModifyPlan = &accessMapResource{}
+)
+
+type accessMapResource struct {
+	db     dbPool
+	config *Config
+}
+
+type accessMapResourceModel struct {
+	Users map[string]accessMapUserModel `tfsdk:"users"`
+}
+
+type accessMapUserModel struct {
+	Host types.String `tfsdk:"host"`
+	// Password is set for the users the access map creates and drops, null for users that exist already.
+	Password  types.String              `tfsdk:"password"`
+	Databases map[string][]types.String `tfsdk:"databases"`
+}
+
+func newAccessMapResource() resource.Resource {
+	return &accessMapResource{}
+}
+
+func (r *accessMapResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_access_map"
+}
+
+func (r *accessMapResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Provisions many users and their database privileges from a single map. A user with a password is " +
+			"created by the access map and dropped when it is removed from the map, a user without a password needs to exist " +
+			"already, e.g. created with google_sql_user. Privileges changed outside of Terraform are reported per user and database",
+		MarkdownDescription: "Provisions many users and their database privileges from a single map. A user with a `password` is " +
+			"created by the access map and dropped when it is removed from the map, a user without a `password` needs to exist " +
+			"already, e.g. created with `google_sql_user`. Privileges changed outside of Terraform are reported per user and database",
+		Attributes: map[string]schema.Attribute{
+			"users": schema.MapNestedAttribute{
+				Description:         "The users by name",
+				MarkdownDescription: "The users by name",
+				Required:            true,
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(userNameValidator{}),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"host": schema.StringAttribute{
+							Description:         "The host of the user. Default: %",
+							MarkdownDescription: "The host of the user. Default: `%`",
+							Optional:            true,
+							Computed:            true,
+							Default:             stringdefault.StaticString("%"),
+							Validators: []validator.String{
+								hostValidator{},
+							},
+						},
+						"password": schema.StringAttribute{
+							Description: "The password of the user. When set the user is created with CREATE USER and dropped " +
+								"with its privileges when it is removed from the map, a change sets the password with ALTER USER. " +
+								"Setting it for an existing user takes the user over, removing it leaves the user in place. " +
+								"Like all sensitive values it is stored in the Terraform state",
+							MarkdownDescription: "The password of the user. When set the user is created with `CREATE USER` and dropped " +
+								"with its privileges when it is removed from the map, a change sets the password with `ALTER USER`. " +
+								"Setting it for an existing user takes the user over, removing it leaves the user in place. " +
+								"Like all sensitive values it is stored in the Terraform state",
+							Optional:  true,
+							Sensitive: true,
+							Validators: []validator.String{
+								stringvalidator.LengthAtLeast(1),
+							},
+						},
+						"databases": schema.MapAttribute{
+							Description:         "The privileges of the user by database",
+							MarkdownDescription: "The privileges of the user by database",
+							ElementType:         types.SetType{ElemType: types.StringType},
+							Required:            true,
+							Validators: []validator.Map{
+								mapvalidator.KeysAre(
+									stringvalidator.RegexMatches(regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_\-]*$`),
+										"the keys of `databases` must be correct names of databases"),
+								),
+								mapvalidator.ValueSetsAre(
+									setvalidator.SizeAtLeast(1),
+									privilegesValidator{level: levelDatabase, levelName: "database"},
+								),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *accessMapResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || !req.Plan.Raw.IsFullyKnown() || r.config == nil {
+		return
+	}
+
+	var plan accessMapResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, user := range sortedKeys(plan.Users) {
+		if message := r.config.anonymousAccountError(user, plan.Users[user].Host.ValueString()); message != "" {
+			resp.Diagnostics.AddAttributeError(path.Root("users").AtMapKey(user), "Anonymous account not allowed", message)
+		}
+		for _, database := range sortedKeys(plan.Users[user].Databases) {
+			databasePath := path.Root("users").AtMapKey(user).AtName("databases").AtMapKey(database)
+			if message := r.config.systemSchemaError(database); message != "" {
+				resp.Diagnostics.AddAttributeError(databasePath, "System schema not allowed", message)
+			}
+			for _, privilege := range plan.Users[user].Databases[database] {
+				if message := r.config.unknownPrivilegeError(privilege.ValueString()); message != "" {
+					resp.Diagnostics.AddAttributeError(databasePath, "Invalid privilege", message)
+				}
+				if message := privilegeVersionError(privilege.ValueString(), r.config.serverVersion); message != "" {
+					resp.Diagnostics.AddAttributeError(databasePath, "Privilege not supported by the server version", message)
+				}
+			}
+		}
+	}
+}
+
+func (r *accessMapResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan accessMapResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.apply(ctx, nil, plan.Users)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *accessMapResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withReadTimeout(ctx)
+
+	var state accessMapResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, user := range sortedKeys(state.Users) {
+		entry := state.Users[user]
+		account := r.config.quoteAccount(user, entry.Host.ValueString())
+
+		// One read per user covers all its databases
+		grants, err := r.config.readGrants(ctx, r.db, user, entry.Host.ValueString())
+		if err != nil && !entry.Password.IsNull() && r.config.accountDropped(ctx, r.db, user, entry.Host.ValueString()) {
+			// Removing the user from the state makes the next apply create it again
+			resp.Diagnostics.AddWarning(
+				"Access map drift detected",
+				"The user "+account+" created by the access map was dropped outside of Terraform",
+			)
+			delete(state.Users, user)
+			continue
+		}
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading access map",
+				"Could not read the grants of "+account+", unexpected error: "+err.Error(),
+			)
+			return
+		}
+
+		databases := make(map[string][]types.String)
+		for _, database := range sortedKeys(entry.Databases) {
+			grant := findDatabaseGrant(grants, r.config, database)
+			if grant == nil {
+				resp.Diagnostics.AddWarning(
+					"Access map drift detected",
+					"All privileges of "+account+" on database '"+database+"' were revoked outside of Terraform",
+				)
+				continue
+			}
+
+			serverPrivileges := r.config.expandAllPrivileges(privilegesAsStrings(entry.Databases[database]), grant.PrivilegeNames(), levelDatabase)
+			privileges := reconcilePrivileges(entry.Databases[database], serverPrivileges)
+			if len(privilegesDifference(privileges, entry.Databases[database])) > 0 || len(privilegesDifference(entry.Databases[database], privileges)) > 0 {
+				resp.Diagnostics.AddWarning(
+					"Access map drift detected",
+					"The privileges of "+account+" on database '"+database+"' were changed outside of Terraform, the server has: "+
+						strings.Join(privilegesAsStrings(privileges), ", "),
+				)
+			}
+			databases[database] = privileges
+		}
+		entry.Databases = databases
+		state.Users[user] = entry
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *accessMapResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan, state accessMapResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.apply(ctx, state.Users, plan.Users)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags := resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *accessMapResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state accessMapResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.apply(ctx, state.Users, nil)...)
+}
+
+func (r *accessMapResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to the Cloud SQL MySQL instance",
+			err.Error(),
+		)
+		return
+	}
+
+	err = config.detectServerSettings(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read the Cloud SQL MySQL server settings",
+			err.Error(),
+		)
+		return
+	}
+
+	r.db = db
+	r.config = config
+}
+
+// apply creates, drops, revokes and grants to go from one map to the other, with at most one REVOKE and one GRANT
+// per user and database. A user whose host changes is handled as a different account. The users with a password
+// are created before their privileges are granted and dropped instead of revoking their privileges.
+func (r *accessMapResource) apply(ctx context.Context, from, to map[string]accessMapUserModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, user := range sortedKeys(from) {
+		fromEntry := from[user]
+		toEntry, ok := to[user]
+		if ok && toEntry.Host.ValueString() != fromEntry.Host.ValueString() {
+			ok = false
+		}
+		if !ok && !fromEntry.Password.IsNull() {
+			// Dropping the user removes its privileges too
+			account := r.config.quoteAccount(user, fromEntry.Host.ValueString())
+			if _, err := execContext(ctx, r.db, sqlgen.DropUser(account, true)); err != nil {
+				diags.AddError(
+					"Error applying access map",
+					"Could not drop user "+account+", unexpected error: "+err.Error(),
+				)
+				return diags
+			}
+			continue
+		}
+		for _, database := range sortedKeys(fromEntry.Databases) {
+			var toPrivileges []types.String
+			if ok {
+				toPrivileges = toEntry.Databases[database]
+			}
+			toRevoke := privilegesDifference(fromEntry.Databases[database], toPrivileges)
+			if len(toRevoke) == 0 {
+				continue
+			}
+			account := r.config.quoteAccount(user, fromEntry.Host.ValueString())
+			err := r.exec(ctx, sqlgen.Revoke(toRevoke, sqlgen.DatabaseLevel(database), account))
+			if accountMissingError(err) {
+				// The user was dropped before the access map, its privileges went with it
+				continue
+			}
+			if err != nil {
+				diags.AddError(
+					"Error applying access map",
+					"Could not revoke privileges on database '"+database+"' from "+account+", unexpected error: "+err.Error(),
+				)
+				return diags
+			}
+		}
+	}
+
+	for _, user := range sortedKeys(to) {
+		toEntry := to[user]
+		fromEntry, ok := from[user]
+		if ok && toEntry.Host.ValueString() != fromEntry.Host.ValueString() {
+			ok = false
+		}
+		account := r.config.quoteAccount(user, toEntry.Host.ValueString())
+		// The statements contain the password, they are only logged redacted by log_sql
+		switch {
+		case toEntry.Password.IsNull():
+		case !ok:
+			if _, err := execContext(ctx, r.db, sqlgen.CreateUser(account, toEntry.Password.ValueString())); err != nil {
+				diags.AddError(
+					"Error applying access map",
+					"Could not create user "+account+", unexpected error: "+err.Error(),
+				)
+				return diags
+			}
+		case !toEntry.Password.Equal(fromEntry.Password):
+			if _, err := execContext(ctx, r.db, sqlgen.AlterUserPassword(account, toEntry.Password.ValueString())); err != nil {
+				diags.AddError(
+					"Error applying access map",
+					"Could not set the password of "+account+", unexpected error: "+err.Error(),
+				)
+				return diags
+			}
+		}
+		for _, database := range sortedKeys(toEntry.Databases) {
+			var fromPrivileges []types.String
+			if ok {
+				fromPrivileges = fromEntry.Databases[database]
+			}
+			toGrant := privilegesDifference(toEntry.Databases[database], fromPrivileges)
+			if len(toGrant) == 0 {
+				continue
+			}
+			err := r.exec(ctx, sqlgen.Grant(toGrant, sqlgen.DatabaseLevel(database), account, false))
+			if err != nil {
+				diags.AddError(
+					"Error applying access map",
+					"Could not grant privileges on database '"+database+"' to "+account+", unexpected error: "+err.Error(),
+				)
+				return diags
+			}
+		}
+	}
+
+	if r.config.verifyAfterApply {
+		diags.Append(r.verifyApplied(ctx, from, to)...)
+	}
+	return diags
+}
+
+// verifyApplied reads the grants of the changed users back after apply with verify_after_apply. The privileges of
+// to must be held, the privileges only in from must be revoked.
+func (r *accessMapResource) verifyApplied(ctx context.Context, from, to map[string]accessMapUserModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	verify := func(user, host, database string, granted, revoked []string) {
+		if len(granted) == 0 && len(revoked) == 0 {
+			return
+		}
+		if err := verifyDatabaseGrant(ctx, r.db, r.config, user, host, database, granted, revoked, false); err != nil {
+			diags.AddError(
+				"Error applying access map",
+				"Could not verify the privileges on database '"+database+"' of "+r.config.quoteAccount(user, host)+": "+err.Error(),
+			)
+		}
+	}
+	for _, user := range sortedKeys(to) {
+		toEntry := to[user]
+		fromEntry, ok := from[user]
+		if ok && toEntry.Host.ValueString() != fromEntry.Host.ValueString() {
+			ok = false
+		}
+		for _, database := range sortedKeys(toEntry.Databases) {
+			var fromPrivileges []types.String
+			if ok {
+				fromPrivileges = fromEntry.Databases[database]
+			}
+			verify(user, toEntry.Host.ValueString(), database, privilegesDifference(toEntry.Databases[database], fromPrivileges),
+				privilegesDifference(fromPrivileges, toEntry.Databases[database]))
+		}
+	}
+	for _, user := range sortedKeys(from) {
+		fromEntry := from[user]
+		toEntry, ok := to[user]
+		if (!ok || toEntry.Host.ValueString() != fromEntry.Host.ValueString()) && !fromEntry.Password.IsNull() {
+			continue // Dropped with its privileges
+		}
+		for _, database := range sortedKeys(fromEntry.Databases) {
+			if ok && toEntry.Host.ValueString() == fromEntry.Host.ValueString() && toEntry.Databases[database] != nil {
+				continue // Verified with the databases of to
+			}
+			verify(user, fromEntry.Host.ValueString(), database, nil, privilegesAsStrings(fromEntry.Databases[database]))
+		}
+	}
+	return diags
+}
+
+func (r *accessMapResource) exec(ctx context.Context, sqlStatement string) error {
+	tflog.Debug(ctx, fmt.Sprintf("SQL Statement: \"%s\"", sqlStatement))
+	_, err := execContext(ctx, r.db, sqlStatement)
+	return err
+}
+
+// sortedKeys returns the keys of the map in a stable order, so statements are executed in the same order every run.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
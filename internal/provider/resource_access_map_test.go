@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func newTestAccessMapUser(host string, password types.String, databases map[string][]string) accessMapUserModel {
+	entry := accessMapUserModel{Host: types.StringValue(host), Password: password, Databases: make(map[string][]types.String)}
+	for database, privileges := range databases {
+		for _, privilege := range privileges {
+			entry.Databases[database] = append(entry.Databases[database], types.StringValue(privilege))
+		}
+	}
+	return entry
+}
+
+func TestAccessMapApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   map[string]accessMapUserModel
+		to     map[string]accessMapUserModel
+		expect []string
+	}{
+		{
+			name: "create user",
+			to: map[string]accessMapUserModel{
+				"app": newTestAccessMapUser("%", types.StringValue("secret"), map[string][]string{"orders": {"SELECT"}}),
+			},
+			expect: []string{
+				"CREATE USER 'app'@'%' IDENTIFIED BY 'secret'",
+				"GRANT SELECT ON `orders`.* TO 'app'@'%'",
+			},
+		},
+		{
+			name: "existing user",
+			to: map[string]accessMapUserModel{
+				"app": newTestAccessMapUser("%", types.StringNull(), map[string][]string{"orders": {"SELECT"}}),
+			},
+			expect: []string{"GRANT SELECT ON `orders`.* TO 'app'@'%'"},
+		},
+		{
+			name: "drop user",
+			from: map[string]accessMapUserModel{
+				"app": newTestAccessMapUser("%", types.StringValue("secret"), map[string][]string{"orders": {"SELECT"}}),
+			},
+			expect: []string{"DROP USER IF EXISTS 'app'@'%'"},
+		},
+		{
+			name: "revoke from existing user",
+			from: map[string]accessMapUserModel{
+				"app": newTestAccessMapUser("%", types.StringNull(), map[string][]string{"orders": {"SELECT"}}),
+			},
+			expect: []string{"REVOKE SELECT ON `orders`.* FROM 'app'@'%'"},
+		},
+		{
+			name: "change password",
+			from: map[string]accessMapUserModel{
+				"app": newTestAccessMapUser("%", types.StringValue("secret"), map[string][]string{"orders": {"SELECT"}}),
+			},
+			to: map[string]accessMapUserModel{
+				"app": newTestAccessMapUser("%", types.StringValue("rotated"), map[string][]string{"orders": {"SELECT", "INSERT"}}),
+			},
+			expect: []string{
+				"ALTER USER 'app'@'%' IDENTIFIED BY 'rotated'",
+				"GRANT INSERT ON `orders`.* TO 'app'@'%'",
+			},
+		},
+		{
+			name: "change host",
+			from: map[string]accessMapUserModel{
+				"app": newTestAccessMapUser("%", types.StringValue("secret"), map[string][]string{"orders": {"SELECT"}}),
+			},
+			to: map[string]accessMapUserModel{
+				"app": newTestAccessMapUser("10.0.0.%", types.StringValue("secret"), map[string][]string{"orders": {"SELECT"}}),
+			},
+			expect: []string{
+				"DROP USER IF EXISTS 'app'@'%'",
+				"CREATE USER 'app'@'10.0.0.%' IDENTIFIED BY 'secret'",
+				"GRANT SELECT ON `orders`.* TO 'app'@'10.0.0.%'",
+			},
+		},
+		{
+			name: "take over existing user",
+			from: map[string]accessMapUserModel{
+				"app": newTestAccessMapUser("%", types.StringNull(), map[string][]string{"orders": {"SELECT"}}),
+			},
+			to: map[string]accessMapUserModel{
+				"app": newTestAccessMapUser("%", types.StringValue("secret"), map[string][]string{"orders": {"SELECT"}}),
+			},
+			expect: []string{"ALTER USER 'app'@'%' IDENTIFIED BY 'secret'"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			r := &accessMapResource{db: db, config: &Config{}}
+			for _, statement := range test.expect {
+				expectConnectionID(mock)
+				mock.ExpectExec(statement).WillReturnResult(sqlmock.NewResult(0, 0))
+			}
+
+			if diags := r.apply(context.Background(), test.from, test.to); diags.HasError() {
+				t.Errorf("apply returned %v", diags)
+			}
+		})
+	}
+}
@@ -3,24 +3,82 @@ package provider
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var (
-	_                resource.Resource              = &auditRuleResource{}
-	_                resource.ResourceWithConfigure = &auditRuleResource{}
-	auditRuleDbMutex sync.Mutex                     // Need this because the results of the stored procedures we need to get from a new select query (needs to be global too)
+	_                resource.Resource                     = &auditRuleResource{}
+	_                resource.ResourceWithConfigure        = &auditRuleResource{}
+	_                resource.ResourceWithModifyPlan       = &auditRuleResource{}
+	_                resource.ResourceWithConfigValidators = &auditRuleResource{}
+	auditRuleDbMutex sync.Mutex                            // Need this because the results of the stored procedures we need to get from a new select query (needs to be global too)
 )
 
+const (
+	// mysqlErrSPDoesNotExist is ER_SP_DOES_NOT_EXIST: PROCEDURE does not exist.
+	mysqlErrSPDoesNotExist = 1305
+
+	defaultAuditRuleRetries = 3
+	auditRuleRetryDelay     = 500 * time.Millisecond
+	auditRuleMaxRetryDelay  = 8 * time.Second
+
+	// auditRuleLimitWarningPercentage is the share of audit_rule_limit from which planning new rules warns.
+	auditRuleLimitWarningPercentage = 90
+
+	auditRuleLookupByID         = "id"
+	auditRuleLookupByAttributes = "attributes"
+)
+
+// auditRuleRetryablePatterns are the lowercase parts of the @outmsg and MySQL errors that mean the audit rule
+// tables are busy, the call succeeds when it's retried later.
+var auditRuleRetryablePatterns = []string{"locked", "retry", "try again", "deadlock", "lock wait timeout"}
+
+// auditRuleOperationPresets are the operation lists the preset attribute of cloudsqlmysql_audit_rule expands to, from
+// the operations of the Cloud SQL audit plugin.
+var auditRuleOperationPresets = map[string][]string{
+	"ddl": {"create_database", "alter_database", "drop_database", "create_table", "alter_table", "drop_table", "rename_table",
+		"truncate", "create_index", "drop_index", "create_view", "drop_view", "create_procedure", "alter_procedure",
+		"drop_procedure", "create_function", "alter_function", "drop_function", "create_trigger", "drop_trigger",
+		"create_event", "alter_event", "drop_event"},
+	"dml": {"select", "insert", "insert_select", "update", "update_multi", "delete", "delete_multi", "replace",
+		"replace_select", "load", "call_procedure"},
+	"dcl":   {"grant", "revoke", "revoke_all", "create_user", "alter_user", "drop_user", "rename_user"},
+	"login": {"connect", "disconnect"},
+}
+
+func auditRuleOperationPresetNames() []string {
+	names := make([]string, 0, len(auditRuleOperationPresets))
+	for name := range auditRuleOperationPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 type auditRuleResource struct {
-	db *sql.DB
+	db     dbPool
+	config *Config
 }
 
 type auditRuleResourceModel struct {
@@ -29,7 +87,18 @@ type auditRuleResourceModel struct {
 	Database  types.String `tfsdk:"database"`
 	Object    types.String `tfsdk:"object"`
 	Operation types.String `tfsdk:"operation"`
+	// Preset is expanded into Operation in ModifyPlan.
+	Preset    types.String `tfsdk:"preset"`
 	OpsResult types.String `tfsdk:"ops_result"`
+	LookupBy  types.String `tfsdk:"lookup_by"`
+
+	NormalizedUser      types.String `tfsdk:"normalized_user"`
+	NormalizedDatabase  types.String `tfsdk:"normalized_database"`
+	NormalizedObject    types.String `tfsdk:"normalized_object"`
+	NormalizedOperation types.String `tfsdk:"normalized_operation"`
+	NormalizedOpsResult types.String `tfsdk:"normalized_ops_result"`
+	// RuleJSON is the rule as the audit plugin stores it, for forwarding the managed rules to a SIEM.
+	RuleJSON types.String `tfsdk:"rule_json"`
 }
 
 func newAuditRuleResource() resource.Resource {
@@ -45,27 +114,106 @@ func (r *auditRuleResource) Schema(_ context.Context, _ resource.SchemaRequest,
 		Attributes: map[string]schema.Attribute{
 			"id": schema.Int64Attribute{
 				Computed: true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
 			},
-			"user": schema.StringAttribute{
-				Required: true,
+			"user":     auditRuleWildcardAttribute("user"),
+			"database": auditRuleWildcardAttribute("database"),
+			"object":   auditRuleWildcardAttribute("object"),
+			"operation": schema.StringAttribute{
+				Description: "The comma separated operations the rule applies to. Either operation or preset must be set, with " +
+					"preset it is the expanded operation list",
+				MarkdownDescription: "The comma separated operations the rule applies to. Either `operation` or `preset` must be set, with " +
+					"`preset` it is the expanded operation list",
+				Optional: true,
+				Computed: true,
 			},
-			"database": schema.StringAttribute{
-				Required: true,
+			"preset": schema.StringAttribute{
+				Description: "A group of operations of the Cloud SQL audit plugin that is expanded into operation: ddl (" +
+					strings.Join(auditRuleOperationPresets["ddl"], ", ") + "), dml (" + strings.Join(auditRuleOperationPresets["dml"], ", ") +
+					"), dcl (" + strings.Join(auditRuleOperationPresets["dcl"], ", ") + ") or login (" +
+					strings.Join(auditRuleOperationPresets["login"], ", ") + "). The operations added to a preset by a new version of " +
+					"the provider are added to the rule on the next apply",
+				MarkdownDescription: "A group of operations of the Cloud SQL audit plugin that is expanded into `operation`: `ddl` (" +
+					strings.Join(auditRuleOperationPresets["ddl"], ", ") + "), `dml` (" + strings.Join(auditRuleOperationPresets["dml"], ", ") +
+					"), `dcl` (" + strings.Join(auditRuleOperationPresets["dcl"], ", ") + ") or `login` (" +
+					strings.Join(auditRuleOperationPresets["login"], ", ") + "). The operations added to a preset by a new version of " +
+					"the provider are added to the rule on the next apply",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(auditRuleOperationPresetNames()...),
+				},
 			},
-			"object": schema.StringAttribute{
+			"ops_result": schema.StringAttribute{
 				Required: true,
 			},
-			"operation": schema.StringAttribute{
-				Required: true,
+			"lookup_by": schema.StringAttribute{
+				Description: "How the rule is found when it's read: id reads the rule with the stored id, attributes finds the rule " +
+					"with the same user, database, object, operation and ops_result and stores its id. Use attributes when the rule ids " +
+					"change, e.g. after the instance is restored from a backup. Default: id",
+				MarkdownDescription: "How the rule is found when it's read: `id` reads the rule with the stored id, `attributes` finds the rule " +
+					"with the same `user`, `database`, `object`, `operation` and `ops_result` and stores its id. Use `attributes` when the rule ids " +
+					"change, e.g. after the instance is restored from a backup. Default: `id`",
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(auditRuleLookupByID),
+				Validators: []validator.String{
+					stringvalidator.OneOf(auditRuleLookupByID, auditRuleLookupByAttributes),
+				},
 			},
-			"ops_result": schema.StringAttribute{
-				Required: true,
+			"normalized_user":       normalizedAuditRuleAttribute("user"),
+			"normalized_database":   normalizedAuditRuleAttribute("database"),
+			"normalized_object":     normalizedAuditRuleAttribute("object"),
+			"normalized_operation":  normalizedAuditRuleAttribute("operation"),
+			"normalized_ops_result": normalizedAuditRuleAttribute("ops_result"),
+			"rule_json": schema.StringAttribute{
+				Description: "The rule as JSON object with the columns of mysql.cloudsql_list_audit_rule: id, username, dbname, " +
+					"object, operation and op_result, with the values as the audit plugin stores them. E.g. to forward the managed " +
+					"rules to a SIEM from the Terraform outputs",
+				MarkdownDescription: "The rule as JSON object with the columns of `mysql.cloudsql_list_audit_rule`: `id`, `username`, `dbname`, " +
+					"`object`, `operation` and `op_result`, with the values as the audit plugin stores them. E.g. to forward the managed " +
+					"rules to a SIEM from the Terraform outputs",
+				Computed: true,
 			},
 		},
 	}
 }
 
+// auditRuleWildcardAttribute returns the schema of the rule fields that accept wildcards. The user is a pattern of the
+// audit plugin rather than an account name, so it doesn't use AccountType.
+func auditRuleWildcardAttribute(attribute string) schema.StringAttribute {
+	return schema.StringAttribute{
+		Description: "The " + attribute + " the rule applies to. * matches any sequence of characters, % is accepted as a synonym " +
+			"and converted to * as the audit plugin only understands *. Use \\% for a literal %, _ is always a literal",
+		MarkdownDescription: "The " + attribute + " the rule applies to. `*` matches any sequence of characters, `%` is accepted as a synonym " +
+			"and converted to `*` as the audit plugin only understands `*`. Use `\\%` for a literal `%`, `_` is always a literal",
+		Required: true,
+		Validators: []validator.String{
+			auditRuleWildcardValidator{},
+		},
+	}
+}
+
+func normalizedAuditRuleAttribute(attribute string) schema.StringAttribute {
+	return schema.StringAttribute{
+		Description:         "The `" + attribute + "` as it's stored by the audit plugin",
+		MarkdownDescription: "The `" + attribute + "` as it's stored by the audit plugin",
+		Computed:            true,
+	}
+}
+
 func (r *auditRuleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	auditRuleDbMutex.Lock()
 	defer auditRuleDbMutex.Unlock()
 
@@ -77,10 +225,10 @@ func (r *auditRuleResource) Create(ctx context.Context, req resource.CreateReque
 		return
 	}
 
-	_, err := r.db.ExecContext(ctx, "CALL mysql.cloudsql_create_audit_rule(?,?,?,?,?,1, @outval,@outmsg);",
-		plan.User.ValueString(),
-		plan.Database.ValueString(),
-		plan.Object.ValueString(),
+	err := r.callAuditRuleProcedure(ctx, "CALL mysql.cloudsql_create_audit_rule(?,?,?,?,?,1, @outval,@outmsg);",
+		auditRulePluginValue(plan.User.ValueString()),
+		auditRulePluginValue(plan.Database.ValueString()),
+		auditRulePluginValue(plan.Object.ValueString()),
 		plan.Operation.ValueString(),
 		plan.OpsResult.ValueString())
 	if err != nil {
@@ -91,26 +239,7 @@ func (r *auditRuleResource) Create(ctx context.Context, req resource.CreateReque
 		return
 	}
 
-	err = r.auditRuleStoredProcedureResponse(ctx)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Unable to create the audit rule",
-			"An unexpected error occurred while creating the audit rule: "+err.Error(),
-		)
-		return
-	}
-
-	rows, err := r.db.QueryContext(ctx, "CALL mysql.cloudsql_list_audit_rule('*',@outval,@outmsg);")
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Unable to create the audit rule",
-			"An unexpected error occurred while creating the audit rule: "+err.Error(),
-		)
-		return
-	}
-	defer rows.Close()
-
-	err = r.auditRuleStoredProcedureResponse(ctx)
+	rules, err := listAuditRules(ctx, r.db, "*")
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to create the audit rule",
@@ -119,25 +248,8 @@ func (r *auditRuleResource) Create(ctx context.Context, req resource.CreateReque
 		return
 	}
 
-	id := int64(-1)
-	for rows.Next() {
-		var row auditRuleRow
-		err = rows.Scan(&row.Id, &row.User, &row.Dbname, &row.Object, &row.Operation, &row.OpResult)
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Unable to create the audit rule",
-				"An unexpected error occurred while creating the audit rule: "+err.Error(),
-			)
-			return
-		}
-
-		if row.equalsModel(&plan) {
-			id = row.Id
-			break
-		}
-	}
-
-	if id == -1 {
+	created, ok := newestAuditRule(rules, &plan, nil)
+	if !ok {
 		resp.Diagnostics.AddError(
 			"Unable to create the audit rule",
 			"An unexpected error occurred while creating the audit rule: the audit rule is not found after creation",
@@ -145,7 +257,8 @@ func (r *auditRuleResource) Create(ctx context.Context, req resource.CreateReque
 		return
 	}
 
-	plan.Id = types.Int64Value(id)
+	plan.Id = types.Int64Value(created.Id)
+	created.setNormalized(&plan)
 
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
@@ -155,6 +268,9 @@ func (r *auditRuleResource) Create(ctx context.Context, req resource.CreateReque
 }
 
 func (r *auditRuleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withReadTimeout(ctx)
+
 	auditRuleDbMutex.Lock()
 	defer auditRuleDbMutex.Unlock()
 
@@ -168,32 +284,46 @@ func (r *auditRuleResource) Read(ctx context.Context, req resource.ReadRequest,
 
 	id := state.Id.ValueInt64()
 
-	var row auditRuleRow
+	if state.LookupBy.ValueString() == auditRuleLookupByAttributes {
+		rules, err := listAuditRules(ctx, r.db, "*")
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to read audit rule",
+				"An unexpected error occurred while listing the audit rules, error: "+err.Error(),
+			)
+			return
+		}
 
-	err := r.db.QueryRowContext(ctx, "CALL mysql.cloudsql_list_audit_rule(?,@outval,@outmsg);", id).Scan(&row.Id, &row.User, &row.Dbname, &row.Object, &row.Operation, &row.OpResult)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Unable to read audit rule",
-			fmt.Sprintf("An unexpected error occurred while fetching the audit rule with id %d, error: %s", id, err.Error()),
-		)
+		row, found := matchingAuditRule(rules, &state)
+		if !found {
+			tflog.Info(ctx, fmt.Sprintf("No audit rule matches the attributes of the rule with id %d, removing it from the state", id))
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		if row.Id != id {
+			tflog.Info(ctx, fmt.Sprintf("The audit rule with id %d now has id %d", id, row.Id))
+		}
+
+		state.Id = types.Int64Value(row.Id)
+		row.setNormalized(&state)
+
+		diags = resp.State.Set(ctx, &state)
+		resp.Diagnostics.Append(diags...)
 		return
 	}
 
-	err = r.auditRuleStoredProcedureResponse(ctx)
+	row, err := r.readAuditRule(ctx, id)
 	if err != nil {
 		resp.Diagnostics.AddError(
-			"Unable to update the audit rule",
+			"Unable to read audit rule",
 			fmt.Sprintf("An unexpected error occurred while fetching the audit rule with id %d, error: %s", id, err.Error()),
 		)
 		return
 	}
 
 	state.Id = types.Int64Value(row.Id)
-	state.User = types.StringValue(row.User)
-	state.Database = types.StringValue(row.Dbname)
-	state.Object = types.StringValue(row.Object)
-	state.Operation = types.StringValue(row.Operation)
-	state.OpsResult = types.StringValue(row.OpResult)
+	row.setChanged(&state)
+	row.setNormalized(&state)
 
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -203,6 +333,16 @@ func (r *auditRuleResource) Read(ctx context.Context, req resource.ReadRequest,
 }
 
 func (r *auditRuleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	auditRuleDbMutex.Lock()
 	defer auditRuleDbMutex.Unlock()
 
@@ -214,11 +354,11 @@ func (r *auditRuleResource) Update(ctx context.Context, req resource.UpdateReque
 		return
 	}
 
-	_, err := r.db.ExecContext(ctx, "CALL mysql.cloudsql_update_audit_rule(?,?,?,?,?,?,1, @outval,@outmsg);",
+	err := r.callAuditRuleProcedure(ctx, "CALL mysql.cloudsql_update_audit_rule(?,?,?,?,?,?,1, @outval,@outmsg);",
 		plan.Id.ValueInt64(),
-		plan.User.ValueString(),
-		plan.Database.ValueString(),
-		plan.Object.ValueString(),
+		auditRulePluginValue(plan.User.ValueString()),
+		auditRulePluginValue(plan.Database.ValueString()),
+		auditRulePluginValue(plan.Object.ValueString()),
 		plan.Operation.ValueString(),
 		plan.OpsResult.ValueString())
 	if err != nil {
@@ -229,14 +369,26 @@ func (r *auditRuleResource) Update(ctx context.Context, req resource.UpdateReque
 		return
 	}
 
-	err = r.auditRuleStoredProcedureResponse(ctx)
+	row, err := r.readAuditRule(ctx, plan.Id.ValueInt64())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to update the audit rule",
-			"An unexpected error occurred while updating the audit rule: "+err.Error(),
+			"An unexpected error occurred while reading the updated audit rule: "+err.Error(),
 		)
 		return
 	}
+	// Some plugin versions accept the update without changing the rule
+	if !auditRuleValuesEqual(row.User, auditRulePluginValue(plan.User.ValueString())) ||
+		!auditRuleValuesEqual(row.Dbname, auditRulePluginValue(plan.Database.ValueString())) ||
+		!auditRuleValuesEqual(row.Object, auditRulePluginValue(plan.Object.ValueString())) {
+		resp.Diagnostics.AddError(
+			"Audit rule not updated",
+			fmt.Sprintf("The audit plugin accepted the update of audit rule %d but didn't change it, it still has user '%s', "+
+				"database '%s' and object '%s'. Recreate the rule with terraform apply -replace.", plan.Id.ValueInt64(), row.User, row.Dbname, row.Object),
+		)
+		return
+	}
+	row.setNormalized(&plan)
 
 	diags = resp.State.Set(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
@@ -246,6 +398,16 @@ func (r *auditRuleResource) Update(ctx context.Context, req resource.UpdateReque
 }
 
 func (r *auditRuleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	auditRuleDbMutex.Lock()
 	defer auditRuleDbMutex.Unlock()
 
@@ -259,15 +421,7 @@ func (r *auditRuleResource) Delete(ctx context.Context, req resource.DeleteReque
 
 	id := state.Id.ValueInt64()
 
-	_, err := r.db.ExecContext(ctx, "CALL mysql.cloudsql_delete_audit_rule(?,1,@outval,@outmsg);", id)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Unable to delete the audit rule",
-			fmt.Sprintf("An unexpected error occurred while deleting the audit rule with id %d, error: %s", id, err.Error()),
-		)
-		return
-	}
-	err = r.auditRuleStoredProcedureResponse(ctx)
+	err := r.callAuditRuleProcedure(ctx, "CALL mysql.cloudsql_delete_audit_rule(?,1,@outval,@outmsg);", id)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to delete the audit rule",
@@ -291,7 +445,7 @@ func (r *auditRuleResource) Configure(ctx context.Context, req resource.Configur
 		return
 	}
 
-	db, err := config.connectToMySQLNoDb() // Not connecting to a specific database
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to connect to the Cloud SQL MySQL instance",
@@ -301,12 +455,212 @@ func (r *auditRuleResource) Configure(ctx context.Context, req resource.Configur
 	}
 
 	r.db = db
+	r.config = config
+}
+
+func (r *auditRuleResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("operation"),
+			path.MatchRoot("preset"),
+		),
+	}
+}
+
+func (r *auditRuleResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	// The preset is expanded on every plan, the state holds the expanded operations so reading the rule doesn't
+	// show a change
+	var preset types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("preset"), &preset)...)
+	if resp.Diagnostics.HasError() || preset.IsUnknown() {
+		return
+	}
+	if !preset.IsNull() {
+		operations := strings.Join(auditRuleOperationPresets[preset.ValueString()], ",")
+		var stateOperation types.String
+		if !req.State.Raw.IsNull() {
+			resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("operation"), &stateOperation)...)
+		}
+		// The order the audit plugin stores the operations in is kept
+		if !stateOperation.IsNull() && auditRuleOperationsEqual(stateOperation.ValueString(), operations) {
+			operations = stateOperation.ValueString()
+		}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("operation"), operations)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if !req.State.Raw.IsNull() {
+		r.requireReplaceWithoutUpdate(ctx, req, resp)
+		return
+	}
+	// Only new rules count against the limit, updates change a rule in place
+	resp.Diagnostics.Append(r.checkAuditRuleLimit(ctx, 1)...)
 }
 
-func (r *auditRuleResource) auditRuleStoredProcedureResponse(ctx context.Context) error {
+// requireReplaceWithoutUpdate recreates the rule when user, database or object change and the audit plugin has no
+// procedure to update a rule in place.
+func (r *auditRuleResource) requireReplaceWithoutUpdate(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if r.config == nil {
+		return
+	}
+
+	var plan, state auditRuleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var changed []path.Path
+	for _, field := range []struct {
+		name        string
+		plan, state types.String
+	}{
+		{"user", plan.User, state.User},
+		{"database", plan.Database, state.Database},
+		{"object", plan.Object, state.Object},
+	} {
+		if field.plan.IsUnknown() || !auditRuleValuesEqual(field.plan.ValueString(), field.state.ValueString()) {
+			changed = append(changed, path.Root(field.name))
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	available, err := r.config.detectAuditRuleUpdate(ctx, r.db)
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Unable to detect the audit rule update support",
+			"The procedures of the audit plugin could not be read, the rule is updated in place: "+err.Error(),
+		)
+		return
+	}
+	if !available {
+		resp.RequiresReplace = append(resp.RequiresReplace, changed...)
+	}
+}
+
+// checkAuditRuleLimit compares the number of rules on the instance plus the new rules with audit_rule_limit, so the
+// plan fails instead of the stored procedure in the middle of the apply.
+func (r *auditRuleResource) checkAuditRuleLimit(ctx context.Context, newRules int) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if r.config == nil || r.config.auditRuleLimit == 0 || newRules <= 0 {
+		return diags
+	}
+
+	available, err := r.config.detectAuditRules(ctx, r.db)
+	if err != nil || !available {
+		// Creating the rule reports the missing stored procedures
+		return diags
+	}
+
+	auditRuleDbMutex.Lock()
+	rules, err := listAuditRules(ctx, r.db, "*")
+	auditRuleDbMutex.Unlock()
+	if err != nil {
+		diags.AddWarning(
+			"Unable to check the audit rule limit",
+			"The audit rules could not be counted, the limit of "+strconv.Itoa(r.config.auditRuleLimit)+" rules is not checked: "+err.Error(),
+		)
+		return diags
+	}
+
+	limit := r.config.auditRuleLimit
+	total := len(rules) + newRules
+	switch {
+	case total > limit:
+		diags.AddError(
+			"Audit rule limit exceeded",
+			fmt.Sprintf("The instance has %d audit rules, creating %d more exceeds the audit_rule_limit of %d rules. "+
+				"Remove audit rules or combine them with wildcards.", len(rules), newRules, limit),
+		)
+	case total*100 >= limit*auditRuleLimitWarningPercentage:
+		diags.AddWarning(
+			"Audit rule limit almost reached",
+			fmt.Sprintf("After creating %d audit rules the instance has %d of the %d audit rules of audit_rule_limit.", newRules, total, limit),
+		)
+	}
+	return diags
+}
+
+// callAuditRuleProcedure calls a stored procedure that changes audit rules and checks its response, reading the
+// response on the same connection as the call. Calls reporting a busy error are retried with exponential backoff.
+func (r *auditRuleResource) callAuditRuleProcedure(ctx context.Context, query string, args ...any) error {
+	retries := defaultAuditRuleRetries
+	if r.config != nil {
+		retries = r.config.auditRuleRetries
+	}
+
+	delay := auditRuleRetryDelay
+	for attempt := 0; ; attempt++ {
+		err := r.callAuditRuleProcedureOnce(ctx, query, args...)
+		if err == nil || attempt >= retries || !auditRuleErrorRetryable(err) {
+			return err
+		}
+
+		recordRetry()
+		tflog.Info(ctx, fmt.Sprintf("Audit rule procedure is busy, retrying in %s (retry %d of %d): %s", delay, attempt+1, retries, err.Error()))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, auditRuleMaxRetryDelay)
+	}
+}
+
+func (r *auditRuleResource) callAuditRuleProcedureOnce(ctx context.Context, query string, args ...any) error {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = execOnConn(ctx, r.db, conn, query, args...)
+	if err != nil {
+		return auditRuleProcedureError(err)
+	}
+	return auditRuleStoredProcedureResponse(ctx, conn)
+}
+
+// auditProcedureMissing returns true for ERROR 1305, returned when the audit stored procedures are not installed
+// because the cloudsql_mysql_audit instance flag is not enabled.
+func auditProcedureMissing(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrSPDoesNotExist
+}
+
+// auditRuleProcedureError explains a missing audit stored procedure, other errors are returned as they are.
+func auditRuleProcedureError(err error) error {
+	if !auditProcedureMissing(err) {
+		return err
+	}
+	return fmt.Errorf("the audit stored procedures of Cloud SQL are not installed on the instance. Set the cloudsql_mysql_audit "+
+		"instance flag to ON to install them, changing the flag restarts the instance. Error: %w", err)
+}
+
+// auditRuleErrorRetryable returns true for the errors the audit plugin reports when its tables are busy.
+func auditRuleErrorRetryable(err error) bool {
+	message := strings.ToLower(err.Error())
+	for _, pattern := range auditRuleRetryablePatterns {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func auditRuleStoredProcedureResponse(ctx context.Context, db dbExecutor) error {
 	var outval sql.NullInt16
 	var outmsg sql.NullString
-	err := r.db.QueryRowContext(ctx, "SELECT @outval, @outmsg;").Scan(&outval, &outmsg)
+	err := queryRow(ctx, db, "SELECT @outval, @outmsg;", nil, &outval, &outmsg)
 	if err != nil {
 		return err
 	}
@@ -318,6 +672,80 @@ func (r *auditRuleResource) auditRuleStoredProcedureResponse(ctx context.Context
 	return nil
 }
 
+// listAuditRules returns the audit rules with the comma separated ids, * lists all rules. The procedure only
+// filters by id, the rules are listed and their response read on the same connection.
+func listAuditRules(ctx context.Context, db dbPool, ids string) ([]auditRuleRow, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	stmtCtx, cancel := statementContext(ctx)
+	defer cancel()
+	rows, err := queryContext(stmtCtx, conn, "CALL mysql.cloudsql_list_audit_rule(?,@outval,@outmsg);", ids)
+	if err != nil {
+		return nil, auditRuleProcedureError(err)
+	}
+
+	var rules []auditRuleRow
+	for rows.Next() {
+		var row auditRuleRow
+		if err = rows.Scan(&row.Id, &row.User, &row.Dbname, &row.Object, &row.Operation, &row.OpResult); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		rules = append(rules, row)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, auditRuleStoredProcedureResponse(ctx, conn)
+}
+
+// newestAuditRule returns the rule with the highest id that matches the model and is not taken yet. The audit plugin
+// accepts identical rules, the one just created has the highest id.
+func newestAuditRule(rules []auditRuleRow, model *auditRuleResourceModel, taken map[int64]bool) (auditRuleRow, bool) {
+	var newest auditRuleRow
+	found := false
+	for _, row := range rules {
+		if taken[row.Id] || !row.equalsModel(model) {
+			continue
+		}
+		if !found || row.Id > newest.Id {
+			newest = row
+			found = true
+		}
+	}
+	return newest, found
+}
+
+// matchingAuditRule returns the rule that matches the attributes of the model, the rule with the id of the model is
+// preferred when identical rules exist. Otherwise the newest rule is used, like after creating the rule.
+func matchingAuditRule(rules []auditRuleRow, model *auditRuleResourceModel) (auditRuleRow, bool) {
+	for _, row := range rules {
+		if row.Id == model.Id.ValueInt64() && row.equalsModel(model) {
+			return row, true
+		}
+	}
+	return newestAuditRule(rules, model, nil)
+}
+
+// readAuditRule returns the audit rule with the id, listed with listAuditRules so the response of the procedure is
+// read on the connection that called it.
+func (r *auditRuleResource) readAuditRule(ctx context.Context, id int64) (auditRuleRow, error) {
+	rules, err := listAuditRules(ctx, r.db, strconv.FormatInt(id, 10))
+	if err != nil {
+		return auditRuleRow{}, err
+	}
+	if len(rules) == 0 {
+		return auditRuleRow{}, sql.ErrNoRows
+	}
+	return rules[0], nil
+}
+
 type auditRuleRow struct {
 	Id        int64
 	User      string
@@ -328,20 +756,139 @@ type auditRuleRow struct {
 }
 
 func (row *auditRuleRow) equalsModel(model *auditRuleResourceModel) bool {
-	if !strings.EqualFold(row.User, model.User.ValueString()) {
-		return false
+	return auditRuleValuesEqual(row.User, auditRulePluginValue(model.User.ValueString())) &&
+		auditRuleValuesEqual(row.Dbname, auditRulePluginValue(model.Database.ValueString())) &&
+		auditRuleValuesEqual(row.Object, auditRulePluginValue(model.Object.ValueString())) &&
+		auditRuleOperationsEqual(row.Operation, model.Operation.ValueString()) &&
+		auditRuleValuesEqual(row.OpResult, model.OpsResult.ValueString())
+}
+
+// setChanged sets the fields of the model that differ from the rule. The configured values are kept when the
+// audit plugin stores an equivalent representation.
+func (row *auditRuleRow) setChanged(model *auditRuleResourceModel) {
+	if !auditRuleValuesEqual(auditRulePluginValue(model.User.ValueString()), row.User) {
+		model.User = types.StringValue(row.User)
+	}
+	if !auditRuleValuesEqual(auditRulePluginValue(model.Database.ValueString()), row.Dbname) {
+		model.Database = types.StringValue(row.Dbname)
+	}
+	if !auditRuleValuesEqual(auditRulePluginValue(model.Object.ValueString()), row.Object) {
+		model.Object = types.StringValue(row.Object)
+	}
+	if !auditRuleOperationsEqual(model.Operation.ValueString(), row.Operation) {
+		model.Operation = types.StringValue(row.Operation)
+	}
+	if !auditRuleValuesEqual(model.OpsResult.ValueString(), row.OpResult) {
+		model.OpsResult = types.StringValue(row.OpResult)
+	}
+}
+
+func (row *auditRuleRow) setNormalized(model *auditRuleResourceModel) {
+	model.NormalizedUser = types.StringValue(row.User)
+	model.NormalizedDatabase = types.StringValue(row.Dbname)
+	model.NormalizedObject = types.StringValue(row.Object)
+	model.NormalizedOperation = types.StringValue(row.Operation)
+	model.NormalizedOpsResult = types.StringValue(row.OpResult)
+	model.RuleJSON = types.StringValue(row.json())
+}
+
+// json returns the rule as JSON object, the keys are the columns of mysql.cloudsql_list_audit_rule.
+func (row *auditRuleRow) json() string {
+	rule, _ := json.Marshal(struct {
+		Id        int64  `json:"id"`
+		Username  string `json:"username"`
+		Dbname    string `json:"dbname"`
+		Object    string `json:"object"`
+		Operation string `json:"operation"`
+		OpResult  string `json:"op_result"`
+	}{row.Id, row.User, row.Dbname, row.Object, row.Operation, row.OpResult}) // Strings and numbers always marshal
+	return string(rule)
+}
+
+// normalizeAuditRuleValue returns the canonical form of an audit rule field, plugin versions differ in storing
+// an empty value or * for "any".
+func normalizeAuditRuleValue(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return "*"
+	}
+	return value
+}
+
+// normalizeAuditRuleOperation returns the canonical form of the comma separated operations, the order of the
+// operations doesn't matter.
+func normalizeAuditRuleOperation(operation string) string {
+	var operations []string
+	for _, op := range strings.Split(operation, ",") {
+		operations = append(operations, normalizeAuditRuleValue(op))
+	}
+	sort.Strings(operations)
+	return strings.Join(operations, ",")
+}
+
+func auditRuleValuesEqual(a, b string) bool {
+	return normalizeAuditRuleValue(a) == normalizeAuditRuleValue(b)
+}
+
+func auditRuleOperationsEqual(a, b string) bool {
+	return normalizeAuditRuleOperation(a) == normalizeAuditRuleOperation(b)
+}
+
+// convertAuditRuleWildcards converts a rule field to the syntax of the audit plugin: % is a synonym of the * wildcard,
+// \% is a literal % and \\ a literal backslash. The plugin has no single character wildcard, _ is a literal.
+func convertAuditRuleWildcards(value string) (string, error) {
+	var converted strings.Builder
+	escaped := false
+	for _, c := range value {
+		switch {
+		case escaped:
+			if c != '%' && c != '_' && c != '\\' {
+				return "", fmt.Errorf("invalid escape sequence \\%c, only \\%%, \\_ and \\\\ are allowed", c)
+			}
+			converted.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '%':
+			converted.WriteRune('*')
+		default:
+			converted.WriteRune(c)
+		}
 	}
-	if !strings.EqualFold(row.Dbname, model.Database.ValueString()) {
-		return false
+	if escaped {
+		return "", errors.New("the value ends with an unfinished escape sequence")
 	}
-	if !strings.EqualFold(row.Object, model.Object.ValueString()) {
-		return false
+	return converted.String(), nil
+}
+
+// auditRulePluginValue returns the value as it's sent to the audit plugin, the value is already validated.
+func auditRulePluginValue(value string) string {
+	converted, err := convertAuditRuleWildcards(value)
+	if err != nil {
+		return value
 	}
-	if !strings.EqualFold(row.Operation, model.Operation.ValueString()) {
-		return false
+	return converted
+}
+
+var _ validator.String = auditRuleWildcardValidator{}
+
+// auditRuleWildcardValidator validates the wildcards and escape sequences of an audit rule field.
+type auditRuleWildcardValidator struct{}
+
+func (v auditRuleWildcardValidator) Description(_ context.Context) string {
+	return "value must only use the * and % wildcards and the \\%, \\_ and \\\\ escape sequences"
+}
+
+func (v auditRuleWildcardValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v auditRuleWildcardValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
 	}
-	if !strings.EqualFold(row.OpResult, model.OpsResult.ValueString()) {
-		return false
+
+	if _, err := convertAuditRuleWildcards(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid audit rule value", "The value \""+req.ConfigValue.ValueString()+"\" is invalid, "+err.Error())
 	}
-	return true
 }
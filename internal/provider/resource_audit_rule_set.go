@@ -0,0 +1,410 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource               = &auditRuleSetResource{}
+	_ resource.ResourceWithConfigure  = &auditRuleSetResource{}
+	_ resource.ResourceWithModifyPlan = &auditRuleSetResource{}
+)
+
+// auditRuleSetResource manages many audit rules as one resource. The rules are created and deleted without
+// reloading the audit plugin, which is reloaded once per operation, and are listed with a single call.
+type auditRuleSetResource struct {
+	auditRuleResource // Configure and the stored procedure calls are shared with the single rule resource
+}
+
+type auditRuleSetResourceModel struct {
+	Rules []auditRuleSetRuleModel `tfsdk:"rules"`
+	Ids   []types.Int64           `tfsdk:"ids"`
+}
+
+type auditRuleSetRuleModel struct {
+	User      types.String `tfsdk:"user"`
+	Database  types.String `tfsdk:"database"`
+	Object    types.String `tfsdk:"object"`
+	Operation types.String `tfsdk:"operation"`
+	OpsResult types.String `tfsdk:"ops_result"`
+}
+
+func newAuditRuleSetResource() resource.Resource {
+	return &auditRuleSetResource{}
+}
+
+func (r *auditRuleSetResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_audit_rule_set"
+}
+
+func (r *auditRuleSetResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages a list of audit rules as one resource. Applying hundreds of rules is much faster than with " +
+			"cloudsqlmysql_audit_rule, the audit plugin is reloaded once instead of once per rule",
+		MarkdownDescription: "Manages a list of audit rules as one resource. Applying hundreds of rules is much faster than with " +
+			"`cloudsqlmysql_audit_rule`, the audit plugin is reloaded once instead of once per rule",
+		Attributes: map[string]schema.Attribute{
+			"rules": schema.ListNestedAttribute{
+				Description:         "The audit rules. Rules are matched by their values, changing a rule deletes it and creates a new one",
+				MarkdownDescription: "The audit rules. Rules are matched by their values, changing a rule deletes it and creates a new one",
+				Required:            true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"user":     auditRuleWildcardAttribute("user"),
+						"database": auditRuleWildcardAttribute("database"),
+						"object":   auditRuleWildcardAttribute("object"),
+						"operation": schema.StringAttribute{
+							Description:         "The comma separated operations the rule applies to",
+							MarkdownDescription: "The comma separated operations the rule applies to",
+							Required:            true,
+						},
+						"ops_result": schema.StringAttribute{
+							Description:         "The result of the operations that is audited: S, U, B or E",
+							MarkdownDescription: "The result of the operations that is audited: `S`, `U`, `B` or `E`",
+							Required:            true,
+						},
+					},
+				},
+			},
+			"ids": schema.ListAttribute{
+				Description:         "The ids of the audit rules, in the order of rules",
+				MarkdownDescription: "The ids of the audit rules, in the order of `rules`",
+				ElementType:         types.Int64Type,
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *auditRuleSetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	auditRuleDbMutex.Lock()
+	defer auditRuleDbMutex.Unlock()
+
+	var plan auditRuleSetResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state := auditRuleSetResourceModel{Rules: []auditRuleSetRuleModel{}, Ids: []types.Int64{}}
+	err := r.createRules(ctx, &state, plan.Rules)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to create the audit rules",
+			"An unexpected error occurred while creating the audit rules: "+err.Error(),
+		)
+		// The rules created so far are kept in the state, so they are deleted when the tainted resource is replaced
+		if len(state.Ids) == 0 {
+			return
+		}
+	}
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *auditRuleSetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withReadTimeout(ctx)
+
+	auditRuleDbMutex.Lock()
+	defer auditRuleDbMutex.Unlock()
+
+	var state auditRuleSetResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rowsById := make(map[int64]auditRuleRow)
+	if len(state.Ids) > 0 {
+		rows, err := listAuditRules(ctx, r.db, state.idList())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to read the audit rules",
+				"An unexpected error occurred while reading the audit rules: "+err.Error(),
+			)
+			return
+		}
+		for _, row := range rows {
+			rowsById[row.Id] = row
+		}
+	}
+
+	// Rules deleted outside of Terraform are dropped, so the plan creates them again
+	rules, ids := []auditRuleSetRuleModel{}, []types.Int64{}
+	for i, rule := range state.Rules {
+		row, ok := rowsById[state.Ids[i].ValueInt64()]
+		if !ok {
+			continue
+		}
+		model := rule.model()
+		row.setChanged(model)
+		rules = append(rules, auditRuleSetRuleFromModel(model))
+		ids = append(ids, state.Ids[i])
+	}
+	state.Rules, state.Ids = rules, ids
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *auditRuleSetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	auditRuleDbMutex.Lock()
+	defer auditRuleDbMutex.Unlock()
+
+	var plan, state auditRuleSetResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Rules that are still planned keep their id, the others are deleted and the new ones created
+	kept := make(map[int]int) // Index in the plan to index in the state
+	matched := make(map[int]bool)
+	for i, planRule := range plan.Rules {
+		for j, stateRule := range state.Rules {
+			if matched[j] {
+				continue
+			}
+			row := stateRule.row()
+			if row.equalsModel(planRule.model()) {
+				kept[i] = j
+				matched[j] = true
+				break
+			}
+		}
+	}
+
+	var deleted []string
+	for j := range state.Rules {
+		if !matched[j] {
+			deleted = append(deleted, strconv.FormatInt(state.Ids[j].ValueInt64(), 10))
+		}
+	}
+	if len(deleted) > 0 {
+		err := r.callAuditRuleProcedure(ctx, "CALL mysql.cloudsql_delete_audit_rule(?,0,@outval,@outmsg);", strings.Join(deleted, ","))
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to update the audit rules",
+				"An unexpected error occurred while deleting the audit rules "+strings.Join(deleted, ",")+": "+err.Error(),
+			)
+			return
+		}
+	}
+
+	var created []auditRuleSetRuleModel
+	for i, rule := range plan.Rules {
+		if _, ok := kept[i]; !ok {
+			created = append(created, rule)
+		}
+	}
+	newRules := auditRuleSetResourceModel{Rules: []auditRuleSetRuleModel{}, Ids: []types.Int64{}}
+	err := r.createRules(ctx, &newRules, created)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to update the audit rules",
+			"An unexpected error occurred while creating the audit rules: "+err.Error(),
+		)
+	}
+
+	// The state follows the order of the plan, rules that failed to be created are left out
+	result := auditRuleSetResourceModel{Rules: []auditRuleSetRuleModel{}, Ids: []types.Int64{}}
+	next := 0
+	for i, rule := range plan.Rules {
+		if j, ok := kept[i]; ok {
+			result.Rules = append(result.Rules, rule)
+			result.Ids = append(result.Ids, state.Ids[j])
+			continue
+		}
+		if next < len(newRules.Ids) {
+			result.Rules = append(result.Rules, rule)
+			result.Ids = append(result.Ids, newRules.Ids[next])
+			next++
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &result)...)
+}
+
+func (r *auditRuleSetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	auditRuleDbMutex.Lock()
+	defer auditRuleDbMutex.Unlock()
+
+	var state auditRuleSetResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || len(state.Ids) == 0 {
+		return
+	}
+
+	err := r.callAuditRuleProcedure(ctx, "CALL mysql.cloudsql_delete_audit_rule(?,1,@outval,@outmsg);", state.idList())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to delete the audit rules",
+			"An unexpected error occurred while deleting the audit rules "+state.idList()+": "+err.Error(),
+		)
+	}
+}
+
+// createRules creates the rules without reloading the audit plugin, looks up their ids with a single list call and
+// then reloads the plugin once. The rules that were created are added to the state, also when an error is returned.
+func (r *auditRuleSetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var planRules, stateRules types.List
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("rules"), &planRules)...)
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("rules"), &stateRules)...)
+	}
+	if resp.Diagnostics.HasError() || planRules.IsUnknown() {
+		return
+	}
+
+	// Approximates the new rules by the growth of the list, changed rules are recreated after the old ones are deleted
+	resp.Diagnostics.Append(r.checkAuditRuleLimit(ctx, len(planRules.Elements())-len(stateRules.Elements()))...)
+}
+
+func (r *auditRuleSetResource) createRules(ctx context.Context, state *auditRuleSetResourceModel, rules []auditRuleSetRuleModel) error {
+	var createErr error
+	created := 0
+	for _, rule := range rules {
+		createErr = r.callAuditRuleProcedure(ctx, "CALL mysql.cloudsql_create_audit_rule(?,?,?,?,?,0, @outval,@outmsg);",
+			auditRulePluginValue(rule.User.ValueString()),
+			auditRulePluginValue(rule.Database.ValueString()),
+			auditRulePluginValue(rule.Object.ValueString()),
+			rule.Operation.ValueString(),
+			rule.OpsResult.ValueString())
+		if createErr != nil {
+			break
+		}
+		created++
+	}
+
+	// Deleted rules are only removed by the reload as well, so it runs even when nothing was created
+	err := r.callAuditRuleProcedure(ctx, "CALL mysql.cloudsql_reload_audit_rule(1,@outval,@outmsg);")
+	if err != nil {
+		return errors.Join(createErr, err)
+	}
+	if created == 0 {
+		return createErr
+	}
+
+	rows, err := listAuditRules(ctx, r.db, "*")
+	if err != nil {
+		return errors.Join(createErr, err)
+	}
+	taken := make(map[int64]bool)
+	for _, id := range state.Ids {
+		taken[id.ValueInt64()] = true
+	}
+	// The rules are matched in reverse, the last rule created has the highest id when the list contains equal rules
+	found := make([]auditRuleRow, created)
+	for i := created - 1; i >= 0; i-- {
+		row, ok := newestAuditRule(rows, rules[i].model(), taken)
+		if !ok {
+			return errors.Join(createErr, errors.New("the audit rule "+rules[i].String()+" is not found after creation"))
+		}
+		taken[row.Id] = true
+		found[i] = row
+	}
+	for i, row := range found {
+		state.Rules = append(state.Rules, rules[i])
+		state.Ids = append(state.Ids, types.Int64Value(row.Id))
+	}
+	return createErr
+}
+
+// idList returns the ids of the rules separated by commas, as the audit stored procedures expect them.
+func (m *auditRuleSetResourceModel) idList() string {
+	var ids []string
+	for _, id := range m.Ids {
+		ids = append(ids, strconv.FormatInt(id.ValueInt64(), 10))
+	}
+	return strings.Join(ids, ",")
+}
+
+// model returns the rule as the model of the single rule resource, to share the comparisons with it.
+func (m auditRuleSetRuleModel) model() *auditRuleResourceModel {
+	return &auditRuleResourceModel{
+		User:      m.User,
+		Database:  m.Database,
+		Object:    m.Object,
+		Operation: m.Operation,
+		OpsResult: m.OpsResult,
+	}
+}
+
+// row returns the rule as it's sent to the audit plugin.
+func (m auditRuleSetRuleModel) row() auditRuleRow {
+	return auditRuleRow{
+		User:      auditRulePluginValue(m.User.ValueString()),
+		Dbname:    auditRulePluginValue(m.Database.ValueString()),
+		Object:    auditRulePluginValue(m.Object.ValueString()),
+		Operation: m.Operation.ValueString(),
+		OpResult:  m.OpsResult.ValueString(),
+	}
+}
+
+func (m auditRuleSetRuleModel) String() string {
+	return strings.Join([]string{m.User.ValueString(), m.Database.ValueString(), m.Object.ValueString(),
+		m.Operation.ValueString(), m.OpsResult.ValueString()}, "/")
+}
+
+func auditRuleSetRuleFromModel(model *auditRuleResourceModel) auditRuleSetRuleModel {
+	return auditRuleSetRuleModel{
+		User:      model.User,
+		Database:  model.Database,
+		Object:    model.Object,
+		Operation: model.Operation,
+		OpsResult: model.OpsResult,
+	}
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+)
+
+var auditRuleColumns = []string{"id", "username", "dbname", "object", "operation", "op_result"}
+
+func TestListAuditRules(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery("CALL mysql.cloudsql_list_audit_rule(?,@outval,@outmsg);").WithArgs("*").
+		WillReturnRows(sqlmock.NewRows(auditRuleColumns).AddRow(1, "app@%", "app", "*", "select", "B").AddRow(2, "*", "*", "*", "ddl", "E"))
+	mock.ExpectQuery("SELECT @outval, @outmsg;").WillReturnRows(sqlmock.NewRows([]string{"@outval", "@outmsg"}).AddRow(0, nil))
+
+	rules, err := listAuditRules(context.Background(), db, "*")
+	if err != nil {
+		t.Fatalf("listAuditRules returned error: %v", err)
+	}
+	want := []auditRuleRow{
+		{Id: 1, User: "app@%", Dbname: "app", Object: "*", Operation: "select", OpResult: "B"},
+		{Id: 2, User: "*", Dbname: "*", Object: "*", Operation: "ddl", OpResult: "E"},
+	}
+	if len(rules) != len(want) || rules[0] != want[0] || rules[1] != want[1] {
+		t.Errorf("listAuditRules returned %v, want %v", rules, want)
+	}
+}
+
+func TestListAuditRulesProcedureFailed(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery("CALL mysql.cloudsql_list_audit_rule(?,@outval,@outmsg);").WithArgs("7").
+		WillReturnRows(sqlmock.NewRows(auditRuleColumns))
+	mock.ExpectQuery("SELECT @outval, @outmsg;").WillReturnRows(sqlmock.NewRows([]string{"@outval", "@outmsg"}).AddRow(1, "Invalid rule id"))
+
+	if _, err := listAuditRules(context.Background(), db, "7"); err == nil || err.Error() != "Invalid rule id" {
+		t.Errorf("listAuditRules returned %v, want the message of the procedure", err)
+	}
+}
+
+func TestListAuditRulesProcedureMissing(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery("CALL mysql.cloudsql_list_audit_rule(?,@outval,@outmsg);").WithArgs("*").
+		WillReturnError(&mysql.MySQLError{Number: 1305, Message: "PROCEDURE mysql.cloudsql_list_audit_rule does not exist"})
+
+	if _, err := listAuditRules(context.Background(), db, "*"); err == nil {
+		t.Error("listAuditRules returned no error without the procedure")
+	}
+}
+
+func TestReadAuditRule(t *testing.T) {
+	db, mock := newMockDB(t)
+	r := &auditRuleResource{db: db, config: &Config{}}
+	mock.ExpectQuery("CALL mysql.cloudsql_list_audit_rule(?,@outval,@outmsg);").WithArgs("3").
+		WillReturnRows(sqlmock.NewRows(auditRuleColumns).AddRow(3, "app@%", "app", "orders", "update", "S"))
+	mock.ExpectQuery("SELECT @outval, @outmsg;").WillReturnRows(sqlmock.NewRows([]string{"@outval", "@outmsg"}).AddRow(0, nil))
+	mock.ExpectQuery("CALL mysql.cloudsql_list_audit_rule(?,@outval,@outmsg);").WithArgs("4").
+		WillReturnRows(sqlmock.NewRows(auditRuleColumns))
+	mock.ExpectQuery("SELECT @outval, @outmsg;").WillReturnRows(sqlmock.NewRows([]string{"@outval", "@outmsg"}).AddRow(0, nil))
+
+	row, err := r.readAuditRule(context.Background(), 3)
+	if err != nil || row.Id != 3 || row.Object != "orders" {
+		t.Errorf("readAuditRule returned %v, %v", row, err)
+	}
+	if _, err = r.readAuditRule(context.Background(), 4); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("readAuditRule of a missing rule returned %v, want %v", err, sql.ErrNoRows)
+	}
+}
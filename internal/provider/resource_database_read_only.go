@@ -0,0 +1,294 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"terraform-provider-cloudsqlmysql/internal/sqlgen"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource               = &databaseReadOnlyResource{}
+	_ resource.ResourceWithConfigure  = &databaseReadOnlyResource{}
+	_ resource.ResourceWithModifyPlan = &databaseReadOnlyResource{}
+)
+
+// mysqlErrBadDB is ER_BAD_DB_ERROR: Unknown database.
+const mysqlErrBadDB = 1049
+
+// readOnlyDatabaseVersion is the first version that supports ALTER DATABASE ... READ ONLY.
+var readOnlyDatabaseVersion = serverVersion{major: 8, patch: 22}
+
+type databaseReadOnlyResource struct {
+	db     dbPool
+	config *Config
+}
+
+type databaseReadOnlyResourceModel struct {
+	Database types.String `tfsdk:"database"`
+	ReadOnly types.Bool   `tfsdk:"read_only"`
+}
+
+func newDatabaseReadOnlyResource() resource.Resource {
+	return &databaseReadOnlyResource{}
+}
+
+func (r *databaseReadOnlyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_database_read_only"
+}
+
+func (r *databaseReadOnlyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages the READ ONLY option of an existing database, e.g. to freeze a schema during a migration. " +
+			"The database is made writable again on destroy. Requires MySQL 8.0.22 or later",
+		MarkdownDescription: "Manages the `READ ONLY` option of an existing database, e.g. to freeze a schema during a migration. " +
+			"The database is made writable again on destroy. Requires MySQL 8.0.22 or later",
+		Attributes: map[string]schema.Attribute{
+			"database": schema.StringAttribute{
+				Description:         "The name of the database",
+				MarkdownDescription: "The name of the database",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, maxDatabaseNameLength),
+				},
+			},
+			"read_only": schema.BoolAttribute{
+				Description: "When true the database is read only, statements that modify it or its objects fail. " +
+					"Changes made outside of Terraform are detected. Default: true",
+				MarkdownDescription: "When `true` the database is read only, statements that modify it or its objects fail. " +
+					"Changes made outside of Terraform are detected. Default: `true`",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(true),
+			},
+		},
+	}
+}
+
+func (r *databaseReadOnlyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan databaseReadOnlyResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.setReadOnly(ctx, plan.Database.ValueString(), plan.ReadOnly.ValueBool())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error setting the database read only option",
+			"Could not alter database '"+plan.Database.ValueString()+"', unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *databaseReadOnlyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withReadTimeout(ctx)
+
+	var state databaseReadOnlyResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	database := state.Database.ValueString()
+	var options string
+	err := r.config.queryRowPrepared(ctx, r.db, "SELECT OPTIONS FROM INFORMATION_SCHEMA.SCHEMATA_EXTENSIONS WHERE SCHEMA_NAME = ?",
+		[]any{r.config.databaseNameForLookup(database)}, &options)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The database was dropped, the option went with it
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading the database read only option",
+			"Could not read the options of database '"+database+"', unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	state.ReadOnly = types.BoolValue(readOnlyOption(options))
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *databaseReadOnlyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan databaseReadOnlyResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.setReadOnly(ctx, plan.Database.ValueString(), plan.ReadOnly.ValueBool())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error setting the database read only option",
+			"Could not alter database '"+plan.Database.ValueString()+"', unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *databaseReadOnlyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state databaseReadOnlyResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.setReadOnly(ctx, state.Database.ValueString(), false)
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrBadDB {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error removing the database read only option",
+			"Could not alter database '"+state.Database.ValueString()+"', unexpected error: "+err.Error(),
+		)
+	}
+}
+
+func (r *databaseReadOnlyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to the Cloud SQL MySQL instance",
+			err.Error(),
+		)
+		return
+	}
+
+	err = config.detectServerSettings(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read the Cloud SQL MySQL server settings",
+			err.Error(),
+		)
+		return
+	}
+
+	r.db = db
+	r.config = config
+}
+
+func (r *databaseReadOnlyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.config == nil {
+		return
+	}
+
+	var plan databaseReadOnlyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.config.serverVersion.less(readOnlyDatabaseVersion) {
+		resp.Diagnostics.AddError(
+			"Read only databases not supported by the server version",
+			fmt.Sprintf("ALTER DATABASE ... READ ONLY requires MySQL %s or later, the server runs MySQL %s",
+				readOnlyDatabaseVersion, r.config.serverVersion),
+		)
+	}
+	if !plan.Database.IsUnknown() {
+		if message := r.config.systemSchemaError(plan.Database.ValueString()); message != "" {
+			resp.Diagnostics.AddAttributeError(path.Root("database"), "System schema not allowed", message)
+		}
+	}
+}
+
+func (r *databaseReadOnlyResource) setReadOnly(ctx context.Context, database string, readOnly bool) error {
+	option := "0"
+	if readOnly {
+		option = "1"
+	}
+	_, err := execContext(ctx, r.db, "ALTER DATABASE "+sqlgen.Identifier(database)+" READ ONLY = "+option)
+	return err
+}
+
+// readOnlyOption checks if the OPTIONS of INFORMATION_SCHEMA.SCHEMATA_EXTENSIONS contain READ ONLY=1.
+func readOnlyOption(options string) bool {
+	for _, option := range strings.Fields(strings.ReplaceAll(strings.ToUpper(options), "READ ONLY", "READ_ONLY")) {
+		if option == "READ_ONLY=1" {
+			return true
+		}
+	}
+	return false
+}
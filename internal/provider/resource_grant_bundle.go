@@ -0,0 +1,292 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"terraform-provider-cloudsqlmysql/internal/grantparser"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ resource.Resource               = &grantBundleResource{}
+	_ resource.ResourceWithConfigure  = &grantBundleResource{}
+	_ resource.ResourceWithModifyPlan = &grantBundleResource{}
+)
+
+// grantBundleStatementPrefixes are the account management statements allowed in a bundle.
+var grantBundleStatementPrefixes = []string{"GRANT", "REVOKE", "CREATE ROLE", "DROP ROLE", "SET DEFAULT ROLE", "ALTER USER"}
+
+type grantBundleResource struct {
+	db     dbPool
+	config *Config
+}
+
+type grantBundleResourceModel struct {
+	Statements []grantBundleStatementModel `tfsdk:"statements"`
+}
+
+type grantBundleStatementModel struct {
+	Apply  types.String `tfsdk:"apply"`
+	Revert types.String `tfsdk:"revert"`
+}
+
+func newGrantBundleResource() resource.Resource {
+	return &grantBundleResource{}
+}
+
+func (r *grantBundleResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_grant_bundle"
+}
+
+func (r *grantBundleResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Applies account management statements as one unit. MySQL commits GRANT, REVOKE and the other account " +
+			"management statements implicitly, so they can't run in a transaction. When a statement fails, the revert " +
+			"statements of the statements already applied are executed in reverse order and the failed statement is reported",
+		MarkdownDescription: "Applies account management statements as one unit. MySQL commits `GRANT`, `REVOKE` and the other account " +
+			"management statements implicitly, so they can't run in a transaction. When a statement fails, the `revert` " +
+			"statements of the statements already applied are executed in reverse order and the failed statement is reported",
+		Attributes: map[string]schema.Attribute{
+			"statements": schema.ListNestedAttribute{
+				Description:         "The statements in the order they are applied, they are reverted in reverse order on destroy",
+				MarkdownDescription: "The statements in the order they are applied, they are reverted in reverse order on destroy",
+				Required:            true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"apply": schema.StringAttribute{
+							Description: "The statement to apply: GRANT, REVOKE, CREATE ROLE, DROP ROLE, SET DEFAULT ROLE or ALTER USER. " +
+								"Grants on the MySQL system schemas require allow_system_schemas",
+							MarkdownDescription: "The statement to apply: `GRANT`, `REVOKE`, `CREATE ROLE`, `DROP ROLE`, `SET DEFAULT ROLE` or `ALTER USER`. " +
+								"Grants on the MySQL system schemas require `allow_system_schemas`",
+							Required: true,
+							Validators: []validator.String{
+								grantBundleStatementValidator{},
+							},
+						},
+						"revert": schema.StringAttribute{
+							Description:         "The statement that undoes apply. Grants on the MySQL system schemas require allow_system_schemas",
+							MarkdownDescription: "The statement that undoes `apply`. Grants on the MySQL system schemas require `allow_system_schemas`",
+							Required:            true,
+							Validators: []validator.String{
+								grantBundleStatementValidator{},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *grantBundleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan grantBundleResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for i, statement := range plan.Statements {
+		_, err := execContext(ctx, r.db, statement.Apply.ValueString())
+		if err == nil {
+			continue
+		}
+
+		resp.Diagnostics.AddError(
+			"Error applying grant bundle",
+			fmt.Sprintf("Statement %d of %d failed: %s\n\nStatement: %s", i+1, len(plan.Statements), err.Error(), statement.Apply.ValueString()),
+		)
+		resp.Diagnostics.Append(revertStatements(ctx, r.db, plan.Statements[:i], "Error reverting grant bundle")...)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *grantBundleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// The statements can't be read back, the state is kept as applied
+}
+
+func (r *grantBundleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// No updates possible, needs to recreate
+}
+
+func (r *grantBundleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state grantBundleResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(revertStatements(ctx, r.db, state.Statements, "Error reverting grant bundle")...)
+}
+
+// revertStatements executes the revert statements in reverse order. All statements are tried, a failing revert
+// doesn't stop the others. A revoke from an account that was dropped or no longer holds the privileges succeeds.
+func revertStatements(ctx context.Context, db dbPool, statements []grantBundleStatementModel, summary string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for i := len(statements) - 1; i >= 0; i-- {
+		_, err := execContext(ctx, db, statements[i].Revert.ValueString())
+		if accountMissingError(err) {
+			tflog.Debug(ctx, fmt.Sprintf("Revert of statement %d has nothing to revoke: %s", i+1, err.Error()))
+			continue
+		}
+		if err != nil {
+			diags.AddError(
+				summary,
+				fmt.Sprintf("Revert of statement %d failed: %s\n\nStatement: %s", i+1, err.Error(), statements[i].Revert.ValueString()),
+			)
+		}
+	}
+	return diags
+}
+
+func (r *grantBundleResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to the Cloud SQL MySQL instance",
+			err.Error(),
+		)
+		return
+	}
+
+	r.db = db
+	r.config = config
+}
+
+func (r *grantBundleResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || !req.Plan.Raw.IsFullyKnown() || r.config == nil {
+		return
+	}
+
+	var plan grantBundleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The validator of the statements has no access to allow_system_schemas, the grants are checked here.
+	for i, statement := range plan.Statements {
+		for _, name := range []string{"apply", "revert"} {
+			value := statement.Apply
+			if name == "revert" {
+				value = statement.Revert
+			}
+			grant, err := grantparser.Parse(value.ValueString())
+			if err != nil {
+				continue
+			}
+			if message := r.config.systemSchemaGrantError(grant); message != "" {
+				resp.Diagnostics.AddAttributeError(path.Root("statements").AtListIndex(i).AtName(name), "System schema not allowed", message)
+			}
+		}
+	}
+}
+
+var _ validator.String = grantBundleStatementValidator{}
+
+// grantBundleStatementValidator validates that the statement is a single account management statement.
+type grantBundleStatementValidator struct{}
+
+func (v grantBundleStatementValidator) Description(_ context.Context) string {
+	return "statement must be a single " + strings.Join(grantBundleStatementPrefixes, ", ") + " statement"
+}
+
+func (v grantBundleStatementValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v grantBundleStatementValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	statement := strings.TrimSuffix(strings.TrimSpace(req.ConfigValue.ValueString()), ";")
+	normalized := strings.ToUpper(strings.Join(strings.Fields(statement), " "))
+	for _, prefix := range grantBundleStatementPrefixes {
+		if strings.HasPrefix(normalized, prefix+" ") && !strings.Contains(statement, ";") {
+			v.validateCloudSQLPrivileges(req, resp, statement)
+			return
+		}
+	}
+	resp.Diagnostics.AddAttributeError(req.Path, "Invalid statement", "The statement \""+req.ConfigValue.ValueString()+"\" is invalid, "+v.Description(ctx))
+}
+
+// validateCloudSQLPrivileges flags GRANT statements that fail on Cloud SQL. Privileges that no account has are errors,
+// ALL PRIVILEGES on *.* is a warning as it only works for the privileges the provider account has itself.
+func (v grantBundleStatementValidator) validateCloudSQLPrivileges(req validator.StringRequest, resp *validator.StringResponse, statement string) {
+	grant, err := grantparser.Parse(statement)
+	if err != nil || grant.Revoke || grant.Level == grantparser.LevelRole {
+		return
+	}
+
+	allPrivileges := false
+	for _, privilege := range grant.PrivilegeNames() {
+		if message := cloudSQLRestrictionError(privilege); message != "" {
+			resp.Diagnostics.AddAttributeError(req.Path, "Privilege not available on Cloud SQL", message)
+		}
+		allPrivileges = allPrivileges || privilegeNamesEqual(privilege, "ALL PRIVILEGES")
+	}
+	if allPrivileges && grant.Level == grantparser.LevelGlobal {
+		resp.Diagnostics.AddAttributeWarning(req.Path, "ALL PRIVILEGES on *.* on Cloud SQL",
+			"ALL PRIVILEGES on *.* includes privileges like SUPER and FILE that are not available on Cloud SQL, the grant fails "+
+				"unless the provider account has all privileges it includes. Grant the privileges explicitly, see "+
+				cloudSQLPrivilegesDocumentation+" for the privileges that can be granted")
+	}
+}
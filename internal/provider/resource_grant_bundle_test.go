@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestGrantBundleModifyPlanSystemSchema(t *testing.T) {
+	r := &grantBundleResource{config: &Config{}}
+	statementType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{"apply": tftypes.String, "revert": tftypes.String}}
+	plan := newTestPlan(t, r, map[string]tftypes.Value{
+		"statements": tftypes.NewValue(tftypes.List{ElementType: statementType}, []tftypes.Value{
+			tftypes.NewValue(statementType, map[string]tftypes.Value{
+				"apply":  tftypes.NewValue(tftypes.String, "GRANT SELECT ON `mysql`.* TO 'app'@'%'"),
+				"revert": tftypes.NewValue(tftypes.String, "REVOKE SELECT ON `mysql`.* FROM 'app'@'%'"),
+			}),
+		}),
+	})
+	req := resource.ModifyPlanRequest{Plan: plan, State: tfsdk.State{Schema: plan.Schema, Raw: tftypes.NewValue(plan.Raw.Type(), nil)}}
+
+	resp := &resource.ModifyPlanResponse{Plan: plan}
+	r.ModifyPlan(context.Background(), req, resp)
+	if resp.Diagnostics.ErrorsCount() != 1 {
+		t.Errorf("ModifyPlan returned %v, want an error for the grant on mysql", resp.Diagnostics)
+	}
+
+	r.config.allowSystemSchemas = true
+	resp = &resource.ModifyPlanResponse{Plan: plan}
+	r.ModifyPlan(context.Background(), req, resp)
+	if resp.Diagnostics.HasError() {
+		t.Errorf("ModifyPlan with allow_system_schemas returned %v", resp.Diagnostics)
+	}
+}
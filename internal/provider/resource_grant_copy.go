@@ -0,0 +1,348 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"terraform-provider-cloudsqlmysql/internal/grantparser"
+	"terraform-provider-cloudsqlmysql/internal/sqlgen"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource               = &grantCopyResource{}
+	_ resource.ResourceWithConfigure  = &grantCopyResource{}
+	_ resource.ResourceWithModifyPlan = &grantCopyResource{}
+)
+
+type grantCopyResource struct {
+	db     dbPool
+	config *Config
+}
+
+type grantCopyResourceModel struct {
+	Source     AccountValue                `tfsdk:"source"`
+	Target     AccountValue                `tfsdk:"target"`
+	Databases  []types.String              `tfsdk:"databases"`
+	Statements []grantBundleStatementModel `tfsdk:"statements"`
+}
+
+func newGrantCopyResource() resource.Resource {
+	return &grantCopyResource{}
+}
+
+func (r *grantCopyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_grant_copy"
+}
+
+func (r *grantCopyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Copies the grants of a template user or role to another user or role, like \"create a user like X\". " +
+			"The grants are copied once when the resource is created, later changes of the source are not followed. " +
+			"The copied grants are revoked on destroy, proxy grants and, unless allow_system_schemas is set, grants on the MySQL system schemas are not copied",
+		MarkdownDescription: "Copies the grants of a template user or role to another user or role, like \"create a user like X\". " +
+			"The grants are copied once when the resource is created, later changes of the source are not followed. " +
+			"The copied grants are revoked on destroy, `PROXY` grants and, unless `allow_system_schemas` is set, grants on the MySQL system schemas are not copied",
+		Attributes: map[string]schema.Attribute{
+			"source": schema.StringAttribute{
+				Description:         "The user or role to copy the grants from, user or 'user'@'host'. The host defaults to %",
+				MarkdownDescription: "The user or role to copy the grants from, `user` or `'user'@'host'`. The host defaults to `%`",
+				CustomType:          AccountType{},
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"target": schema.StringAttribute{
+				Description:         "The existing user or role to copy the grants to, user or 'user'@'host'. The host defaults to %",
+				MarkdownDescription: "The existing user or role to copy the grants to, `user` or `'user'@'host'`. The host defaults to `%`",
+				CustomType:          AccountType{},
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"databases": schema.SetAttribute{
+				Description: "Only copy the grants on these databases. Global grants and granted roles are only copied when " +
+					"no databases are set",
+				MarkdownDescription: "Only copy the grants on these databases. Global grants and granted roles are only copied when " +
+					"no `databases` are set",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.RequiresReplace(),
+				},
+			},
+			"statements": schema.ListNestedAttribute{
+				Description:         "The statements that copied the grants and the statements that revoke them on destroy",
+				MarkdownDescription: "The statements that copied the grants and the statements that revoke them on destroy",
+				Computed:            true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"apply": schema.StringAttribute{
+							Description:         "The statement that copied a grant",
+							MarkdownDescription: "The statement that copied a grant",
+							Computed:            true,
+						},
+						"revert": schema.StringAttribute{
+							Description:         "The statement that revokes the copied grant",
+							MarkdownDescription: "The statement that revokes the copied grant",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *grantCopyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan grantCopyResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	source, err := plan.Source.Account()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("source"), "Invalid account", err.Error())
+		return
+	}
+	targetAccount, err := plan.Target.Account()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("target"), "Invalid account", err.Error())
+		return
+	}
+	if r.config.accountsEqual(source, targetAccount) {
+		resp.Diagnostics.AddAttributeError(path.Root("target"), "Invalid account", "The target can't be the same account as the source")
+		return
+	}
+
+	grants, err := r.config.showGrants(ctx, r.db, source.User, source.Host)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error copying grants",
+			"Could not read the grants of "+plan.Source.ValueString()+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	plan.Statements = []grantBundleStatementModel{}
+	for _, grant := range grants {
+		if !r.copied(grant, plan.Databases) {
+			continue
+		}
+		apply, revert := r.config.copyGrantStatements(grant, r.config.quoteAccount(targetAccount.User, targetAccount.Host))
+		if message := r.config.systemSchemaGrantError(grant); message != "" {
+			resp.Diagnostics.AddWarning("Grant on a system schema not copied", message+"\n\nStatement: "+apply)
+			continue
+		}
+		plan.Statements = append(plan.Statements, grantBundleStatementModel{
+			Apply:  types.StringValue(apply),
+			Revert: types.StringValue(revert),
+		})
+	}
+
+	for i, statement := range plan.Statements {
+		_, err = execContext(ctx, r.db, statement.Apply.ValueString())
+		if err == nil {
+			continue
+		}
+
+		resp.Diagnostics.AddError(
+			"Error copying grants",
+			fmt.Sprintf("Statement %d of %d failed: %s\n\nStatement: %s", i+1, len(plan.Statements), err.Error(), statement.Apply.ValueString()),
+		)
+		resp.Diagnostics.Append(revertStatements(ctx, r.db, plan.Statements[:i], "Error reverting copied grants")...)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *grantCopyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// The copy is a snapshot, the state is kept as applied
+}
+
+func (r *grantCopyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// No updates possible, needs to recreate
+}
+
+func (r *grantCopyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state grantCopyResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(revertStatements(ctx, r.db, state.Statements, "Error revoking copied grants")...)
+}
+
+func (r *grantCopyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to the Cloud SQL MySQL instance",
+			err.Error(),
+		)
+		return
+	}
+
+	err = config.detectServerSettings(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read the Cloud SQL MySQL server settings",
+			err.Error(),
+		)
+		return
+	}
+
+	r.db = db
+	r.config = config
+}
+
+func (r *grantCopyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.config == nil {
+		return
+	}
+
+	var plan grantCopyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if message := r.config.anonymousAccountValueError(plan.Source); message != "" {
+		resp.Diagnostics.AddAttributeError(path.Root("source"), "Anonymous account not allowed", message)
+	}
+	if message := r.config.anonymousAccountValueError(plan.Target); message != "" {
+		resp.Diagnostics.AddAttributeError(path.Root("target"), "Anonymous account not allowed", message)
+	}
+	for _, database := range plan.Databases {
+		if message := r.config.systemSchemaError(database.ValueString()); message != "" {
+			resp.Diagnostics.AddAttributeError(path.Root("databases"), "System schema not allowed", message)
+		}
+	}
+	r.config.keepStateAccounts(ctx, req, resp, path.Root("source"), path.Root("target"))
+}
+
+// copied returns true when the grant is copied. USAGE on *.* only means the account exists and proxy grants
+// can't be granted without the grant option on the proxied account, these are never copied.
+func (r *grantCopyResource) copied(grant *grantparser.Grant, databases []types.String) bool {
+	if grant.Level == grantparser.LevelProxy {
+		return false
+	}
+	if grant.Level == grantparser.LevelGlobal && !grant.Revoke && len(grant.Privileges) == 1 && grant.HasPrivilege("USAGE") {
+		return false
+	}
+	if databases == nil {
+		return true
+	}
+
+	if grant.Level == grantparser.LevelGlobal || grant.Level == grantparser.LevelRole {
+		return false
+	}
+	for _, database := range databases {
+		if r.config.databaseNamesEqual(grant.Database, database.ValueString()) {
+			return true
+		}
+	}
+	return false
+}
+
+// copyGrantStatements returns the statement that applies the grant to the target and the statement that undoes it.
+// Partial revokes are copied as REVOKE and undone with GRANT.
+func (c *Config) copyGrantStatements(grant *grantparser.Grant, target string) (string, string) {
+	if grant.Level == grantparser.LevelRole {
+		var roles []string
+		for _, role := range grant.Roles {
+			roles = append(roles, c.quoteAccount(role.User, role.Host))
+		}
+		return sqlgen.GrantRoles(roles, target, grant.WithGrantOption), sqlgen.RevokeRoles(roles, target)
+	}
+
+	var privileges []string
+	for _, privilege := range grant.Privileges {
+		if len(privilege.Columns) == 0 {
+			privileges = append(privileges, privilege.Name)
+			continue
+		}
+		privileges = append(privileges, sqlgen.ColumnPrivilege(privilege.Name, privilege.Columns))
+	}
+
+	var level string
+	switch grant.Level {
+	case grantparser.LevelGlobal:
+		level = sqlgen.GlobalLevel
+	case grantparser.LevelDatabase:
+		level = sqlgen.DatabaseLevel(grant.Database)
+	case grantparser.LevelRoutine:
+		level = sqlgen.RoutineLevel(grant.ObjectType, grant.Database, grant.Object)
+	default:
+		level = sqlgen.TableLevel(grant.Database, grant.Object)
+	}
+
+	if grant.Revoke {
+		return sqlgen.Revoke(privileges, level, target), sqlgen.Grant(privileges, level, target, false)
+	}
+
+	revoked := privileges
+	if grant.WithGrantOption {
+		revoked = append(revoked, "GRANT OPTION")
+	}
+	return sqlgen.Grant(privileges, level, target, grant.WithGrantOption), sqlgen.Revoke(revoked, level, target)
+}
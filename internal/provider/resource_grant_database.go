@@ -3,13 +3,19 @@ package provider
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
 	"strings"
 
+	"terraform-provider-cloudsqlmysql/internal/grantparser"
+	"terraform-provider-cloudsqlmysql/internal/sqlgen"
+
 	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -27,10 +33,12 @@ var (
 	_ resource.Resource                     = &databaseGrantResource{}
 	_ resource.ResourceWithConfigure        = &databaseGrantResource{}
 	_ resource.ResourceWithConfigValidators = &databaseGrantResource{}
+	_ resource.ResourceWithModifyPlan       = &databaseGrantResource{}
 )
 
 type databaseGrantResource struct {
-	db *sql.DB
+	db     dbPool
+	config *Config
 }
 
 func newDatabaseGrantResource() resource.Resource {
@@ -59,12 +67,18 @@ func (r *databaseGrantResource) Schema(_ context.Context, _ resource.SchemaReque
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
+				Validators: []validator.String{
+					userNameValidator{},
+				},
 			},
 			"role": schema.StringAttribute{
 				Optional: true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
+				Validators: []validator.String{
+					userNameValidator{},
+				},
 			},
 			"host": schema.StringAttribute{
 				Optional: true,
@@ -73,24 +87,229 @@ func (r *databaseGrantResource) Schema(_ context.Context, _ resource.SchemaReque
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
+				Validators: []validator.String{
+					hostValidator{},
+				},
+			},
+			"allow_role_host": schema.BoolAttribute{
+				Description: "MySQL creates roles with the host % unless a host is given, a role with another host is usually a " +
+					"mistake for the role with %. When false the plan fails for a role with a host other than %. Default: false",
+				MarkdownDescription: "MySQL creates roles with the host `%` unless a host is given, a role with another host is usually a " +
+					"mistake for the role with `%`. When `false` the plan fails for a `role` with a `host` other than `%`. Default: `false`",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			"host_match": schema.StringAttribute{
+				Description: "How host selects the account: exact uses the account with exactly that host, best_match treats host as " +
+					"the host name or IP address a client connects from and uses the account MySQL authenticates it as, e.g. 'u'@'10.%' " +
+					"before 'u'@'%'. With best_match a warning lists all accounts of the user that match, the accounts are read from " +
+					"mysql.user. Roles don't authenticate clients, for a role the host is always matched exactly. Default: exact",
+				MarkdownDescription: "How `host` selects the account: `exact` uses the account with exactly that host, `best_match` treats `host` as " +
+					"the host name or IP address a client connects from and uses the account MySQL authenticates it as, e.g. `'u'@'10.%'` " +
+					"before `'u'@'%'`. With `best_match` a warning lists all accounts of the user that match, the accounts are read from " +
+					"`mysql.user`. Roles don't authenticate clients, for a `role` the host is always matched exactly. Default: `exact`",
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(hostMatchExact),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.OneOf(hostMatchExact, hostMatchBest),
+				},
+			},
+			"matched_host": schema.StringAttribute{
+				Description: "The host of the account the privileges are granted to. With host_match = best_match it is resolved on " +
+					"every plan, the grant is recreated when another account of the user becomes the best match",
+				MarkdownDescription: "The host of the account the privileges are granted to. With `host_match = best_match` it is resolved on " +
+					"every plan, the grant is recreated when another account of the user becomes the best match",
+				Computed: true,
+			},
+			"planned_statements": schema.ListAttribute{
+				Description: "The REVOKE and GRANT statements the plan executes on the server, in order, so policies can inspect the exact SQL " +
+					"in the plan JSON before the apply is approved. Unknown when the plan depends on values known after apply, e.g. an account " +
+					"that doesn't exist yet, then set to the executed statements. Kept from the previous apply when the grant doesn't change",
+				MarkdownDescription: "The `REVOKE` and `GRANT` statements the plan executes on the server, in order, so policies can inspect the exact SQL " +
+					"in the plan JSON before the apply is approved. Unknown when the plan depends on values known after apply, e.g. an account " +
+					"that doesn't exist yet, then set to the executed statements. Kept from the previous apply when the grant doesn't change",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+			"grantee": schema.StringAttribute{
+				Description: "The account the privileges are granted to, quoted as in the GRANT statement, e.g. 'app'@'%'. " +
+					"Use it to refer to the account in SQL instead of building the string from the user and host",
+				MarkdownDescription: "The account the privileges are granted to, quoted as in the `GRANT` statement, e.g. `'app'@'%'`. " +
+					"Use it to refer to the account in SQL instead of building the string from the user and host",
+				CustomType: AccountType{},
+				Computed:   true,
+			},
+			"authoritative": schema.BoolAttribute{
+				Description: "When true the privileges are the only privileges of the user or role on the database, privileges granted " +
+					"outside of Terraform are revoked. Otherwise they are left alone. Default: false",
+				MarkdownDescription: "When `true` the privileges are the only privileges of the user or role on the database, privileges granted " +
+					"outside of Terraform are revoked. Otherwise they are left alone. Default: `false`",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			"enforce": schema.BoolAttribute{
+				Description: "When true drift on the server is corrected in place on the next apply: the privileges granted outside of " +
+					"Terraform are revoked like with authoritative, the missing privileges are granted again, also when the account lost all " +
+					"privileges on the database, and a grant option that doesn't match with_grant_option is granted or revoked without " +
+					"replacing the grant. The corrections show up in the plan. Default: false",
+				MarkdownDescription: "When `true` drift on the server is corrected in place on the next apply: the privileges granted outside of " +
+					"Terraform are revoked like with `authoritative`, the missing privileges are granted again, also when the account lost all " +
+					"privileges on the database, and a grant option that doesn't match `with_grant_option` is granted or revoked without " +
+					"replacing the grant. The corrections show up in the plan. Default: `false`",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			"adopt_existing": schema.BoolAttribute{
+				Description: "When true and the account already holds all privileges with the configured grant option when the grant is created, " +
+					"the existing grant is adopted into the state without granting it again. Default: false",
+				MarkdownDescription: "When `true` and the account already holds all privileges with the configured grant option when the grant is created, " +
+					"the existing grant is adopted into the state without granting it again. Default: `false`",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			"fail_on_conflict": schema.BoolAttribute{
+				Description: "When true creating the grant fails when the account already holds privileges on the database or its routines " +
+					"with a grant option that differs from with_grant_option, which the grant would leave in place. Checked when planning " +
+					"and again when applying. Otherwise, and with adopt_existing, a conflict is a warning. Default: false",
+				MarkdownDescription: "When `true` creating the grant fails when the account already holds privileges on the database or its routines " +
+					"with a grant option that differs from `with_grant_option`, which the grant would leave in place. Checked when planning " +
+					"and again when applying. Otherwise, and with `adopt_existing`, a conflict is a warning. Default: `false`",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			"include_global": schema.BoolAttribute{
+				Description: "When true the privileges granted on *.* are considered held on the database when the privileges are " +
+					"read, so a privilege granted globally doesn't show up as a change. The global privileges are not revoked, also " +
+					"not with authoritative. Only applies to the TABLE object type. Default: false",
+				MarkdownDescription: "When `true` the privileges granted on `*.*` are considered held on the database when the privileges are " +
+					"read, so a privilege granted globally doesn't show up as a change. The global privileges are not revoked, also " +
+					"not with `authoritative`. Only applies to the `TABLE` object type. Default: `false`",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			"object_type": schema.StringAttribute{
+				Description: "The objects of the database the privileges are granted on: TABLE for the database itself, FUNCTION or " +
+					"PROCEDURE for all routines of that type, or * for all routines. MySQL has no wildcard for routines, the privileges " +
+					"are granted on each existing routine and read back from their grants. Default: TABLE",
+				MarkdownDescription: "The objects of the database the privileges are granted on: `TABLE` for the database itself, `FUNCTION` or " +
+					"`PROCEDURE` for all routines of that type, or `*` for all routines. MySQL has no wildcard for routines, the privileges " +
+					"are granted on each existing routine and read back from their grants. Default: `TABLE`",
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString("TABLE"),
+				PlanModifiers: []planmodifier.String{
+					// Grants created before object_type existed are TABLE grants, they don't need to be recreated
+					stringplanmodifier.RequiresReplaceIf(func(_ context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+						resp.RequiresReplace = !req.StateValue.IsNull() || req.PlanValue.ValueString() != "TABLE"
+					}, "Changing the object type recreates the grant", "Changing the object type recreates the grant"),
+				},
+				Validators: []validator.String{
+					stringvalidator.OneOf("TABLE", "FUNCTION", "PROCEDURE", "*"),
+				},
+			},
+			"prevent_destroy_sql": preventDestroySQLAttribute(),
+			"labels":              labelsAttribute(),
+			"description": schema.StringAttribute{
+				Description: "Why the grant exists, e.g. the team or ticket that requested it. Only stored in the Terraform state, unless " +
+					"persist_description is set",
+				MarkdownDescription: "Why the grant exists, e.g. the team or ticket that requested it. Only stored in the Terraform state, unless " +
+					"`persist_description` is set",
+				Optional: true,
+			},
+			"persist_description": schema.BoolAttribute{
+				Description: "When true the description is also stored in the grant_descriptions key of the user attributes of the account, " +
+					"by database, so it's visible in INFORMATION_SCHEMA.USER_ATTRIBUTES. A description changed on the server shows up as a " +
+					"change. Requires MySQL 8.0.21 or later and the CREATE USER privilege. Default: false",
+				MarkdownDescription: "When `true` the description is also stored in the `grant_descriptions` key of the user attributes of the account, " +
+					"by database, so it's visible in `INFORMATION_SCHEMA.USER_ATTRIBUTES`. A description changed on the server shows up as a " +
+					"change. Requires MySQL 8.0.21 or later and the `CREATE USER` privilege. Default: `false`",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
 			},
 			"with_grant_option": schema.BoolAttribute{
+				Description: "When true the privileges are granted WITH GRANT OPTION. MySQL stores the grant option once per " +
+					"database or routine, not per privilege, a warning is shown when the grants on the server disagree with it. Changing it recreates the grant, " +
+					"unless enforce is set. Default: false",
+				MarkdownDescription: "When `true` the privileges are granted `WITH GRANT OPTION`. MySQL stores the grant option once per " +
+					"database or routine, not per privilege, a warning is shown when the grants on the server disagree with it. Changing it recreates the grant, " +
+					"unless `enforce` is set. Default: `false`",
 				Optional: true,
 				Computed: true,
 				Default:  booldefault.StaticBool(false),
 				PlanModifiers: []planmodifier.Bool{
-					boolplanmodifier.RequiresReplace(),
+					// With enforce the grant option is granted or revoked in place
+					boolplanmodifier.RequiresReplaceIf(func(ctx context.Context, req planmodifier.BoolRequest, resp *boolplanmodifier.RequiresReplaceIfFuncResponse) {
+						var enforce types.Bool
+						resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("enforce"), &enforce)...)
+						resp.RequiresReplace = !enforce.ValueBool()
+					}, "Changing the grant option recreates the grant unless enforce is set", "Changing the grant option recreates the grant unless `enforce` is set"),
 				},
 			},
 			"privileges": schema.SetAttribute{
+				Description: "The privileges managed by this resource. Only the configured privileges are stored, privileges granted " +
+					"outside of Terraform show up in privileges_effective. Either privileges with at least one privilege or preset must be set. " +
+					"GRANT OPTION is set with with_grant_option",
+				MarkdownDescription: "The privileges managed by this resource. Only the configured privileges are stored, privileges granted " +
+					"outside of Terraform show up in `privileges_effective`. Either `privileges` with at least one privilege or `preset` must be set. " +
+					"`GRANT OPTION` is set with `with_grant_option`",
+				ElementType: types.StringType,
+				Optional:    true,
+				Computed:    true,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					privilegesValidator{level: levelDatabase, levelName: "database", grantOptionAttribute: "with_grant_option"},
+				},
+			},
+			"preset": schema.StringAttribute{
+				Description: "A curated list of privileges maintained by the provider that is expanded into privileges: reader (" +
+					strings.Join(privilegePresets["reader"], ", ") + "), writer (" + strings.Join(privilegePresets["writer"], ", ") +
+					") or ddl_admin (" + strings.Join(privilegePresets["ddl_admin"], ", ") + "). The list can grow in new versions " +
+					"of the provider, the new privileges are granted on the next apply",
+				MarkdownDescription: "A curated list of privileges maintained by the provider that is expanded into `privileges`: `reader` (" +
+					strings.Join(privilegePresets["reader"], ", ") + "), `writer` (" + strings.Join(privilegePresets["writer"], ", ") +
+					") or `ddl_admin` (" + strings.Join(privilegePresets["ddl_admin"], ", ") + "). The list can grow in new versions " +
+					"of the provider, the new privileges are granted on the next apply",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(privilegePresetNames()...),
+				},
+			},
+			"privileges_effective": schema.SetAttribute{
+				Description: "All privileges of the user or role on the database as read from the server, including the privileges granted " +
+					"outside of Terraform. For routines the privileges held on every routine. With authoritative or enforce the privileges that " +
+					"are not configured are revoked, so after apply this equals privileges",
+				MarkdownDescription: "All privileges of the user or role on the database as read from the server, including the privileges granted " +
+					"outside of Terraform. For routines the privileges held on every routine. With `authoritative` or `enforce` the privileges that " +
+					"are not configured are revoked, so after apply this equals `privileges`",
 				ElementType: types.StringType,
-				Required:    true,
+				Computed:    true,
 			},
 		},
 	}
 }
 
 func (r *databaseGrantResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var plan databaseGrantResourceModel
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
@@ -99,27 +318,58 @@ func (r *databaseGrantResource) Create(ctx context.Context, req resource.CreateR
 		return
 	}
 
-	userOrRole, err := plan.userOrRole() //TODO fix this
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error in input values",
-			"No value for user nor role, unexpected error: "+err.Error(),
-		)
+	if plan.MatchedHost.IsUnknown() {
+		// The account didn't exist yet when planning
+		plan.MatchedHost, diags = r.matchedHost(ctx, &plan)
+		resp.Diagnostics.Append(diags...)
+		if userOrRole, _ := plan.userOrRole(); plan.MatchedHost.IsUnknown() {
+			resp.Diagnostics.AddAttributeError(path.Root("host"), "No matching account",
+				"No account of "+userOrRole+" matches the host "+plan.Host.ValueString()+", create the account first")
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	plan.Grantee = plan.grantee(r.config)
+
+	toGrant := plan.privilegesAsString()
+	if plan.checksExistingGrant() {
+		adopt, conflict, err := r.checkExistingGrant(ctx, &plan)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error granting database permissions",
+				"Unable to read the existing grants of "+plan.Grantee.ValueString()+", unexpected error: "+err.Error(),
+			)
+			return
+		}
+		if conflict != "" && plan.FailOnConflict.ValueBool() {
+			resp.Diagnostics.AddError("Conflicting existing grant", conflict)
+			return
+		}
+		if conflict != "" {
+			resp.Diagnostics.AddWarning("Conflicting existing grant", conflict+". The grant option on the server is left as it is")
+		}
+		if adopt && plan.AdoptExisting.ValueBool() {
+			tflog.Info(ctx, "Adopting the existing grant of "+plan.Grantee.ValueString()+" on "+plan.databaseAsString())
+			toGrant = nil
+		}
+	}
+
+	resp.Diagnostics.Append(r.revokeAndGrant(ctx, &plan, "Error granting database permissions", nil, toGrant)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	sqlStatement := fmt.Sprintf("GRANT %s ON %s.* TO %s@'%s'", strings.Join(plan.privilegesAsString(), ", "),
-		plan.databaseAsString(), userOrRole, plan.hostAsString())
-	if plan.withGrantOption() {
-		sqlStatement = sqlStatement + " WITH GRANT OPTION"
+
+	if plan.PersistDescription.ValueBool() {
+		resp.Diagnostics.Append(r.persistDescription(ctx, &plan, plan.Description)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
 	}
-	tflog.Debug(ctx, fmt.Sprintf("SQL Statement: \"%s\"", sqlStatement))
 
-	_, err = r.db.ExecContext(ctx, sqlStatement)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error granting database permissions",
-			"Unable to grant permissions to "+userOrRole+", unexpected error: "+err.Error(),
-		)
+	// The privileges granted outside of Terraform are read on the next refresh
+	resp.Diagnostics.Append(plan.setEffectivePrivileges(ctx, plan.privilegesAsString())...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
@@ -132,6 +382,9 @@ func (r *databaseGrantResource) Create(ctx context.Context, req resource.CreateR
 }
 
 func (r *databaseGrantResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withReadTimeout(ctx)
+
 	var state databaseGrantResourceModel
 
 	diags := req.State.Get(ctx, &state)
@@ -148,43 +401,103 @@ func (r *databaseGrantResource) Read(ctx context.Context, req resource.ReadReque
 		)
 		return
 	}
-	var row dbRow
-	err = r.db.QueryRowContext(ctx, "SELECT "+
-		"Host,Db,User,Select_priv,Insert_priv,Update_priv,Delete_priv,Create_priv,Drop_priv,Grant_priv,References_priv,"+
-		"Index_priv,Alter_priv,Create_tmp_table_priv,Lock_tables_priv,Create_view_priv,Show_view_priv,Create_routine_priv,"+
-		"Alter_routine_priv,Execute_priv,Event_priv,Trigger_priv"+
-		" FROM mysql.db WHERE Host = ? AND User = ? AND Db = ?",
-		state.hostAsString(),
-		userOrRole,
-		state.databaseAsString()).Scan(&row.Host,
-		&row.Db, &row.User, &row.SelectPriv, &row.InsertPriv, &row.UpdatePriv, &row.DeletePriv,
-		&row.CreatePriv, &row.DropPriv, &row.GrantPriv, &row.ReferencesPriv, &row.IndexPriv, &row.AlterPriv,
-		&row.CreateTmpTablePriv, &row.LockTablesPriv, &row.CreateViewPriv, &row.ShowViewPriv, &row.CreateRoutinePriv,
-		&row.AlterRoutinePriv, &row.ExecutePriv, &row.EventPriv, &row.TriggerPriv)
 
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error reading database privileges data",
-			"Unable to read data from the database privileges table, unexpected error: "+err.Error(),
-		)
-		return
+	// Grants created before host_match existed are on the account with exactly the host
+	if state.HostMatch.IsNull() {
+		state.HostMatch = types.StringValue(hostMatchExact)
+	}
+	if state.MatchedHost.IsNull() {
+		state.MatchedHost = state.Host
 	}
-	var privileges []types.String
-	for _, rowPermission := range row.allPrivilegesStringValues() {
-		found := false
-		for _, statePermission := range state.Privileges {
-			if strings.EqualFold(statePermission.ValueString(), rowPermission.ValueString()) {
-				privileges = append(privileges, statePermission)
-				found = true
-				break
+	state.Grantee = state.grantee(r.config)
+
+	if objectTypes := state.routineObjectTypes(); objectTypes != nil {
+		routines, err := readRoutineGrants(ctx, r.db, r.config, userOrRole, state.hostAsString(), state.databaseAsString(), objectTypes)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading database privileges data",
+				"Unable to read the routine grants of "+userOrRole+", unexpected error: "+err.Error(),
+			)
+			return
+		}
+		// Without routines there is nothing to compare, the state is kept until routines are created
+		if len(routines) > 0 {
+			privileges, withGrantOption, granted := aggregateRoutinePrivileges(routines, r.config)
+			if with, without := splitRoutineGrantOption(routines); len(with) > 0 && len(without) > 0 {
+				// Either value of the flag disagrees with part of the routines, flipping it replaces the grant
+				// so all routines end up with the same grant option, with enforce it is updated in place
+				withGrantOption = !state.withGrantOption()
+				correction := "The grant is replaced to apply with_grant_option to all routines"
+				if state.Enforce.ValueBool() {
+					correction = "The next apply grants or revokes the grant option to apply with_grant_option to all routines"
+				}
+				resp.Diagnostics.AddWarning(
+					"Inconsistent grant option",
+					"The grant option of "+userOrRole+" differs between the routines of database "+state.databaseAsString()+
+						", with: "+strings.Join(with, ", ")+", without: "+strings.Join(without, ", ")+". "+correction,
+				)
 			}
+			privileges = r.config.expandAllPrivileges(state.privilegesAsString(), privileges, levelRoutine)
+			resp.Diagnostics.Append(state.setServerPrivileges(ctx, privileges, nil, withGrantOption, granted)...)
+		}
+	} else {
+		grants, err := r.config.readGrants(ctx, r.db, userOrRole, state.hostAsString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading database privileges data",
+				"Unable to read the grants of "+userOrRole+", unexpected error: "+err.Error(),
+			)
+			return
+		}
+		var global []string
+		if state.IncludeGlobal.ValueBool() {
+			global = r.config.expandAllPrivileges(state.privilegesAsString(), globalDatabasePrivileges(grants, r.config, state.databaseAsString()), levelDatabase)
+		}
+		grant := findDatabaseGrant(grants, r.config, state.databaseAsString())
+		switch {
+		case grant != nil:
+			privileges := r.config.expandAllPrivileges(state.privilegesAsString(), grant.PrivilegeNames(), levelDatabase)
+			resp.Diagnostics.Append(state.setServerPrivileges(ctx, privileges, global, grant.WithGrantOption, true)...)
+			resp.Diagnostics.Append(r.grantOptionWarnings(&state, userOrRole, grants, grant)...)
+		case len(global) > 0:
+			// All managed privileges can be held globally, there is no grant option on the database to read
+			resp.Diagnostics.Append(state.setServerPrivileges(ctx, nil, global, false, false)...)
+		case state.Enforce.ValueBool():
+			// Without privileges in the state all of them are granted again on the next apply
+			resp.Diagnostics.AddWarning(
+				"Database privileges revoked outside of Terraform",
+				"No privileges found for "+userOrRole+" on database "+state.databaseAsString()+", they are granted again on the next apply",
+			)
+			resp.Diagnostics.Append(state.setServerPrivileges(ctx, nil, nil, false, false)...)
+		default:
+			resp.Diagnostics.AddError(
+				"Error reading database privileges data",
+				"No privileges found for "+userOrRole+" on database "+state.databaseAsString(),
+			)
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(r.verifyPrincipalKind(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.PersistDescription.ValueBool() {
+		descriptions, err := r.config.readGrantDescriptions(ctx, r.db, userOrRole, state.hostAsString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading database privileges data",
+				"Unable to read the grant descriptions of "+userOrRole+", unexpected error: "+err.Error(),
+			)
+			return
 		}
-		if !found {
-			privileges = append(privileges, rowPermission)
+		state.Description = types.StringNull()
+		if description, ok := descriptions[state.descriptionKey()]; ok {
+			state.Description = types.StringValue(description)
 		}
 	}
-	state.Privileges = privileges
-	state.WithGrantOption = row.withGrantOption()
+
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -192,11 +505,71 @@ func (r *databaseGrantResource) Read(ctx context.Context, req resource.ReadReque
 	}
 }
 
-func (r *databaseGrantResource) Update(_ context.Context, _ resource.UpdateRequest, _ *resource.UpdateResponse) {
-	// No updates possible, needs to recreate
+func (r *databaseGrantResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan, state databaseGrantResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	stateEffective, diags := state.effectivePrivileges(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	toRevoke, toGrant := plan.privilegeChanges(&state, stateEffective)
+	resp.Diagnostics.Append(r.revokeAndGrant(ctx, &plan, "Error updating database permissions", toRevoke, toGrant)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Turning persist_description off removes the description from the user attributes
+	if !plan.PersistDescription.Equal(state.PersistDescription) || (plan.PersistDescription.ValueBool() && !plan.Description.Equal(state.Description)) {
+		description := types.StringNull()
+		if plan.PersistDescription.ValueBool() {
+			description = plan.Description
+		}
+		resp.Diagnostics.Append(r.persistDescription(ctx, &plan, description)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	effective := plan.privilegesAsString()
+	if !plan.revokesUnmanaged() {
+		effective = uniquePrivileges(append(withoutPrivileges(stateEffective, toRevoke), effective...))
+	}
+	resp.Diagnostics.Append(plan.setEffectivePrivileges(ctx, effective)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
 func (r *databaseGrantResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var state databaseGrantResourceModel
 
 	diags := req.State.Get(ctx, &state)
@@ -205,26 +578,256 @@ func (r *databaseGrantResource) Delete(ctx context.Context, req resource.DeleteR
 		return
 	}
 
-	userOrRole, err := state.userOrRole()
+	resp.Diagnostics.Append(checkPreventDestroySQL(ctx, r.db, state.PreventDestroySQL)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The user or role can be destroyed before its grants, its privileges went with it
+	if userOrRole, err := state.userOrRole(); err == nil && r.config.accountDropped(ctx, r.db, userOrRole, state.hostAsString()) {
+		tflog.Debug(ctx, "Skipping the revoke, "+r.config.quoteAccount(userOrRole, state.hostAsString())+" no longer exists")
+		return
+	}
+
+	// Revoking only the privileges would leave the grant option behind
+	toRevoke := state.privilegesAsString()
+	if state.withGrantOption() {
+		toRevoke = append(toRevoke, grantOptionPrivilege)
+	}
+	resp.Diagnostics.Append(r.revokeAndGrant(ctx, &state, "Error removing grant database permissions", toRevoke, nil)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.PersistDescription.ValueBool() {
+		resp.Diagnostics.Append(r.persistDescription(ctx, &state, types.StringNull())...)
+	}
+}
+
+// revokeAndGrant revokes and then grants the privileges with the statements of grantStatements. When planned_statements
+// is unknown it is set to the executed statements.
+func (r *databaseGrantResource) revokeAndGrant(ctx context.Context, m *databaseGrantResourceModel, summary string, toRevoke, toGrant []string) diag.Diagnostics {
+	statements, diags := r.grantStatements(ctx, m, summary, toRevoke, toGrant)
+	if diags.HasError() {
+		return diags
+	}
+
+	userOrRole, _ := m.userOrRole()
+	for _, statement := range statements {
+		tflog.Debug(ctx, fmt.Sprintf("SQL Statement: \"%s\"", statement.sql))
+		_, err := execContext(ctx, r.db, statement.sql)
+		if err != nil && statement.grant {
+			diags.AddError(
+				summary,
+				"Unable to grant permissions to "+userOrRole+", unexpected error: "+err.Error(),
+			)
+			return diags
+		}
+		if err != nil {
+			diags.AddError(
+				summary,
+				"Unable to revoke permissions from "+userOrRole+", unexpected error: "+err.Error(),
+			)
+			return diags
+		}
+	}
+
+	if m.PlannedStatements.IsUnknown() {
+		diags.Append(m.setPlannedStatements(ctx, statements)...)
+	}
+
+	if r.config.verifyAfterApply && (len(toRevoke) > 0 || len(toGrant) > 0) {
+		if err := r.verifyApplied(ctx, m, userOrRole, toRevoke, toGrant); err != nil {
+			diags.AddError(summary, "Unable to verify the grants of "+userOrRole+": "+err.Error())
+		}
+	}
+	return diags
+}
+
+// grantStatement is a REVOKE or GRANT statement of grantStatements.
+type grantStatement struct {
+	sql   string
+	grant bool
+}
+
+// setPlannedStatements sets planned_statements to the SQL of the statements.
+func (m *databaseGrantResourceModel) setPlannedStatements(ctx context.Context, statements []grantStatement) diag.Diagnostics {
+	sqlStatements := []string{}
+	for _, statement := range statements {
+		sqlStatements = append(sqlStatements, statement.sql)
+	}
+	var diags diag.Diagnostics
+	m.PlannedStatements, diags = types.ListValueFrom(ctx, types.StringType, sqlStatements)
+	return diags
+}
+
+// grantStatements returns the statements that revoke and then grant the privileges on the database, or on each
+// routine of the object type. Privileges are only revoked from routines that have them, MySQL fails to revoke
+// privileges that aren't granted.
+func (r *databaseGrantResource) grantStatements(ctx context.Context, m *databaseGrantResourceModel, summary string, toRevoke, toGrant []string) ([]grantStatement, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	userOrRole, err := m.userOrRole()
 	if err != nil {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Error in input values",
 			"No value for user nor role, unexpected error: "+err.Error(),
 		)
-		return
+		return nil, diags
+	}
+	account := r.config.quoteAccount(userOrRole, m.hostAsString())
+
+	type target struct {
+		level      string
+		privileges []string // The privileges on the server, nil when not read
+	}
+	targets := []target{{level: sqlgen.DatabaseLevel(m.databaseAsString())}}
+	if m.IncludeGlobal.ValueBool() && m.routineObjectTypes() == nil && len(toRevoke) > 0 {
+		// Managed privileges can be held on *.* only, MySQL fails to revoke them from the database
+		grant, err := readDatabaseGrant(ctx, r.db, r.config, userOrRole, m.hostAsString(), m.databaseAsString())
+		if err != nil {
+			diags.AddError(
+				summary,
+				"Unable to read the grants of "+userOrRole+", unexpected error: "+err.Error(),
+			)
+			return nil, diags
+		}
+		targets[0].privileges = []string{}
+		if grant != nil {
+			targets[0].privileges = r.config.expandAllPrivileges(toRevoke, grant.PrivilegeNames(), levelDatabase)
+			if grant.WithGrantOption {
+				targets[0].privileges = append(targets[0].privileges, grantOptionPrivilege)
+			}
+		}
+	}
+	if objectTypes := m.routineObjectTypes(); objectTypes != nil {
+		routines, err := readRoutineGrants(ctx, r.db, r.config, userOrRole, m.hostAsString(), m.databaseAsString(), objectTypes)
+		if err != nil {
+			diags.AddError(
+				summary,
+				"Unable to read the routines of database "+m.databaseAsString()+", unexpected error: "+err.Error(),
+			)
+			return nil, diags
+		}
+		if len(routines) == 0 && len(toGrant) > 0 {
+			diags.AddWarning(
+				"No routines found",
+				"Database "+m.databaseAsString()+" has no routines of object type "+m.ObjectType.ValueString()+
+					", the privileges are granted on the routines created later when the resource is applied again",
+			)
+		}
+		targets = nil
+		for _, routine := range routines {
+			privileges := []string{}
+			if routine.Grant != nil {
+				privileges = r.config.expandAllPrivileges(toRevoke, routine.Grant.PrivilegeNames(), levelRoutine)
+				if routine.Grant.WithGrantOption {
+					privileges = append(privileges, grantOptionPrivilege)
+				}
+			}
+			targets = append(targets, target{level: routine.target(m.databaseAsString()), privileges: privileges})
+		}
+	}
+
+	var statements []grantStatement
+	for _, target := range targets {
+		privileges := toRevoke
+		if target.privileges != nil {
+			privileges = privilegesIntersection(toRevoke, target.privileges)
+		}
+		if len(privileges) == 0 {
+			continue
+		}
+		statements = append(statements, grantStatement{sql: sqlgen.Revoke(privileges, target.level, account)})
 	}
-	sqlStatement := fmt.Sprintf("REVOKE %s ON %s.* FROM %s@'%s'", strings.Join(state.privilegesAsString(), ", "), state.databaseAsString(), userOrRole, state.hostAsString())
-	_, err = r.db.ExecContext(ctx, sqlStatement)
+
+	if len(toGrant) > 0 {
+		for _, target := range targets {
+			statements = append(statements, grantStatement{sql: sqlgen.Grant(toGrant, target.level, account, m.withGrantOption()), grant: true})
+		}
+	}
+	return statements, diags
+}
+
+// checkExistingGrant compares the privileges the account already holds on the database, or on each routine of the
+// object type, with the grant before it is created. adopt is true when all levels hold the privileges with the
+// configured grant option, conflict explains the levels that hold privileges with another grant option.
+func (r *databaseGrantResource) checkExistingGrant(ctx context.Context, m *databaseGrantResourceModel) (adopt bool, conflict string, err error) {
+	userOrRole, err := m.userOrRole()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error removing grant database permissions",
-			"Unable to remove grant permissions from "+userOrRole+", unexpected error: "+err.Error(),
-		)
-		return
+		return false, "", err
+	}
+
+	type existingGrant struct {
+		level string
+		grant *grantparser.Grant // nil when the account has no privileges on the level
+	}
+	var existing []existingGrant
+	if objectTypes := m.routineObjectTypes(); objectTypes != nil {
+		routines, err := readRoutineGrants(ctx, r.db, r.config, userOrRole, m.hostAsString(), m.databaseAsString(), objectTypes)
+		if err != nil {
+			return false, "", err
+		}
+		for _, routine := range routines {
+			existing = append(existing, existingGrant{level: routine.target(m.databaseAsString()), grant: routine.Grant})
+		}
+	} else {
+		grant, err := readDatabaseGrant(ctx, r.db, r.config, userOrRole, m.hostAsString(), m.databaseAsString())
+		if err != nil {
+			return false, "", err
+		}
+		existing = append(existing, existingGrant{level: sqlgen.DatabaseLevel(m.databaseAsString()), grant: grant})
+	}
+
+	withGrantOption := m.withGrantOption()
+	adopt = len(existing) > 0
+	var conflicts []string
+	for _, e := range existing {
+		if e.grant == nil {
+			adopt = false
+			continue
+		}
+		if e.grant.WithGrantOption != withGrantOption {
+			conflicts = append(conflicts, e.level)
+		}
+		if len(grantDifferences(e.grant, m.privilegesAsString(), nil, withGrantOption)) > 0 {
+			adopt = false
+		}
+	}
+	if len(conflicts) == 0 {
+		return adopt, "", nil
+	}
+
+	held := "with"
+	if withGrantOption {
+		held = "without"
+	}
+	return false, fmt.Sprintf("%s already holds privileges %s the grant option on %s, with_grant_option is %t. MySQL stores the "+
+		"grant option once per level, revoke the existing privileges or change with_grant_option",
+		r.config.quoteAccount(userOrRole, m.hostAsString()), held, strings.Join(conflicts, ", "), withGrantOption), nil
+}
+
+// verifyApplied reads the grants back after revokeAndGrant with verify_after_apply, an error lists the differences
+// with the privileges that were revoked and granted.
+func (r *databaseGrantResource) verifyApplied(ctx context.Context, m *databaseGrantResourceModel, userOrRole string, toRevoke, toGrant []string) error {
+	objectTypes := m.routineObjectTypes()
+	if objectTypes == nil {
+		return verifyDatabaseGrant(ctx, r.db, r.config, userOrRole, m.hostAsString(), m.databaseAsString(), toGrant, toRevoke, m.withGrantOption())
+	}
+
+	routines, err := readRoutineGrants(ctx, r.db, r.config, userOrRole, m.hostAsString(), m.databaseAsString(), objectTypes)
+	if err != nil {
+		return fmt.Errorf("reading the grants back: %w", err)
+	}
+	for _, routine := range routines {
+		if differences := grantDifferences(routine.Grant, toGrant, toRevoke, m.withGrantOption()); len(differences) > 0 {
+			return verifyError(r.config.quoteAccount(userOrRole, m.hostAsString()), routine.target(m.databaseAsString()), differences)
+		}
 	}
+	return nil
 }
 
-func (r *databaseGrantResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+func (r *databaseGrantResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
 	}
@@ -238,7 +841,7 @@ func (r *databaseGrantResource) Configure(_ context.Context, req resource.Config
 		return
 	}
 
-	db, err := config.connectToMySQLNoDb() // Not connecting to a specific database
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to connect to the Cloud SQL MySQL instance",
@@ -247,7 +850,17 @@ func (r *databaseGrantResource) Configure(_ context.Context, req resource.Config
 		return
 	}
 
+	err = config.detectServerSettings(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read the Cloud SQL MySQL server settings",
+			err.Error(),
+		)
+		return
+	}
+
 	r.db = db
+	r.config = config
 }
 
 func (r *databaseGrantResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
@@ -260,16 +873,272 @@ func (r *databaseGrantResource) ConfigValidators(ctx context.Context) []resource
 			path.MatchRoot("user"),
 			path.MatchRoot("role"),
 		),
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("privileges"),
+			path.MatchRoot("preset"),
+		),
+	}
+}
+
+func (r *databaseGrantResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	// The preset is expanded on every plan, the privileges revoked outside of Terraform and the privileges added to
+	// the preset by a new provider version show up as a change.
+	var preset types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("preset"), &preset)...)
+	if resp.Diagnostics.HasError() || preset.IsUnknown() {
+		return
+	}
+	if !preset.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("privileges"), privilegePresets[preset.ValueString()])...)
+	}
+	if r.config == nil {
+		return
+	}
+
+	// A set of privileges computed from other resources is unknown as a whole, it can't be decoded into the model.
+	var privileges types.Set
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("privileges"), &privileges)...)
+	if resp.Diagnostics.HasError() || privileges.IsUnknown() {
+		return
+	}
+
+	var plan databaseGrantResourceModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Privileges from values unknown at validation time can still turn out empty, MySQL fails on GRANT  ON.
+	if len(plan.Privileges) == 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("privileges"), "No privileges",
+			"At least one privilege is required, an empty set can't be granted")
+	}
+	if plan.PersistDescription.ValueBool() && r.config.serverVersion.less(userAttributesVersion) {
+		resp.Diagnostics.AddAttributeError(path.Root("persist_description"), "User attributes not supported by the server version",
+			fmt.Sprintf("persist_description stores the description with ALTER USER ... ATTRIBUTE, which requires MySQL %s or later, "+
+				"the server runs MySQL %s", userAttributesVersion, r.config.serverVersion))
+	}
+	if !plan.Database.IsUnknown() {
+		if message := r.config.systemSchemaError(plan.databaseAsString()); message != "" {
+			resp.Diagnostics.AddAttributeError(path.Root("database"), "System schema not allowed", message)
+		}
+	}
+	if !plan.User.IsUnknown() && !plan.Role.IsUnknown() && !plan.Host.IsUnknown() {
+		if userOrRole, err := plan.userOrRole(); err == nil {
+			if message := r.config.anonymousAccountError(userOrRole, plan.Host.ValueString()); message != "" {
+				resp.Diagnostics.AddError("Anonymous account not allowed", message)
+			}
+		}
+	}
+	if !plan.Role.IsNull() && !plan.Host.IsUnknown() && plan.Host.ValueString() != "%" && !plan.AllowRoleHost.ValueBool() {
+		resp.Diagnostics.AddAttributeError(path.Root("host"), "Host not allowed for roles",
+			"Roles are created with the host % unless a host is given, the privileges would be granted to "+
+				r.config.quoteAccount(plan.Role.ValueString(), plan.Host.ValueString())+" instead. Remove host, or set allow_role_host = true "+
+				"when the role was created with this host")
+	}
+	if plan.HostMatch.ValueString() == hostMatchBest && r.config.readSource == readSourceInformationSchema {
+		resp.Diagnostics.AddAttributeError(path.Root("host_match"), "host_match not supported by read_source",
+			"best_match reads the accounts from mysql.user, which read_source = information_schema doesn't read. Use host_match = exact")
+	} else if !plan.User.IsUnknown() && !plan.Role.IsUnknown() && !plan.Host.IsUnknown() && !plan.HostMatch.IsUnknown() {
+		matchedHost, diags := r.matchedHost(ctx, &plan)
+		resp.Diagnostics.Append(diags...)
+		if !matchedHost.IsUnknown() {
+			plan.MatchedHost = matchedHost
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("matched_host"), matchedHost)...)
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("grantee"), plan.grantee(r.config))...)
+			var stateMatchedHost types.String
+			if !req.State.Raw.IsNull() {
+				resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("matched_host"), &stateMatchedHost)...)
+			}
+			if !stateMatchedHost.IsNull() && !stateMatchedHost.Equal(matchedHost) {
+				resp.RequiresReplace = append(resp.RequiresReplace, path.Root("matched_host"))
+			}
+		}
+	}
+
+	// Authoritative and enforced grants revoke everything else, the effective privileges after apply are the configured ones.
+	// Privileges granted outside of Terraform make the plan differ from the state, so they are revoked.
+	privilegesKnown := true
+	for _, privilege := range plan.Privileges {
+		privilegesKnown = privilegesKnown && !privilege.IsUnknown()
+	}
+	if plan.revokesUnmanaged() && privilegesKnown {
+		resp.Diagnostics.Append(plan.setEffectivePrivileges(ctx, plan.privilegesAsString())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("privileges_effective"), plan.PrivilegesEffective)...)
+	}
+
+	routines := !plan.ObjectType.IsUnknown() && plan.routineObjectTypes() != nil
+	for _, privilege := range plan.Privileges {
+		if privilege.IsUnknown() {
+			continue
+		}
+		if message := r.config.unknownPrivilegeError(privilege.ValueString()); message != "" {
+			resp.Diagnostics.AddAttributeError(path.Root("privileges"), "Invalid privilege", message)
+			continue
+		}
+		if routines && knownPrivilege(privilege.ValueString()) {
+			if message := privilegeLevelError(privilege.ValueString(), levelRoutine, "routine"); message != "" {
+				resp.Diagnostics.AddAttributeError(path.Root("privileges"), "Privilege not supported on routines", message)
+			}
+		}
+		if message := privilegeVersionError(privilege.ValueString(), r.config.serverVersion); message != "" {
+			resp.Diagnostics.AddAttributeError(path.Root("privileges"), "Privilege not supported by the server version", message)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.planStatements(ctx, req, resp)...)
+}
+
+// planStatements sets planned_statements to the statements the apply executes. Without changes the statements of the
+// previous apply are kept, so they don't show up as a change on every plan.
+func (r *databaseGrantResource) planStatements(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var state *databaseGrantResourceModel
+	if !req.State.Raw.IsNull() {
+		state = &databaseGrantResourceModel{}
+		diags.Append(req.State.Get(ctx, state)...)
+		diags.Append(resp.Plan.SetAttribute(ctx, path.Root("planned_statements"), state.PlannedStatements)...)
+		if diags.HasError() || resp.Plan.Raw.Equal(req.State.Raw) {
+			return diags
+		}
+	}
+
+	var plan databaseGrantResourceModel
+	diags.Append(resp.Plan.Get(ctx, &plan)...)
+	if diags.HasError() {
+		return diags
+	}
+	plan.PlannedStatements = types.ListUnknown(types.StringType)
+	if plan.statementsKnown() {
+		toGrant := plan.privilegesAsString()
+		var toRevoke []string
+		if (state == nil || plan.replacedFrom(state)) && plan.checksExistingGrant() {
+			adopt, conflict, err := r.checkExistingGrant(ctx, &plan)
+			switch {
+			case err != nil:
+				// E.g. the account is created in the same apply, Create checks the grants again
+				tflog.Debug(ctx, "Unable to read the existing grants: "+err.Error())
+				return diags
+			case conflict != "" && plan.FailOnConflict.ValueBool():
+				diags.AddError("Conflicting existing grant", conflict)
+				return diags
+			case adopt && plan.AdoptExisting.ValueBool():
+				toGrant = nil
+			}
+		}
+		if state != nil && !plan.replacedFrom(state) {
+			stateEffective, effectiveDiags := state.effectivePrivileges(ctx)
+			diags.Append(effectiveDiags...)
+			if diags.HasError() {
+				return diags
+			}
+			toRevoke, toGrant = plan.privilegeChanges(state, stateEffective)
+		}
+
+		statements, statementDiags := r.grantStatements(ctx, &plan, "Error planning the statements", toRevoke, toGrant)
+		if statementDiags.HasError() {
+			// E.g. the account is created in the same apply, the statements are known after apply
+			tflog.Debug(ctx, fmt.Sprintf("Unable to plan the statements: %v", statementDiags.Errors()))
+		} else {
+			diags.Append(plan.setPlannedStatements(ctx, statements)...)
+		}
 	}
+	diags.Append(resp.Plan.SetAttribute(ctx, path.Root("planned_statements"), plan.PlannedStatements)...)
+	return diags
 }
 
 type databaseGrantResourceModel struct {
-	Database        types.String   `tfsdk:"database"`
-	User            types.String   `tfsdk:"user"`
-	Role            types.String   `tfsdk:"role"`
-	Host            types.String   `tfsdk:"host"`
-	Privileges      []types.String `tfsdk:"privileges"`
-	WithGrantOption types.Bool     `tfsdk:"with_grant_option"`
+	Database types.String `tfsdk:"database"`
+	User     types.String `tfsdk:"user"`
+	Role     types.String `tfsdk:"role"`
+	Host     types.String `tfsdk:"host"`
+	// AllowRoleHost allows a Role with a Host other than %.
+	AllowRoleHost types.Bool `tfsdk:"allow_role_host"`
+	// HostMatch decides how Host selects the account, MatchedHost is the host of the selected account.
+	HostMatch   types.String `tfsdk:"host_match"`
+	MatchedHost types.String `tfsdk:"matched_host"`
+	// Grantee is the quoted account of MatchedHost.
+	Grantee    AccountValue   `tfsdk:"grantee"`
+	Privileges []types.String `tfsdk:"privileges"`
+	// Preset is expanded into Privileges in ModifyPlan.
+	Preset          types.String `tfsdk:"preset"`
+	WithGrantOption types.Bool   `tfsdk:"with_grant_option"`
+	// Authoritative revokes the privileges granted outside of Terraform.
+	Authoritative types.Bool `tfsdk:"authoritative"`
+	// Enforce corrects all drift on the server in place, including the grant option.
+	Enforce types.Bool `tfsdk:"enforce"`
+	// AdoptExisting and FailOnConflict decide what Create does with the privileges the account already holds.
+	AdoptExisting  types.Bool `tfsdk:"adopt_existing"`
+	FailOnConflict types.Bool `tfsdk:"fail_on_conflict"`
+	// IncludeGlobal considers the privileges on *.* held on the database.
+	IncludeGlobal types.Bool   `tfsdk:"include_global"`
+	ObjectType    types.String `tfsdk:"object_type"`
+	// PreventDestroySQL is checked before the grant is revoked on destroy.
+	PreventDestroySQL types.String `tfsdk:"prevent_destroy_sql"`
+	// Labels have no effect on the server, they group the grant in cloudsqlmysql_access_report.
+	Labels types.Map `tfsdk:"labels"`
+	// Description is only written to the user attributes with PersistDescription.
+	Description        types.String `tfsdk:"description"`
+	PersistDescription types.Bool   `tfsdk:"persist_description"`
+	// PrivilegesEffective are all privileges on the server, Privileges only holds the configured ones.
+	PrivilegesEffective types.Set `tfsdk:"privileges_effective"`
+	// PlannedStatements are the statements of grantStatements computed when planning.
+	PlannedStatements types.List `tfsdk:"planned_statements"`
+}
+
+// checksExistingGrant checks if Create compares the grant with the privileges the account already holds.
+func (m *databaseGrantResourceModel) checksExistingGrant() bool {
+	return m.AdoptExisting.ValueBool() || m.FailOnConflict.ValueBool()
+}
+
+// privilegeChanges returns the privileges to revoke and to grant to update the grant from the state to m.
+func (m *databaseGrantResourceModel) privilegeChanges(state *databaseGrantResourceModel, stateEffective []string) (toRevoke, toGrant []string) {
+	toRevoke = privilegesDifference(state.Privileges, m.Privileges)
+	if m.revokesUnmanaged() {
+		// The privileges granted outside of Terraform are revoked too
+		toRevoke = uniquePrivileges(append(toRevoke, withoutPrivileges(stateEffective, m.privilegesAsString())...))
+	}
+	toGrant = privilegesDifference(m.Privileges, state.Privileges)
+	if !m.WithGrantOption.Equal(state.WithGrantOption) {
+		// Only reached with enforce, otherwise changing with_grant_option replaces the grant
+		if m.withGrantOption() {
+			// Granting any privilege WITH GRANT OPTION sets the grant option on the level
+			toGrant = m.privilegesAsString()
+		} else {
+			toRevoke = append(toRevoke, grantOptionPrivilege)
+		}
+	}
+	return toRevoke, toGrant
+}
+
+// replacedFrom checks if going from the state to m replaces the grant. It mirrors the RequiresReplace plan modifiers of
+// the schema, ModifyPlan of the resource doesn't see their result.
+func (m *databaseGrantResourceModel) replacedFrom(state *databaseGrantResourceModel) bool {
+	objectTypeChanged := !m.ObjectType.Equal(state.ObjectType) && (!state.ObjectType.IsNull() || m.ObjectType.ValueString() != "TABLE")
+	grantOptionChanged := !m.WithGrantOption.Equal(state.WithGrantOption) && !m.Enforce.ValueBool()
+	matchedHostChanged := !state.MatchedHost.IsNull() && !m.MatchedHost.Equal(state.MatchedHost)
+	return !m.Database.Equal(state.Database) || !m.User.Equal(state.User) || !m.Role.Equal(state.Role) || !m.Host.Equal(state.Host) ||
+		!m.HostMatch.Equal(state.HostMatch) || objectTypeChanged || grantOptionChanged || matchedHostChanged
+}
+
+// statementsKnown checks if the attributes the statements of grantStatements depend on are known.
+func (m *databaseGrantResourceModel) statementsKnown() bool {
+	for _, privilege := range m.Privileges {
+		if privilege.IsUnknown() {
+			return false
+		}
+	}
+	return !m.Database.IsUnknown() && !m.User.IsUnknown() && !m.Role.IsUnknown() && !m.MatchedHost.IsUnknown() &&
+		!m.WithGrantOption.IsUnknown() && !m.ObjectType.IsUnknown() && !m.IncludeGlobal.IsUnknown() &&
+		!m.Authoritative.IsUnknown() && !m.Enforce.IsUnknown()
 }
 
 func (m *databaseGrantResourceModel) privilegesAsString() []string {
@@ -284,10 +1153,24 @@ func (m *databaseGrantResourceModel) databaseAsString() string {
 	return m.Database.ValueString()
 }
 
+// hostAsString returns the host of the account the privileges are granted to, with host_match = best_match that is
+// the matched host.
 func (m *databaseGrantResourceModel) hostAsString() string {
+	if !m.MatchedHost.IsNull() && !m.MatchedHost.IsUnknown() {
+		return m.MatchedHost.ValueString()
+	}
 	return m.Host.ValueString()
 }
 
+// grantee returns the quoted account the privileges are granted to, unknown until the matched host is known.
+func (m *databaseGrantResourceModel) grantee(config *Config) AccountValue {
+	userOrRole, err := m.userOrRole()
+	if err != nil || m.User.IsUnknown() || m.Role.IsUnknown() || m.MatchedHost.IsUnknown() {
+		return AccountValue{StringValue: types.StringUnknown()}
+	}
+	return config.newAccountValue(userOrRole, m.hostAsString())
+}
+
 func (m *databaseGrantResourceModel) userOrRole() (string, error) {
 	if m.User.IsNull() && m.Role.IsNull() {
 		return "", errors.New("user nor role are not filled in")
@@ -298,178 +1181,232 @@ func (m *databaseGrantResourceModel) userOrRole() (string, error) {
 	return m.Role.ValueString(), nil
 }
 
-func (m *databaseGrantResourceModel) withGrantOption() bool {
-	return m.WithGrantOption.ValueBool()
+// routineObjectTypes returns the routine types the privileges are granted on, nil when they are granted on the database.
+func (m *databaseGrantResourceModel) routineObjectTypes() []string {
+	switch m.ObjectType.ValueString() {
+	case "FUNCTION", "PROCEDURE":
+		return []string{m.ObjectType.ValueString()}
+	case "*":
+		return []string{"FUNCTION", "PROCEDURE"}
+	}
+	return nil
 }
 
-type dbRow struct {
-	Host               string
-	Db                 string
-	User               string
-	SelectPriv         string
-	InsertPriv         string
-	UpdatePriv         string
-	DeletePriv         string
-	CreatePriv         string
-	DropPriv           string
-	GrantPriv          string
-	ReferencesPriv     string
-	IndexPriv          string
-	AlterPriv          string
-	CreateTmpTablePriv string
-	LockTablesPriv     string
-	CreateViewPriv     string
-	ShowViewPriv       string
-	CreateRoutinePriv  string
-	AlterRoutinePriv   string
-	ExecutePriv        string
-	EventPriv          string
-	TriggerPriv        string
-}
-
-func (dbRow *dbRow) allPrivilegesStringValues() []types.String {
-	var privileges []types.String
-	for _, priv := range dbRow.allPrivileges() {
-		privileges = append(privileges, types.StringValue(priv))
+// effectivePrivileges returns the privileges on the server from the last read, the managed privileges for states
+// created before privileges_effective existed.
+func (m *databaseGrantResourceModel) effectivePrivileges(ctx context.Context) ([]string, diag.Diagnostics) {
+	if m.PrivilegesEffective.IsNull() || m.PrivilegesEffective.IsUnknown() {
+		return m.privilegesAsString(), nil
 	}
-	return privileges
+	var privileges []string
+	diags := m.PrivilegesEffective.ElementsAs(ctx, &privileges, false)
+	return privileges, diags
 }
 
-func (dbRow *dbRow) allPrivileges() []string {
-	var privileges []string
-	if dbRow.selectPrivBool() {
-		privileges = append(privileges, "SELECT")
-	}
-	if dbRow.insertPrivBool() {
-		privileges = append(privileges, "INSERT")
-	}
-	if dbRow.updatePrivBool() {
-		privileges = append(privileges, "UPDATE")
-	}
-	if dbRow.deletePrivBool() {
-		privileges = append(privileges, "DELETE")
-	}
-	if dbRow.createPrivBool() {
-		privileges = append(privileges, "CREATE")
-	}
-	if dbRow.dropPrivBool() {
-		privileges = append(privileges, "DROP")
-	}
-	if dbRow.referencesPrivBool() {
-		privileges = append(privileges, "REFERENCES")
-	}
-	if dbRow.indexPrivBool() {
-		privileges = append(privileges, "INDEX")
-	}
-	if dbRow.alterPrivBool() {
-		privileges = append(privileges, "ALTER")
-	}
-	if dbRow.createTmpTablePrivBool() {
-		privileges = append(privileges, "CREATE TEMPORARY TABLES")
-	}
-	if dbRow.lockTablesPrivBool() {
-		privileges = append(privileges, "LOCK TABLES")
-	}
-	if dbRow.createViewPrivBool() {
-		privileges = append(privileges, "CREATE VIEW")
+func (m *databaseGrantResourceModel) setEffectivePrivileges(ctx context.Context, privileges []string) diag.Diagnostics {
+	if privileges == nil {
+		privileges = []string{}
 	}
-	if dbRow.showViewPrivBool() {
-		privileges = append(privileges, "SHOW VIEW")
-	}
-	if dbRow.createRoutinePrivBool() {
-		privileges = append(privileges, "CREATE ROUTINE")
-	}
-	if dbRow.alterRoutinePrivBool() {
-		privileges = append(privileges, "ALTER ROUTINE")
-	}
-	if dbRow.executePrivBool() {
-		privileges = append(privileges, "EXECUTE")
-	}
-	if dbRow.eventPrivBool() {
-		privileges = append(privileges, "EVENT")
-	}
-	if dbRow.triggerPrivBool() {
-		privileges = append(privileges, "TRIGGER")
-	}
-	return privileges
+	var diags diag.Diagnostics
+	m.PrivilegesEffective, diags = types.SetValueFrom(ctx, types.StringType, uniquePrivileges(privileges))
+	return diags
 }
 
-func (dbRow *dbRow) withGrantOption() types.Bool {
-	return types.BoolValue(dbRow.grantPrivBool())
+// revokesUnmanaged checks if the privileges granted outside of Terraform are revoked, with authoritative or enforce.
+func (m *databaseGrantResourceModel) revokesUnmanaged() bool {
+	return m.Authoritative.ValueBool() || m.Enforce.ValueBool()
 }
 
-func (dbRow *dbRow) selectPrivBool() bool {
-	return dbRow.SelectPriv == "Y"
+func (m *databaseGrantResourceModel) withGrantOption() bool {
+	return m.WithGrantOption.ValueBool()
 }
 
-func (dbRow *dbRow) insertPrivBool() bool {
-	return dbRow.InsertPriv == "Y"
-}
+// setServerPrivileges sets the privileges and the grant option read from the server. The grant option is left as is
+// when the account has no grants to read it from. Only the configured privileges are kept in privileges, all of
+// them end up in privileges_effective. The global privileges count as granted for privileges only, privileges_effective
+// stays the privileges on the database.
+func (m *databaseGrantResourceModel) setServerPrivileges(ctx context.Context, privileges, global []string, withGrantOption bool, granted bool) diag.Diagnostics {
+	if granted {
+		m.WithGrantOption = types.BoolValue(withGrantOption)
+	}
+	m.Privileges = managedPrivileges(m.Privileges, append(global, privileges...))
 
-func (dbRow *dbRow) updatePrivBool() bool {
-	return dbRow.UpdatePriv == "Y"
+	var effective []string
+	for _, privilege := range reconcilePrivileges(m.Privileges, privileges) {
+		effective = append(effective, privilege.ValueString())
+	}
+	return m.setEffectivePrivileges(ctx, effective)
 }
 
-func (dbRow *dbRow) deletePrivBool() bool {
-	return dbRow.DeletePriv == "Y"
-}
+// grantOptionWarnings warns when the grant option of the account on the database doesn't match what the single
+// with_grant_option flag expresses: a grant option on *.* also covers the database, and the grant option on the
+// database also covers the privileges that are not managed by this resource.
+func (r *databaseGrantResource) grantOptionWarnings(m *databaseGrantResourceModel, userOrRole string, grants []*grantparser.Grant, grant *grantparser.Grant) diag.Diagnostics {
+	var diags diag.Diagnostics
 
-func (dbRow *dbRow) createPrivBool() bool {
-	return dbRow.CreatePriv == "Y"
-}
+	if !grant.WithGrantOption && globalGrantOption(grants, r.config, m.databaseAsString()) {
+		diags.AddWarning(
+			"Inconsistent grant option",
+			userOrRole+" has the grant option on *.*, it can grant its privileges on database "+m.databaseAsString()+
+				" although the grant option is not set on the database. Revoke the grant option on *.* or set "+
+				"with_grant_option to true",
+		)
+	}
 
-func (dbRow *dbRow) dropPrivBool() bool {
-	return dbRow.DropPriv == "Y"
+	if !grant.WithGrantOption || m.revokesUnmanaged() {
+		return diags
+	}
+	var unmanaged []string
+	for _, privilege := range r.config.expandAllPrivileges(m.privilegesAsString(), grant.PrivilegeNames(), levelDatabase) {
+		if len(privilegesIntersection([]string{privilege}, m.privilegesAsString())) == 0 {
+			unmanaged = append(unmanaged, privilege)
+		}
+	}
+	if len(unmanaged) > 0 {
+		diags.AddWarning(
+			"Inconsistent grant option",
+			"MySQL stores the grant option once per database, the grant option of "+userOrRole+" on database "+
+				m.databaseAsString()+" also applies to the privileges not managed by this resource: "+strings.Join(unmanaged, ", "),
+		)
+	}
+	return diags
 }
 
-func (dbRow *dbRow) grantPrivBool() bool {
-	return dbRow.GrantPriv == "Y"
-}
+// verifyPrincipalKind checks if the account on the server matches the configured user or role. MySQL stores roles
+// as locked accounts without a password, an account that can log in is considered a user.
+// The check is skipped when the provider account has no access to mysql.user.
+func (r *databaseGrantResource) verifyPrincipalKind(ctx context.Context, m *databaseGrantResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
 
-func (dbRow *dbRow) referencesPrivBool() bool {
-	return dbRow.ReferencesPriv == "Y"
-}
+	userOrRole, err := m.userOrRole()
+	if err != nil {
+		return diags
+	}
 
-func (dbRow *dbRow) indexPrivBool() bool {
-	return dbRow.IndexPriv == "Y"
-}
+	if r.config.readSource == readSourceInformationSchema {
+		tflog.Debug(ctx, "Skipping the verification of the principal kind of "+userOrRole+", read_source doesn't allow reading mysql.user")
+		return diags
+	}
 
-func (dbRow *dbRow) alterPrivBool() bool {
-	return dbRow.AlterPriv == "Y"
-}
+	user, host := r.config.canonicalAccount(userOrRole, m.hostAsString())
+	var accountLocked, authenticationString string
+	err = r.config.queryRowPrepared(ctx, r.db, "SELECT account_locked, authentication_string FROM mysql.user WHERE User = ? AND Host = ?",
+		[]any{user, host}, &accountLocked, &authenticationString)
+	if err != nil {
+		tflog.Debug(ctx, "Skipping the verification of the principal kind of "+userOrRole+": "+err.Error())
+		return diags
+	}
+	looksLikeRole := accountLocked == "Y" && authenticationString == ""
 
-func (dbRow *dbRow) createTmpTablePrivBool() bool {
-	return dbRow.CreateTmpTablePriv == "Y"
+	if !m.Role.IsNull() && !looksLikeRole {
+		diags.AddAttributeError(path.Root("role"),
+			"Principal is not a role",
+			"The account "+userOrRole+"@"+m.hostAsString()+" is configured as role, but it's a user that can log in. Use the `user` attribute instead.")
+	}
+	if !m.User.IsNull() && looksLikeRole {
+		diags.AddAttributeWarning(path.Root("user"),
+			"Principal looks like a role",
+			"The account "+userOrRole+"@"+m.hostAsString()+" is configured as user, but it's locked and has no password like a role. "+
+				"Use the `role` attribute if the account is a role.")
+	}
+	return diags
 }
 
-func (dbRow *dbRow) lockTablesPrivBool() bool {
-	return dbRow.LockTablesPriv == "Y"
-}
+// The host_match settings of a database grant.
+const (
+	hostMatchExact = "exact"
+	hostMatchBest  = "best_match"
+)
 
-func (dbRow *dbRow) createViewPrivBool() bool {
-	return dbRow.CreateViewPriv == "Y"
-}
+// matchedHost returns the host of the account the privileges are granted to. With host_match = best_match the
+// accounts of the user are read from mysql.user, the value is unknown when no account matches the host. A warning
+// lists the matching accounts when more than one matches.
+func (r *databaseGrantResource) matchedHost(ctx context.Context, m *databaseGrantResourceModel) (types.String, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	// Roles don't authenticate clients, the role is the account with exactly the host
+	if m.HostMatch.ValueString() != hostMatchBest || !m.Role.IsNull() {
+		return m.Host, diags
+	}
 
-func (dbRow *dbRow) showViewPrivBool() bool {
-	return dbRow.ShowViewPriv == "Y"
-}
+	userOrRole, err := m.userOrRole()
+	if err != nil {
+		return types.StringUnknown(), diags
+	}
+	user, host := r.config.canonicalAccount(userOrRole, m.Host.ValueString())
+	var hosts []string
+	err = r.config.queryRowsPrepared(ctx, r.db, "SELECT Host FROM mysql.user WHERE User = ?", []any{user}, func(rows *sql.Rows) error {
+		var accountHost string
+		if err := rows.Scan(&accountHost); err != nil {
+			return err
+		}
+		hosts = append(hosts, accountHost)
+		return nil
+	})
+	if err != nil {
+		diags.AddError(
+			"Error reading the accounts",
+			"Could not read the accounts of '"+user+"', unexpected error: "+err.Error(),
+		)
+		return types.StringUnknown(), diags
+	}
 
-func (dbRow *dbRow) createRoutinePrivBool() bool {
-	return dbRow.CreateRoutinePriv == "Y"
+	matching := matchingHosts(hosts, host)
+	if len(matching) == 0 {
+		return types.StringUnknown(), diags
+	}
+	if len(matching) > 1 {
+		accounts := make([]string, len(matching))
+		for i, matchingHost := range matching {
+			accounts[i] = sqlgen.Account(user, matchingHost)
+		}
+		diags.AddAttributeWarning(path.Root("host"), "Multiple accounts match the host",
+			"The accounts "+strings.Join(accounts, ", ")+" match a client connecting from "+host+", the privileges are granted to "+
+				accounts[0]+" which MySQL authenticates the client as. Set host_match = exact with the host of the intended account "+
+				"to grant to another one")
+	}
+	return types.StringValue(matching[0]), diags
 }
 
-func (dbRow *dbRow) alterRoutinePrivBool() bool {
-	return dbRow.AlterRoutinePriv == "Y"
+// descriptionKey is the key of the description of the grant in the grant_descriptions of the user attributes, the
+// database with the object type for the grants on routines.
+func (m *databaseGrantResourceModel) descriptionKey() string {
+	if m.routineObjectTypes() == nil {
+		return m.databaseAsString()
+	}
+	return m.databaseAsString() + ":" + m.ObjectType.ValueString()
 }
 
-func (dbRow *dbRow) executePrivBool() bool {
-	return dbRow.ExecutePriv == "Y"
-}
+// persistDescription sets the description of the grant in the grant_descriptions of the user attributes, a null
+// description removes it.
+func (r *databaseGrantResource) persistDescription(ctx context.Context, m *databaseGrantResourceModel, description types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
 
-func (dbRow *dbRow) eventPrivBool() bool {
-	return dbRow.EventPriv == "Y"
-}
+	userOrRole, err := m.userOrRole()
+	if err != nil {
+		diags.AddError(
+			"Error in input values",
+			"No value for user nor role, unexpected error: "+err.Error(),
+		)
+		return diags
+	}
 
-func (dbRow *dbRow) triggerPrivBool() bool {
-	return dbRow.TriggerPriv == "Y"
+	var value any
+	if !description.IsNull() {
+		value = description.ValueString()
+	}
+	// ATTRIBUTE merges the JSON into the existing attributes, null removes the key
+	patch, err := json.Marshal(map[string]any{accountGrantDescriptionsKey: map[string]any{m.descriptionKey(): value}})
+	if err != nil {
+		diags.AddError("Error storing the grant description", "Could not encode the description, unexpected error: "+err.Error())
+		return diags
+	}
+	account := r.config.quoteAccount(userOrRole, m.hostAsString())
+	_, err = execContext(ctx, r.db, "ALTER USER "+account+" ATTRIBUTE "+sqlgen.String(string(patch)))
+	if err != nil {
+		diags.AddError(
+			"Error storing the grant description",
+			"Could not alter the user attributes of "+account+", unexpected error: "+err.Error(),
+		)
+	}
+	return diags
 }
@@ -0,0 +1,243 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func newTestDatabaseGrant(database string, privileges ...string) *databaseGrantResourceModel {
+	m := &databaseGrantResourceModel{
+		Database:        types.StringValue(database),
+		User:            types.StringValue("app"),
+		Host:            types.StringValue("%"),
+		WithGrantOption: types.BoolValue(false),
+		IncludeGlobal:   types.BoolValue(false),
+		ObjectType:      types.StringValue("TABLE"),
+	}
+	for _, privilege := range privileges {
+		m.Privileges = append(m.Privileges, types.StringValue(privilege))
+	}
+	return m
+}
+
+func TestDatabaseGrantRevokeAndGrant(t *testing.T) {
+	db, mock := newMockDB(t)
+	r := &databaseGrantResource{db: db, config: &Config{}}
+	m := newTestDatabaseGrant("app", "SELECT", "INSERT")
+
+	expectConnectionID(mock)
+	mock.ExpectExec("REVOKE DELETE ON `app`.* FROM 'app'@'%'").WillReturnResult(sqlmock.NewResult(0, 0))
+	expectConnectionID(mock)
+	mock.ExpectExec("GRANT SELECT, INSERT ON `app`.* TO 'app'@'%'").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	diags := r.revokeAndGrant(context.Background(), m, "Error", []string{"DELETE"}, []string{"SELECT", "INSERT"})
+	if diags.HasError() {
+		t.Errorf("revokeAndGrant returned %v", diags)
+	}
+}
+
+func TestDatabaseGrantWithGrantOption(t *testing.T) {
+	db, mock := newMockDB(t)
+	r := &databaseGrantResource{db: db, config: &Config{}}
+	m := newTestDatabaseGrant("we`ird", "SELECT")
+	m.WithGrantOption = types.BoolValue(true)
+
+	expectConnectionID(mock)
+	mock.ExpectExec("GRANT SELECT ON `we``ird`.* TO 'app'@'%' WITH GRANT OPTION").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if diags := r.revokeAndGrant(context.Background(), m, "Error", nil, []string{"SELECT"}); diags.HasError() {
+		t.Errorf("revokeAndGrant returned %v", diags)
+	}
+}
+
+func TestDatabaseGrantErrors(t *testing.T) {
+	denied := &mysql.MySQLError{Number: 1044, Message: "Access denied for user 'provider'@'%' to database 'app'"}
+	tests := []struct {
+		name             string
+		toRevoke         []string
+		toGrant          []string
+		expect           func(mock sqlmock.Sqlmock)
+		wantErrorContain string
+	}{
+		{
+			name:    "grant fails",
+			toGrant: []string{"SELECT"},
+			expect: func(mock sqlmock.Sqlmock) {
+				expectConnectionID(mock)
+				mock.ExpectExec("GRANT SELECT ON `app`.* TO 'app'@'%'").WillReturnError(denied)
+			},
+			wantErrorContain: "Unable to grant permissions to app, unexpected error: Error 1044: Access denied",
+		},
+		{
+			name:     "revoke fails before the grant",
+			toRevoke: []string{"DELETE"},
+			toGrant:  []string{"SELECT"},
+			expect: func(mock sqlmock.Sqlmock) {
+				expectConnectionID(mock)
+				mock.ExpectExec("REVOKE DELETE ON `app`.* FROM 'app'@'%'").WillReturnError(denied)
+			},
+			wantErrorContain: "Unable to revoke permissions from app",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			r := &databaseGrantResource{db: db, config: &Config{}}
+			test.expect(mock)
+
+			diags := r.revokeAndGrant(context.Background(), newTestDatabaseGrant("app", "SELECT"), "Error granting", test.toRevoke, test.toGrant)
+			if !diags.HasError() {
+				t.Fatal("revokeAndGrant returned no error")
+			}
+			if detail := diags.Errors()[0].Detail(); !strings.Contains(detail, test.wantErrorContain) {
+				t.Errorf("revokeAndGrant returned %q, want it to contain %q", detail, test.wantErrorContain)
+			}
+		})
+	}
+}
+
+func TestDatabaseGrantStatementsIncludeGlobal(t *testing.T) {
+	tests := []struct {
+		name   string
+		grants []string
+		want   []string
+	}{
+		{
+			name:   "revoked from all privileges",
+			grants: []string{"GRANT USAGE ON *.* TO `app`@`%`", "GRANT ALL PRIVILEGES ON `app`.* TO `app`@`%`"},
+			want:   []string{"REVOKE SELECT ON `app`.* FROM 'app'@'%'"},
+		},
+		{
+			name:   "only held globally",
+			grants: []string{"GRANT SELECT ON *.* TO `app`@`%`"},
+		},
+		{
+			name:   "grant option held",
+			grants: []string{"GRANT SELECT, INSERT ON `app`.* TO `app`@`%` WITH GRANT OPTION"},
+			want:   []string{"REVOKE SELECT, GRANT OPTION ON `app`.* FROM 'app'@'%'"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			r := &databaseGrantResource{db: db, config: &Config{serverVersion: serverVersion{major: 8}}}
+			m := newTestDatabaseGrant("app", "INSERT")
+			m.IncludeGlobal = types.BoolValue(true)
+			expectShowGrants(mock, "'app'@'%'", test.grants...)
+
+			statements, diags := r.grantStatements(context.Background(), m, "Error", []string{"SELECT", "GRANT OPTION"}, nil)
+			if diags.HasError() {
+				t.Fatalf("grantStatements returned %v", diags)
+			}
+			var got []string
+			for _, statement := range statements {
+				got = append(got, statement.sql)
+			}
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("grantStatements returned %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDatabaseGrantStatementsRoutines(t *testing.T) {
+	db, mock := newMockDB(t)
+	r := &databaseGrantResource{db: db, config: &Config{}}
+	m := newTestDatabaseGrant("app", "EXECUTE")
+	m.ObjectType = types.StringValue("PROCEDURE")
+
+	mock.ExpectPrepare("SELECT ROUTINE_TYPE, ROUTINE_NAME FROM INFORMATION_SCHEMA.ROUTINES WHERE ROUTINE_SCHEMA = ? AND "+
+		"ROUTINE_TYPE IN (?) ORDER BY ROUTINE_TYPE, ROUTINE_NAME").ExpectQuery().WithArgs("app", "PROCEDURE").
+		WillReturnRows(sqlmock.NewRows([]string{"ROUTINE_TYPE", "ROUTINE_NAME"}).AddRow("PROCEDURE", "archive").AddRow("PROCEDURE", "refresh"))
+	expectShowGrants(mock, "'app'@'%'", "GRANT USAGE ON *.* TO `app`@`%`",
+		"GRANT EXECUTE, ALTER ROUTINE ON PROCEDURE `app`.`refresh` TO `app`@`%`")
+
+	statements, diags := r.grantStatements(context.Background(), m, "Error", []string{"ALTER ROUTINE"}, []string{"EXECUTE"})
+	if diags.HasError() {
+		t.Fatalf("grantStatements returned %v", diags)
+	}
+	want := []grantStatement{
+		{sql: "REVOKE ALTER ROUTINE ON PROCEDURE `app`.`refresh` FROM 'app'@'%'"},
+		{sql: "GRANT EXECUTE ON PROCEDURE `app`.`archive` TO 'app'@'%'", grant: true},
+		{sql: "GRANT EXECUTE ON PROCEDURE `app`.`refresh` TO 'app'@'%'", grant: true},
+	}
+	if len(statements) != len(want) {
+		t.Fatalf("grantStatements returned %v, want %v", statements, want)
+	}
+	for i := range want {
+		if statements[i] != want[i] {
+			t.Errorf("statement %d is %v, want %v", i, statements[i], want[i])
+		}
+	}
+}
+
+func TestDatabaseGrantCheckExistingGrant(t *testing.T) {
+	tests := []struct {
+		name         string
+		grants       []string
+		wantAdopt    bool
+		wantConflict bool
+	}{
+		{name: "no grant", grants: []string{"GRANT USAGE ON *.* TO `app`@`%`"}},
+		{name: "identical grant", grants: []string{"GRANT SELECT, INSERT ON `app`.* TO `app`@`%`"}, wantAdopt: true},
+		{name: "all privileges", grants: []string{"GRANT ALL PRIVILEGES ON `app`.* TO `app`@`%`"}, wantAdopt: true},
+		{name: "missing privilege", grants: []string{"GRANT SELECT ON `app`.* TO `app`@`%`"}},
+		{name: "grant option", grants: []string{"GRANT SELECT, INSERT ON `app`.* TO `app`@`%` WITH GRANT OPTION"}, wantConflict: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			r := &databaseGrantResource{db: db, config: &Config{}}
+			expectShowGrants(mock, "'app'@'%'", test.grants...)
+
+			adopt, conflict, err := r.checkExistingGrant(context.Background(), newTestDatabaseGrant("app", "SELECT", "INSERT"))
+			if err != nil {
+				t.Fatalf("checkExistingGrant returned error: %v", err)
+			}
+			if adopt != test.wantAdopt || (conflict != "") != test.wantConflict {
+				t.Errorf("checkExistingGrant returned %t, %q, want %t and conflict %t", adopt, conflict, test.wantAdopt, test.wantConflict)
+			}
+		})
+	}
+}
+
+// newTestPlan returns a plan of the resource with the values, the other attributes are null.
+func newTestPlan(t *testing.T, r resource.Resource, values map[string]tftypes.Value) tfsdk.Plan {
+	t.Helper()
+	var schemaResp resource.SchemaResponse
+	r.Schema(context.Background(), resource.SchemaRequest{}, &schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(context.Background()).(tftypes.Object)
+	attributes := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attributeType := range objectType.AttributeTypes {
+		attributes[name] = tftypes.NewValue(attributeType, nil)
+	}
+	for name, value := range values {
+		attributes[name] = value
+	}
+	return tfsdk.Plan{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, attributes)}
+}
+
+func TestDatabaseGrantModifyPlanUnknownPrivileges(t *testing.T) {
+	r := &databaseGrantResource{config: &Config{}}
+	plan := newTestPlan(t, r, map[string]tftypes.Value{
+		"database":   tftypes.NewValue(tftypes.String, "app"),
+		"user":       tftypes.NewValue(tftypes.String, "app"),
+		"host":       tftypes.NewValue(tftypes.String, "%"),
+		"privileges": tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, tftypes.UnknownValue),
+	})
+	req := resource.ModifyPlanRequest{Plan: plan, State: tfsdk.State{Schema: plan.Schema, Raw: tftypes.NewValue(plan.Raw.Type(), nil)}}
+	resp := &resource.ModifyPlanResponse{Plan: plan}
+
+	r.ModifyPlan(context.Background(), req, resp)
+	if resp.Diagnostics.HasError() {
+		t.Errorf("ModifyPlan with unknown privileges returned %v", resp.Diagnostics)
+	}
+}
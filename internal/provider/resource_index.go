@@ -0,0 +1,340 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"terraform-provider-cloudsqlmysql/internal/sqlgen"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource               = &indexResource{}
+	_ resource.ResourceWithConfigure  = &indexResource{}
+	_ resource.ResourceWithModifyPlan = &indexResource{}
+)
+
+const (
+	// mysqlErrCantDropFieldOrKey is ER_CANT_DROP_FIELD_OR_KEY: the index to drop doesn't exist.
+	mysqlErrCantDropFieldOrKey = 1091
+	// mysqlErrNoSuchTable is ER_NO_SUCH_TABLE.
+	mysqlErrNoSuchTable = 1146
+)
+
+type indexResource struct {
+	db     dbPool
+	config *Config
+}
+
+type indexResourceModel struct {
+	Database types.String   `tfsdk:"database"`
+	Table    types.String   `tfsdk:"table"`
+	Name     types.String   `tfsdk:"name"`
+	Columns  []types.String `tfsdk:"columns"`
+	Unique   types.Bool     `tfsdk:"unique"`
+}
+
+func newIndexResource() resource.Resource {
+	return &indexResource{}
+}
+
+func (r *indexResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_index"
+}
+
+func (r *indexResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Makes sure an index exists on an existing table, e.g. on the audit tables of an application. The index is " +
+			"read from INFORMATION_SCHEMA.STATISTICS, an index that was dropped or changed outside of Terraform is created again. " +
+			"The index is dropped on destroy. Requires allow_index_ddl in the provider configuration",
+		MarkdownDescription: "Makes sure an index exists on an existing table, e.g. on the audit tables of an application. The index is " +
+			"read from `INFORMATION_SCHEMA.STATISTICS`, an index that was dropped or changed outside of Terraform is created again. " +
+			"The index is dropped on destroy. Requires `allow_index_ddl` in the provider configuration",
+		Attributes: map[string]schema.Attribute{
+			"database": schema.StringAttribute{
+				Description:         "The name of the database",
+				MarkdownDescription: "The name of the database",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, maxDatabaseNameLength),
+				},
+			},
+			"table": schema.StringAttribute{
+				Description:         "The name of the table",
+				MarkdownDescription: "The name of the table",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, maxDatabaseNameLength),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description:         "The name of the index. The primary key can't be managed",
+				MarkdownDescription: "The name of the index. The primary key can't be managed",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, maxDatabaseNameLength),
+					stringvalidator.NoneOfCaseInsensitive("PRIMARY"),
+				},
+			},
+			"columns": schema.ListAttribute{
+				Description:         "The columns of the index in order. Functional key parts are not supported",
+				MarkdownDescription: "The columns of the index in order. Functional key parts are not supported",
+				ElementType:         types.StringType,
+				Required:            true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.UniqueValues(),
+				},
+			},
+			"unique": schema.BoolAttribute{
+				Description:         "When true the index is a UNIQUE index. Default: false",
+				MarkdownDescription: "When `true` the index is a `UNIQUE` index. Default: `false`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+	}
+}
+
+func (r *indexResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan indexResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	columns := make([]string, len(plan.Columns))
+	for i, column := range plan.Columns {
+		columns[i] = column.ValueString()
+	}
+
+	_, err := execContext(ctx, r.db, sqlgen.CreateIndex(plan.Name.ValueString(), plan.quotedTable(), columns, plan.Unique.ValueBool()))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating the index",
+			"Could not create index '"+plan.Name.ValueString()+"' on "+plan.quotedTable()+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *indexResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withReadTimeout(ctx)
+
+	var state indexResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var columns []types.String
+	unique := true
+	err := queryRows(ctx, r.db, "SELECT COLUMN_NAME, NON_UNIQUE FROM INFORMATION_SCHEMA.STATISTICS "+
+		"WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND INDEX_NAME = ? ORDER BY SEQ_IN_INDEX",
+		[]any{r.config.databaseNameForLookup(state.Database.ValueString()), r.config.databaseNameForLookup(state.Table.ValueString()),
+			state.Name.ValueString()},
+		func(rows *sql.Rows) error {
+			// COLUMN_NAME is NULL for functional key parts
+			var column sql.NullString
+			var nonUnique bool
+			if err := rows.Scan(&column, &nonUnique); err != nil {
+				return err
+			}
+			columns = append(columns, types.StringValue(column.String))
+			unique = unique && !nonUnique
+			return nil
+		})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading the index",
+			"Could not read index '"+state.Name.ValueString()+"' of "+state.quotedTable()+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	if len(columns) == 0 {
+		// Removing the resource from the state creates the index again on the next apply
+		resp.Diagnostics.AddWarning(
+			"Index dropped outside of Terraform",
+			"Index '"+state.Name.ValueString()+"' no longer exists on "+state.quotedTable()+", it is created again on the next apply",
+		)
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// The configured spelling of the columns is kept, MySQL compares column names case insensitively
+	if !indexColumnsEqual(state.Columns, columns) {
+		state.Columns = columns
+	}
+	state.Unique = types.BoolValue(unique)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *indexResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// No updates possible, needs to recreate
+}
+
+func (r *indexResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state indexResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := execContext(ctx, r.db, sqlgen.DropIndex(state.Name.ValueString(), state.quotedTable()))
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && (mysqlErr.Number == mysqlErrCantDropFieldOrKey || mysqlErr.Number == mysqlErrNoSuchTable ||
+		mysqlErr.Number == mysqlErrBadDB) {
+		// The index went with the table
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error dropping the index",
+			"Could not drop index '"+state.Name.ValueString()+"' of "+state.quotedTable()+", unexpected error: "+err.Error(),
+		)
+	}
+}
+
+func (r *indexResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to the Cloud SQL MySQL instance",
+			err.Error(),
+		)
+		return
+	}
+
+	err = config.detectServerSettings(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read the Cloud SQL MySQL server settings",
+			err.Error(),
+		)
+		return
+	}
+
+	r.db = db
+	r.config = config
+}
+
+func (r *indexResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.config == nil {
+		return
+	}
+
+	var plan indexResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !r.config.allowIndexDDL {
+		resp.Diagnostics.AddError(
+			"Index management not enabled",
+			"cloudsqlmysql_index runs CREATE INDEX and DROP INDEX on the tables of the database, which can lock and slow down "+
+				"large tables. Set `allow_index_ddl = true` in the provider configuration to allow it.",
+		)
+	}
+	if !plan.Database.IsUnknown() {
+		if message := r.config.systemSchemaError(plan.Database.ValueString()); message != "" {
+			resp.Diagnostics.AddAttributeError(path.Root("database"), "System schema not allowed", message)
+		}
+	}
+}
+
+// quotedTable returns the quoted database and table of the index.
+func (m *indexResourceModel) quotedTable() string {
+	return sqlgen.TableLevel(m.Database.ValueString(), m.Table.ValueString())
+}
+
+// indexColumnsEqual compares the columns of an index in order, column names are case insensitive.
+func indexColumnsEqual(a, b []types.String) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i].ValueString(), b[i].ValueString()) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,276 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource               = &monitoringGrantResource{}
+	_ resource.ResourceWithConfigure  = &monitoringGrantResource{}
+	_ resource.ResourceWithModifyPlan = &monitoringGrantResource{}
+)
+
+type monitoringGrantResource struct {
+	db     dbPool
+	config *Config
+}
+
+type monitoringGrantResourceModel struct {
+	User types.String `tfsdk:"user"`
+	Host types.String `tfsdk:"host"`
+	// PerformanceSchemaEnabled is read from @@performance_schema, the Cloud SQL performance_schema flag.
+	PerformanceSchemaEnabled types.Bool `tfsdk:"performance_schema_enabled"`
+}
+
+func newMonitoringGrantResource() resource.Resource {
+	return &monitoringGrantResource{}
+}
+
+func (r *monitoringGrantResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_monitoring_grant"
+}
+
+func (r *monitoringGrantResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Grants an existing user the read-only privileges of monitoring agents: PROCESS on *.* and SELECT on " +
+			"performance_schema.*. The plan fails when the provider user can't grant them, the grants are read back after " +
+			"they are applied and granted again when they were revoked outside of Terraform. The privileges are revoked on destroy",
+		MarkdownDescription: "Grants an existing user the read-only privileges of monitoring agents: `PROCESS` on `*.*` and `SELECT` on " +
+			"`performance_schema.*`. The plan fails when the provider user can't grant them, the grants are read back after " +
+			"they are applied and granted again when they were revoked outside of Terraform. The privileges are revoked on destroy",
+		Attributes: map[string]schema.Attribute{
+			"user": schema.StringAttribute{
+				Description:         "The name of the user the monitoring agent connects as",
+				MarkdownDescription: "The name of the user the monitoring agent connects as",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					userNameValidator{},
+				},
+			},
+			"host": schema.StringAttribute{
+				Description:         "The host of the user. Default: %",
+				MarkdownDescription: "The host of the user. Default: `%`",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("%"),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					hostValidator{},
+				},
+			},
+			"performance_schema_enabled": schema.BoolAttribute{
+				Description: "Whether performance_schema is enabled on the instance. On Cloud SQL it is enabled with the " +
+					"performance_schema flag, which restarts the instance. The tables of performance_schema are empty while it is disabled",
+				MarkdownDescription: "Whether `performance_schema` is enabled on the instance. On Cloud SQL it is enabled with the " +
+					"`performance_schema` flag, which restarts the instance. The tables of `performance_schema` are empty while it is disabled",
+				Computed: true,
+			},
+		},
+	}
+}
+
+func (r *monitoringGrantResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan monitoringGrantResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := grantMonitoringPrivileges(ctx, r.db, r.config, plan.User.ValueString(), plan.Host.ValueString(), performanceSchemaPrivileges)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error granting the monitoring privileges",
+			"Could not grant the monitoring privileges to "+r.config.quoteAccount(plan.User.ValueString(), plan.Host.ValueString())+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	plan.PerformanceSchemaEnabled, err = r.performanceSchemaEnabled(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error granting the monitoring privileges",
+			"Could not read @@performance_schema, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *monitoringGrantResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withReadTimeout(ctx)
+
+	var state monitoringGrantResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	account := r.config.quoteAccount(state.User.ValueString(), state.Host.ValueString())
+	grants, err := r.config.readGrants(ctx, r.db, state.User.ValueString(), state.Host.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading the monitoring privileges",
+			"Could not read the grants of "+account+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	if !monitoringPrivilegesHeld(grants, r.config, performanceSchemaPrivileges) {
+		// Removing the resource from the state grants the privileges again on the next apply
+		resp.Diagnostics.AddWarning(
+			"Monitoring privileges revoked outside of Terraform",
+			account+" no longer holds both PROCESS on *.* and SELECT on `performance_schema`.*, they are granted again on the next apply",
+		)
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.PerformanceSchemaEnabled, err = r.performanceSchemaEnabled(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading the monitoring privileges",
+			"Could not read @@performance_schema, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *monitoringGrantResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// No updates possible, needs to recreate
+}
+
+func (r *monitoringGrantResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state monitoringGrantResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := revokeMonitoringPrivileges(ctx, r.db, r.config, state.User.ValueString(), state.Host.ValueString(), performanceSchemaPrivileges)
+	if accountMissingError(err) {
+		// The user was dropped before the grant, its privileges went with it
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error revoking the monitoring privileges",
+			"Could not revoke the monitoring privileges from "+r.config.quoteAccount(state.User.ValueString(), state.Host.ValueString())+", unexpected error: "+err.Error(),
+		)
+	}
+}
+
+func (r *monitoringGrantResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to the Cloud SQL MySQL instance",
+			err.Error(),
+		)
+		return
+	}
+
+	err = config.detectServerSettings(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read the Cloud SQL MySQL server settings",
+			err.Error(),
+		)
+		return
+	}
+
+	r.db = db
+	r.config = config
+}
+
+func (r *monitoringGrantResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.config == nil {
+		return
+	}
+	ctx = r.config.withReadTimeout(ctx)
+
+	var plan monitoringGrantResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.User.IsUnknown() && !plan.Host.IsUnknown() {
+		if message := r.config.anonymousAccountError(plan.User.ValueString(), plan.Host.ValueString()); message != "" {
+			resp.Diagnostics.AddAttributeError(path.Root("user"), "Anonymous account not allowed", message)
+		}
+	}
+
+	// The capabilities are only checked when the privileges are granted
+	if !req.State.Raw.IsNull() {
+		return
+	}
+	resp.Diagnostics.Append(checkMonitoringCapabilities(ctx, r.db, r.config, performanceSchemaPrivileges)...)
+}
+
+func (r *monitoringGrantResource) performanceSchemaEnabled(ctx context.Context) (types.Bool, error) {
+	enabled, err := performanceSchemaEnabled(ctx, r.db, r.config)
+	if err != nil {
+		return types.BoolNull(), err
+	}
+	return types.BoolValue(enabled), nil
+}
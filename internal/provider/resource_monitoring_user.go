@@ -0,0 +1,363 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"terraform-provider-cloudsqlmysql/internal/sqlgen"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource               = &monitoringUserResource{}
+	_ resource.ResourceWithConfigure  = &monitoringUserResource{}
+	_ resource.ResourceWithModifyPlan = &monitoringUserResource{}
+)
+
+type monitoringUserResource struct {
+	db     dbPool
+	config *Config
+}
+
+type monitoringUserResourceModel struct {
+	User     types.String `tfsdk:"user"`
+	Host     types.String `tfsdk:"host"`
+	Password types.String `tfsdk:"password"`
+	// Agent is cleared on read when privileges of the agent were revoked, so the next apply grants them again.
+	Agent  types.String `tfsdk:"agent"`
+	Labels types.Map    `tfsdk:"labels"`
+}
+
+func newMonitoringUserResource() resource.Resource {
+	return &monitoringUserResource{}
+}
+
+func (r *monitoringUserResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_monitoring_user"
+}
+
+func (r *monitoringUserResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Creates the user of a monitoring agent with the privileges from the setup instructions of the agent. " +
+			"The user and its privileges are managed as one unit: the user is dropped again when the privileges can't be granted, " +
+			"privileges revoked outside of Terraform are granted again and the user is dropped on destroy. The user must not " +
+			"also be managed with google_sql_user",
+		MarkdownDescription: "Creates the user of a monitoring agent with the privileges from the setup instructions of the agent. " +
+			"The user and its privileges are managed as one unit: the user is dropped again when the privileges can't be granted, " +
+			"privileges revoked outside of Terraform are granted again and the user is dropped on destroy. The user must not " +
+			"also be managed with `google_sql_user`",
+		Attributes: map[string]schema.Attribute{
+			"user": schema.StringAttribute{
+				Description:         "The name of the user",
+				MarkdownDescription: "The name of the user",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					userNameValidator{},
+				},
+			},
+			"host": schema.StringAttribute{
+				Description:         "The host of the user. Default: %",
+				MarkdownDescription: "The host of the user. Default: `%`",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("%"),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					hostValidator{},
+				},
+			},
+			"password": schema.StringAttribute{
+				Description: "The password of the user. It is only sent to the server, but like all sensitive values it is " +
+					"stored in the Terraform state",
+				MarkdownDescription: "The password of the user. It is only sent to the server, but like all sensitive values it is " +
+					"stored in the Terraform state",
+				Required:  true,
+				Sensitive: true,
+			},
+			"labels": labelsAttribute(),
+			"agent": schema.StringAttribute{
+				Description: "The monitoring agent: datadog (PROCESS and REPLICATION CLIENT on *.*, SELECT on performance_schema.*), " +
+					"pmm for Percona Monitoring and Management (SELECT, PROCESS, REPLICATION CLIENT and RELOAD on *.*) or " +
+					"cloudsql_insights for agents that read the processlist and performance_schema (PROCESS on *.*, SELECT on " +
+					"performance_schema.*). A change of the agent revokes the privileges the new agent doesn't need",
+				MarkdownDescription: "The monitoring agent: `datadog` (`PROCESS` and `REPLICATION CLIENT` on `*.*`, `SELECT` on `performance_schema.*`), " +
+					"`pmm` for Percona Monitoring and Management (`SELECT`, `PROCESS`, `REPLICATION CLIENT` and `RELOAD` on `*.*`) or " +
+					"`cloudsql_insights` for agents that read the processlist and `performance_schema` (`PROCESS` on `*.*`, `SELECT` on " +
+					"`performance_schema.*`). A change of the agent revokes the privileges the new agent doesn't need",
+				Required: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(monitoringAgentNames()...),
+				},
+			},
+		},
+	}
+}
+
+func (r *monitoringUserResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan monitoringUserResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	account := r.config.quoteAccount(plan.User.ValueString(), plan.Host.ValueString())
+	// The statement contains the password, it is only logged redacted by log_sql
+	_, err := execContext(ctx, r.db, sqlgen.CreateUser(account, plan.Password.ValueString()))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating the monitoring user",
+			"Could not create user "+account+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	err = grantMonitoringPrivileges(ctx, r.db, r.config, plan.User.ValueString(), plan.Host.ValueString(), monitoringAgents[plan.Agent.ValueString()])
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating the monitoring user",
+			"Could not grant the privileges of "+plan.Agent.ValueString()+" to "+account+", the user is dropped again. Unexpected error: "+err.Error(),
+		)
+		_, err = execContext(ctx, r.db, sqlgen.DropUser(account, true))
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reverting the monitoring user",
+				"Could not drop user "+account+", unexpected error: "+err.Error(),
+			)
+		}
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *monitoringUserResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withReadTimeout(ctx)
+
+	var state monitoringUserResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	account := r.config.quoteAccount(state.User.ValueString(), state.Host.ValueString())
+	user, host := r.config.canonicalAccount(state.User.ValueString(), state.Host.ValueString())
+	var exists int
+	err := r.config.queryRowPrepared(ctx, r.db, "SELECT 1 FROM mysql.user WHERE User = ? AND Host = ?", []any{user, host}, &exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading the monitoring user",
+			"Could not read user "+account+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	grants, err := r.config.readGrants(ctx, r.db, state.User.ValueString(), state.Host.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading the monitoring user",
+			"Could not read the grants of "+account+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+	if !state.Agent.IsNull() && !monitoringPrivilegesHeld(grants, r.config, monitoringAgents[state.Agent.ValueString()]) {
+		// Clearing the agent from the state makes the next apply grant the privileges again
+		resp.Diagnostics.AddWarning(
+			"Monitoring privileges revoked outside of Terraform",
+			account+" no longer holds all privileges of "+state.Agent.ValueString()+", they are granted again on the next apply",
+		)
+		state.Agent = types.StringNull()
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *monitoringUserResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan, state monitoringUserResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	account := r.config.quoteAccount(plan.User.ValueString(), plan.Host.ValueString())
+	if !plan.Password.Equal(state.Password) {
+		_, err := execContext(ctx, r.db, sqlgen.AlterUserPassword(account, plan.Password.ValueString()))
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error updating the monitoring user",
+				"Could not alter user "+account+", unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	if !plan.Agent.Equal(state.Agent) {
+		required := monitoringAgents[plan.Agent.ValueString()]
+		// The agent is null in the state when privileges were revoked outside of Terraform, there is nothing to revoke then
+		toRevoke := monitoringPrivilegesDifference(monitoringAgents[state.Agent.ValueString()], required)
+		err := revokeMonitoringPrivileges(ctx, r.db, r.config, plan.User.ValueString(), plan.Host.ValueString(), toRevoke)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error updating the monitoring user",
+				"Could not revoke the privileges of "+state.Agent.ValueString()+" from "+account+", unexpected error: "+err.Error(),
+			)
+			return
+		}
+
+		err = grantMonitoringPrivileges(ctx, r.db, r.config, plan.User.ValueString(), plan.Host.ValueString(), required)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error updating the monitoring user",
+				"Could not grant the privileges of "+plan.Agent.ValueString()+" to "+account+", unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	diags := resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *monitoringUserResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state monitoringUserResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	account := r.config.quoteAccount(state.User.ValueString(), state.Host.ValueString())
+	_, err := execContext(ctx, r.db, sqlgen.DropUser(account, true)) // Dropping the user removes its grants too
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting the monitoring user",
+			"Could not drop user "+account+", unexpected error: "+err.Error(),
+		)
+	}
+}
+
+func (r *monitoringUserResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to the Cloud SQL MySQL instance",
+			err.Error(),
+		)
+		return
+	}
+
+	err = config.detectServerSettings(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read the Cloud SQL MySQL server settings",
+			err.Error(),
+		)
+		return
+	}
+
+	r.db = db
+	r.config = config
+}
+
+func (r *monitoringUserResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.config == nil {
+		return
+	}
+	ctx = r.config.withReadTimeout(ctx)
+
+	var plan monitoringUserResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.User.IsUnknown() && !plan.Host.IsUnknown() {
+		if message := r.config.anonymousAccountError(plan.User.ValueString(), plan.Host.ValueString()); message != "" {
+			resp.Diagnostics.AddAttributeError(path.Root("user"), "Anonymous account not allowed", message)
+		}
+	}
+
+	// The capabilities are only checked when privileges are granted
+	if plan.Agent.IsUnknown() {
+		return
+	}
+	if !req.State.Raw.IsNull() {
+		var agent types.String
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("agent"), &agent)...)
+		if resp.Diagnostics.HasError() || agent.Equal(plan.Agent) {
+			return
+		}
+	}
+	resp.Diagnostics.Append(checkMonitoringCapabilities(ctx, r.db, r.config, monitoringAgents[plan.Agent.ValueString()])...)
+}
@@ -3,22 +3,32 @@ package provider
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
+	"terraform-provider-cloudsqlmysql/internal/sqlgen"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var (
-	_ resource.Resource              = &roleResource{}
-	_ resource.ResourceWithConfigure = &roleResource{}
+	_ resource.Resource               = &roleResource{}
+	_ resource.ResourceWithConfigure  = &roleResource{}
+	_ resource.ResourceWithModifyPlan = &roleResource{}
 )
 
 type roleResource struct {
-	db *sql.DB
+	db     dbPool
+	config *Config
 }
 
 func NewRoleResource() resource.Resource {
@@ -33,16 +43,57 @@ func (r *roleResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"name": schema.StringAttribute{
-				Required: true,
+				Description:         "The name of the role, role or 'role'@'host'. The host defaults to %",
+				MarkdownDescription: "The name of the role, `role` or `'role'@'host'`. The host defaults to `%`",
+				CustomType:          AccountType{},
+				Required:            true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
+			"comment": schema.StringAttribute{
+				Description:         "The comment of the role account, stored in the comment key of its user attributes. Requires MySQL 8.0.21 or later",
+				MarkdownDescription: "The comment of the role account, stored in the `comment` key of its user attributes. Requires MySQL 8.0.21 or later",
+				Optional:            true,
+			},
+			"attributes": schema.StringAttribute{
+				Description: "The user attributes of the role account as JSON object, e.g. to record the owner of the role. " +
+					"Requires MySQL 8.0.21 or later",
+				MarkdownDescription: "The user attributes of the role account as JSON object, e.g. to record the owner of the role. " +
+					"Requires MySQL 8.0.21 or later",
+				Optional: true,
+				Validators: []validator.String{
+					accountAttributesValidator{},
+				},
+			},
+			"prevent_destroy_sql": preventDestroySQLAttribute(),
+			"labels":              labelsAttribute(),
+			"adopt_existing": schema.BoolAttribute{
+				Description: "When true a role that already exists is adopted into the state on create instead of failing, e.g. " +
+					"when the role was created by another Terraform configuration during a migration. The existing account is " +
+					"verified to be a role, an account that can log in is not adopted. Default: false",
+				MarkdownDescription: "When `true` a role that already exists is adopted into the state on create instead of failing, e.g. " +
+					"when the role was created by another Terraform configuration during a migration. The existing account is " +
+					"verified to be a role, an account that can log in is not adopted. Default: `false`",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
 		},
 	}
 }
 
 func (r *roleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var plan roleResourceModel
 
 	diags := req.Plan.Get(ctx, &plan)
@@ -54,7 +105,14 @@ func (r *roleResource) Create(ctx context.Context, req resource.CreateRequest, r
 
 	roleName := plan.Name.ValueString()
 
-	_, err := r.db.ExecContext(ctx, fmt.Sprintf("CREATE ROLE '%s'", roleName)) // Fix this when CREATE ROLE is supported in prepared statements
+	if plan.AdoptExisting.ValueBool() {
+		resp.Diagnostics.Append(r.verifyAdoptable(ctx, &plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	_, err := execContext(ctx, r.db, sqlgen.CreateRole(plan.quotedName(r.config), plan.AdoptExisting.ValueBool()))
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error creating role",
@@ -63,6 +121,11 @@ func (r *roleResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
+	resp.Diagnostics.Append(r.alterAttributes(ctx, &roleResourceModel{}, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -71,6 +134,9 @@ func (r *roleResource) Create(ctx context.Context, req resource.CreateRequest, r
 }
 
 func (r *roleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withReadTimeout(ctx)
+
 	var state roleResourceModel
 
 	diags := req.State.Get(ctx, &state)
@@ -81,7 +147,9 @@ func (r *roleResource) Read(ctx context.Context, req resource.ReadRequest, resp
 
 	role := state.Name.ValueString()
 
-	rows, err := r.db.QueryContext(ctx, fmt.Sprintf("SHOW GRANTS FOR '%s'", role))
+	stmtCtx, cancel := statementContext(ctx)
+	defer cancel()
+	rows, err := queryContext(stmtCtx, r.db, "SHOW GRANTS FOR "+state.quotedName(r.config))
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error reading role",
@@ -98,6 +166,26 @@ func (r *roleResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		)
 		return
 	}
+	rows.Close()
+
+	// The attributes are only read when managed, so roles keep working on MySQL versions without USER_ATTRIBUTES
+	if !state.Comment.IsNull() || !state.Attributes.IsNull() {
+		account, err := state.Name.Account()
+		if err == nil {
+			var attributes types.String
+			state.Comment, attributes, err = r.config.readAccountAttributes(ctx, r.db, account.User, account.Host)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error reading role",
+					"Could not read the attributes of role "+role+", unexpected error: "+err.Error(),
+				)
+				return
+			}
+			if !accountAttributesEqual(state.Attributes, attributes) {
+				state.Attributes = attributes
+			}
+		}
+	}
 
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -108,10 +196,43 @@ func (r *roleResource) Read(ctx context.Context, req resource.ReadRequest, resp
 }
 
 func (r *roleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// No updates possible, needs to recreate
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	// The name needs to recreate, the other attributes change in place
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan, state roleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.alterAttributes(ctx, &state, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
 func (r *roleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var state roleResourceModel
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -119,8 +240,13 @@ func (r *roleResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
+	resp.Diagnostics.Append(checkPreventDestroySQL(ctx, r.db, state.PreventDestroySQL)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	roleName := state.Name.ValueString()
-	_, err := r.db.ExecContext(ctx, fmt.Sprintf("DROP ROLE '%s'", roleName))
+	_, err := execContext(ctx, r.db, sqlgen.DropRole(state.quotedName(r.config), false))
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error deleting role",
@@ -130,7 +256,7 @@ func (r *roleResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 	}
 }
 
-func (r *roleResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+func (r *roleResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
 	}
@@ -144,7 +270,7 @@ func (r *roleResource) Configure(_ context.Context, req resource.ConfigureReques
 		return
 	}
 
-	db, err := config.connectToMySQLNoDb() // Not connecting to a specific database
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to connect to the Cloud SQL MySQL instance",
@@ -154,8 +280,103 @@ func (r *roleResource) Configure(_ context.Context, req resource.ConfigureReques
 	}
 
 	r.db = db
+	r.config = config
+}
+
+func (r *roleResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.config == nil {
+		return
+	}
+
+	var plan roleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if message := r.config.anonymousAccountValueError(plan.Name); message != "" {
+		resp.Diagnostics.AddAttributeError(path.Root("name"), "Anonymous account not allowed", message)
+	}
+	r.config.keepStateAccounts(ctx, req, resp, path.Root("name"))
+}
+
+// alterAttributes changes the comment and attributes of the role account from the state to the plan.
+func (r *roleResource) alterAttributes(ctx context.Context, state, plan *roleResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	patch, err := accountAttributesPatch(state.Comment, plan.Comment, state.Attributes, plan.Attributes)
+	if err != nil {
+		diags.AddError(
+			"Error changing role attributes",
+			"Could not change the attributes of role '"+plan.Name.ValueString()+"', unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+	if patch == "" {
+		return diags
+	}
+
+	_, err = execContext(ctx, r.db, "ALTER USER "+plan.quotedName(r.config)+" ATTRIBUTE "+sqlgen.String(patch))
+	if err != nil {
+		diags.AddError(
+			"Error changing role attributes",
+			"Could not change the attributes of role '"+plan.Name.ValueString()+"', unexpected error: "+err.Error(),
+		)
+	}
+	return diags
+}
+
+// verifyAdoptable checks that an existing account of the role is a role before it is adopted, and warns that it is
+// adopted. Without access to mysql.user the account is adopted without the check.
+func (r *roleResource) verifyAdoptable(ctx context.Context, m *roleResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	account, err := m.Name.Account()
+	if err != nil {
+		return diags
+	}
+	user, host := r.config.canonicalAccount(account.User, account.Host)
+	var accountLocked, authenticationString string
+	err = r.config.queryRowPrepared(ctx, r.db, "SELECT account_locked, authentication_string FROM mysql.user WHERE User = ? AND Host = ?",
+		[]any{user, host}, &accountLocked, &authenticationString)
+	if errors.Is(err, sql.ErrNoRows) {
+		return diags
+	}
+	if err != nil {
+		tflog.Debug(ctx, "Skipping the verification of the existing account of role "+m.quotedName(r.config)+": "+err.Error())
+		return diags
+	}
+
+	if accountLocked != "Y" || authenticationString != "" {
+		diags.AddAttributeError(path.Root("name"),
+			"Existing account is not a role",
+			"The account "+m.quotedName(r.config)+" already exists, but it's a user that can log in. Only roles are adopted with adopt_existing.")
+		return diags
+	}
+	diags.AddWarning(
+		"Existing role adopted",
+		"The role "+m.quotedName(r.config)+" already exists, it is adopted into the state. Its grants are left as they are, "+
+			"and it is dropped when the resource is destroyed",
+	)
+	return diags
 }
 
 type roleResourceModel struct {
-	Name types.String `tfsdk:"name"`
+	Name              AccountValue `tfsdk:"name"`
+	Comment           types.String `tfsdk:"comment"`
+	Attributes        types.String `tfsdk:"attributes"`
+	PreventDestroySQL types.String `tfsdk:"prevent_destroy_sql"`
+	Labels            types.Map    `tfsdk:"labels"`
+	// AdoptExisting only applies to create, an existing role is taken over instead of failing.
+	AdoptExisting types.Bool `tfsdk:"adopt_existing"`
+}
+
+// quotedName returns the quoted account name of the role, names in state from before the name was parsed
+// as an account are quoted as a user with the default host.
+func (m *roleResourceModel) quotedName(config *Config) string {
+	quoted, err := config.quoteAccountValue(m.Name)
+	if err != nil {
+		return config.quoteAccount(m.Name.ValueString(), "%")
+	}
+	return quoted
 }
@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccRoleResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "cloudsqlmysql_role" "test" {
+  name = "tf_acc_role"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsqlmysql_role.test", "name", "tf_acc_role"),
+				),
+			},
+			{
+				Config: `
+resource "cloudsqlmysql_role" "test" {
+  name    = "tf_acc_role"
+  comment = "Acceptance test role"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsqlmysql_role.test", "comment", "Acceptance test role"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,425 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"terraform-provider-cloudsqlmysql/internal/sqlgen"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ resource.Resource               = &schemaBaselineResource{}
+	_ resource.ResourceWithConfigure  = &schemaBaselineResource{}
+	_ resource.ResourceWithModifyPlan = &schemaBaselineResource{}
+)
+
+var (
+	defaultReaderPrivileges = []string{"SELECT", "SHOW VIEW"}
+	defaultWriterPrivileges = []string{"SELECT", "INSERT", "UPDATE", "DELETE", "SHOW VIEW", "EXECUTE", "CREATE TEMPORARY TABLES", "LOCK TABLES"}
+	defaultAdminPrivileges  = []string{"ALL PRIVILEGES"}
+)
+
+type schemaBaselineResource struct {
+	db     dbPool
+	config *Config
+}
+
+func newSchemaBaselineResource() resource.Resource {
+	return &schemaBaselineResource{}
+}
+
+func (r *schemaBaselineResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_schema_baseline"
+}
+
+func (r *schemaBaselineResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	privilegesAttribute := func(tier string, defaults []string) schema.SetAttribute {
+		description := "The privileges on the database granted to the " + tier + " role. Default: " + strings.Join(defaults, ", ")
+		return schema.SetAttribute{
+			Description:         description,
+			MarkdownDescription: description,
+			ElementType:         types.StringType,
+			Optional:            true,
+			Computed:            true,
+			Default:             setdefault.StaticValue(stringSetValue(defaults)),
+			Validators: []validator.Set{
+				setvalidator.SizeAtLeast(1),
+				privilegesValidator{level: levelDatabase, levelName: "database"},
+			},
+		}
+	}
+	roleAttribute := func(tier string) schema.StringAttribute {
+		return schema.StringAttribute{
+			Description:         "The name of the " + tier + " role",
+			MarkdownDescription: "The name of the " + tier + " role",
+			Computed:            true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
+			},
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Provisions the standard reader, writer and admin roles with their grants on a database",
+		MarkdownDescription: "Provisions the standard reader, writer and admin roles with their grants on a database. " +
+			"The roles are named `<role_prefix>_reader`, `<role_prefix>_writer` and `<role_prefix>_admin`",
+		Attributes: map[string]schema.Attribute{
+			"database": schema.StringAttribute{
+				Description:         "The database the roles get privileges on",
+				MarkdownDescription: "The database the roles get privileges on",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_\-]*$`),
+						"`database` must be a correct name of a database"),
+				},
+			},
+			"role_prefix": schema.StringAttribute{
+				Description:         "The prefix of the role names, defaults to the database name",
+				MarkdownDescription: "The prefix of the role names, defaults to the database name",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"reader_privileges": privilegesAttribute("reader", defaultReaderPrivileges),
+			"writer_privileges": privilegesAttribute("writer", defaultWriterPrivileges),
+			"admin_privileges":  privilegesAttribute("admin", defaultAdminPrivileges),
+			"reader_role":       roleAttribute("reader"),
+			"writer_role":       roleAttribute("writer"),
+			"admin_role":        roleAttribute("admin"),
+		},
+	}
+}
+
+func (r *schemaBaselineResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan schemaBaselineResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.RolePrefix.IsUnknown() && !plan.Database.IsUnknown() {
+		plan.RolePrefix = plan.Database
+	}
+	if !plan.RolePrefix.IsUnknown() {
+		plan.ReaderRole = types.StringValue(plan.RolePrefix.ValueString() + "_reader")
+		plan.WriterRole = types.StringValue(plan.RolePrefix.ValueString() + "_writer")
+		plan.AdminRole = types.StringValue(plan.RolePrefix.ValueString() + "_admin")
+	}
+
+	if r.config != nil && !plan.Database.IsUnknown() {
+		if message := r.config.systemSchemaError(plan.Database.ValueString()); message != "" {
+			resp.Diagnostics.AddAttributeError(path.Root("database"), "System schema not allowed", message)
+		}
+	}
+
+	if r.config != nil {
+		for _, tier := range plan.tiers() {
+			for _, privilege := range tier.privileges {
+				if message := r.config.unknownPrivilegeError(privilege.ValueString()); message != "" {
+					resp.Diagnostics.AddAttributeError(path.Root(tier.name+"_privileges"), "Invalid privilege", message)
+				}
+				if message := privilegeVersionError(privilege.ValueString(), r.config.serverVersion); message != "" {
+					resp.Diagnostics.AddAttributeError(path.Root(tier.name+"_privileges"), "Privilege not supported by the server version", message)
+				}
+			}
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+func (r *schemaBaselineResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan schemaBaselineResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, tier := range plan.tiers() {
+		_, err := execContext(ctx, r.db, sqlgen.CreateRole(r.config.quoteAccount(tier.role, "%"), false))
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error creating schema baseline",
+				"Could not create role '"+tier.role+"', unexpected error: "+err.Error(),
+			)
+			return
+		}
+
+		err = r.grant(ctx, plan.Database.ValueString(), tier.role, privilegesAsStrings(tier.privileges))
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error creating schema baseline",
+				"Could not grant privileges to role '"+tier.role+"', unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *schemaBaselineResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withReadTimeout(ctx)
+
+	var state schemaBaselineResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, tier := range state.tiers() {
+		grant, err := readDatabaseGrant(ctx, r.db, r.config, tier.role, "%", state.Database.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading schema baseline",
+				"Could not read the grants of role '"+tier.role+"', unexpected error: "+err.Error(),
+			)
+			return
+		}
+
+		var privileges []types.String
+		if grant != nil {
+			privileges = reconcilePrivileges(tier.privileges, r.config.expandAllPrivileges(privilegesAsStrings(tier.privileges), grant.PrivilegeNames(), levelDatabase))
+		}
+		state.setTierPrivileges(tier.name, privileges)
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *schemaBaselineResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan, state schemaBaselineResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	stateTiers := state.tiers()
+	for i, tier := range plan.tiers() {
+		toRevoke := privilegesDifference(stateTiers[i].privileges, tier.privileges)
+		toGrant := privilegesDifference(tier.privileges, stateTiers[i].privileges)
+
+		if len(toRevoke) > 0 {
+			sqlStatement := sqlgen.Revoke(toRevoke, sqlgen.DatabaseLevel(plan.Database.ValueString()), r.config.quoteAccount(tier.role, "%"))
+			tflog.Debug(ctx, fmt.Sprintf("SQL Statement: \"%s\"", sqlStatement))
+			_, err := execContext(ctx, r.db, sqlStatement)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error updating schema baseline",
+					"Could not revoke privileges from role '"+tier.role+"', unexpected error: "+err.Error(),
+				)
+				return
+			}
+		}
+
+		if len(toGrant) > 0 {
+			err := r.grant(ctx, plan.Database.ValueString(), tier.role, toGrant)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error updating schema baseline",
+					"Could not grant privileges to role '"+tier.role+"', unexpected error: "+err.Error(),
+				)
+				return
+			}
+		}
+	}
+
+	diags := resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *schemaBaselineResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state schemaBaselineResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, tier := range state.tiers() {
+		_, err := execContext(ctx, r.db, sqlgen.DropRole(r.config.quoteAccount(tier.role, "%"), true)) // Dropping the role removes its grants too
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error deleting schema baseline",
+				"Could not delete role '"+tier.role+"', unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+}
+
+func (r *schemaBaselineResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to the Cloud SQL MySQL instance",
+			err.Error(),
+		)
+		return
+	}
+
+	err = config.detectServerSettings(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read the Cloud SQL MySQL server settings",
+			err.Error(),
+		)
+		return
+	}
+
+	r.db = db
+	r.config = config
+}
+
+func (r *schemaBaselineResource) grant(ctx context.Context, database, role string, privileges []string) error {
+	sqlStatement := sqlgen.Grant(privileges, sqlgen.DatabaseLevel(database), r.config.quoteAccount(role, "%"), false)
+	tflog.Debug(ctx, fmt.Sprintf("SQL Statement: \"%s\"", sqlStatement))
+	_, err := execContext(ctx, r.db, sqlStatement)
+	if err != nil || !r.config.verifyAfterApply {
+		return err
+	}
+	return verifyDatabaseGrant(ctx, r.db, r.config, role, "%", database, privileges, nil, false)
+}
+
+type schemaBaselineResourceModel struct {
+	Database         types.String   `tfsdk:"database"`
+	RolePrefix       types.String   `tfsdk:"role_prefix"`
+	ReaderPrivileges []types.String `tfsdk:"reader_privileges"`
+	WriterPrivileges []types.String `tfsdk:"writer_privileges"`
+	AdminPrivileges  []types.String `tfsdk:"admin_privileges"`
+	ReaderRole       types.String   `tfsdk:"reader_role"`
+	WriterRole       types.String   `tfsdk:"writer_role"`
+	AdminRole        types.String   `tfsdk:"admin_role"`
+}
+
+type schemaBaselineTier struct {
+	name       string
+	role       string
+	privileges []types.String
+}
+
+func (m *schemaBaselineResourceModel) tiers() []schemaBaselineTier {
+	return []schemaBaselineTier{
+		{name: "reader", role: m.ReaderRole.ValueString(), privileges: m.ReaderPrivileges},
+		{name: "writer", role: m.WriterRole.ValueString(), privileges: m.WriterPrivileges},
+		{name: "admin", role: m.AdminRole.ValueString(), privileges: m.AdminPrivileges},
+	}
+}
+
+func (m *schemaBaselineResourceModel) setTierPrivileges(tier string, privileges []types.String) {
+	switch tier {
+	case "reader":
+		m.ReaderPrivileges = privileges
+	case "writer":
+		m.WriterPrivileges = privileges
+	case "admin":
+		m.AdminPrivileges = privileges
+	}
+}
+
+func stringSetValue(values []string) types.Set {
+	elements := make([]attr.Value, 0, len(values))
+	for _, value := range values {
+		elements = append(elements, types.StringValue(value))
+	}
+	return types.SetValueMust(types.StringType, elements)
+}
+
+func privilegesAsStrings(privileges []types.String) []string {
+	var values []string
+	for _, privilege := range privileges {
+		values = append(values, privilege.ValueString())
+	}
+	return values
+}
+
+// privilegesDifference returns the privileges of a that are not in b.
+func privilegesDifference(a, b []types.String) []string {
+	var difference []string
+	for _, privilegeA := range a {
+		found := false
+		for _, privilegeB := range b {
+			if privilegeNamesEqual(privilegeA.ValueString(), privilegeB.ValueString()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			difference = append(difference, privilegeA.ValueString())
+		}
+	}
+	return difference
+}
@@ -0,0 +1,330 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"terraform-provider-cloudsqlmysql/internal/sqlgen"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource               = &userPasswordResource{}
+	_ resource.ResourceWithConfigure  = &userPasswordResource{}
+	_ resource.ResourceWithModifyPlan = &userPasswordResource{}
+)
+
+type userPasswordResource struct {
+	db     dbPool
+	config *Config
+}
+
+type userPasswordResourceModel struct {
+	User            types.String `tfsdk:"user"`
+	Host            types.String `tfsdk:"host"`
+	Password        types.String `tfsdk:"password"` // Write-only, always null in the plan and the state
+	RotationTrigger types.String `tfsdk:"rotation_trigger"`
+	// PasswordLastChanged is read from mysql.user to detect passwords changed outside of Terraform.
+	PasswordLastChanged types.String `tfsdk:"password_last_changed"`
+}
+
+func newUserPasswordResource() resource.Resource {
+	return &userPasswordResource{}
+}
+
+func (r *userPasswordResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user_password"
+}
+
+func (r *userPasswordResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Sets the password of an existing user with ALTER USER ... IDENTIFIED BY, e.g. in password rotation pipelines. " +
+			"The password is write-only, it is neither stored in the state nor read back from the server. It is set on create, " +
+			"when rotation_trigger changes and when password_last_changed shows it was changed outside of Terraform. " +
+			"The password is left as is on destroy. Requires Terraform 1.11 or later",
+		MarkdownDescription: "Sets the password of an existing user with `ALTER USER ... IDENTIFIED BY`, e.g. in password rotation pipelines. " +
+			"The password is write-only, it is neither stored in the state nor read back from the server. It is set on create, " +
+			"when `rotation_trigger` changes and when `password_last_changed` shows it was changed outside of Terraform. " +
+			"The password is left as is on destroy. Requires Terraform 1.11 or later",
+		Attributes: map[string]schema.Attribute{
+			"user": schema.StringAttribute{
+				Description:         "The name of the user",
+				MarkdownDescription: "The name of the user",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					userNameValidator{},
+				},
+			},
+			"host": schema.StringAttribute{
+				Description:         "The host of the user. Default: %",
+				MarkdownDescription: "The host of the user. Default: `%`",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("%"),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					hostValidator{},
+				},
+			},
+			"password": schema.StringAttribute{
+				Description: "The password of the user. It is write-only and not stored in the Terraform state, change " +
+					"rotation_trigger to set a changed password",
+				MarkdownDescription: "The password of the user. It is write-only and not stored in the Terraform state, change " +
+					"`rotation_trigger` to set a changed password",
+				Required:  true,
+				Sensitive: true,
+				WriteOnly: true,
+			},
+			"rotation_trigger": schema.StringAttribute{
+				Description:         "An arbitrary value, e.g. a date, the password is set again when it changes",
+				MarkdownDescription: "An arbitrary value, e.g. a date, the password is set again when it changes",
+				Optional:            true,
+			},
+			"password_last_changed": schema.StringAttribute{
+				Description:         "The time the password was last changed in RFC 3339 format, from the password_last_changed column of mysql.user",
+				MarkdownDescription: "The time the password was last changed in RFC 3339 format, from the `password_last_changed` column of `mysql.user`",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *userPasswordResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan userPasswordResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The password is write-only, it is only in the configuration
+	var password types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("password"), &password)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.setPassword(ctx, &plan, password.ValueString())...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *userPasswordResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withReadTimeout(ctx)
+
+	var state userPasswordResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	lastChanged, err := r.passwordLastChanged(ctx, &state)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The user was dropped, its password went with it
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading the user password",
+			"Could not read the password_last_changed of '"+state.User.ValueString()+"'@'"+state.Host.ValueString()+"', unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	// A password_last_changed cleared by an earlier Read stays cleared until the password is set again
+	if !state.PasswordLastChanged.IsNull() && !state.PasswordLastChanged.Equal(lastChanged) {
+		// The password is not in the state, clearing password_last_changed makes ModifyPlan plan an update that sets
+		// the configured password again
+		resp.Diagnostics.AddWarning(
+			"Password changed outside of Terraform",
+			"The password of '"+state.User.ValueString()+"'@'"+state.Host.ValueString()+"' was changed at "+lastChanged.ValueString()+
+				", it is set again on the next apply",
+		)
+		state.PasswordLastChanged = types.StringNull()
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *userPasswordResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan userPasswordResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var password types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("password"), &password)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The user and host need to recreate, a change of the rotation trigger or a password changed outside of
+	// Terraform sets the password
+	resp.Diagnostics.Append(r.setPassword(ctx, &plan, password.ValueString())...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *userPasswordResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// The password is left as is, MySQL has no way to remove it without dropping the user
+}
+
+func (r *userPasswordResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to the Cloud SQL MySQL instance",
+			err.Error(),
+		)
+		return
+	}
+
+	err = config.detectServerSettings(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read the Cloud SQL MySQL server settings",
+			err.Error(),
+		)
+		return
+	}
+
+	r.db = db
+	r.config = config
+}
+
+func (r *userPasswordResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.config == nil {
+		return
+	}
+
+	var plan userPasswordResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.User.IsUnknown() && !plan.Host.IsUnknown() {
+		if message := r.config.anonymousAccountError(plan.User.ValueString(), plan.Host.ValueString()); message != "" {
+			resp.Diagnostics.AddAttributeError(path.Root("user"), "Anonymous account not allowed", message)
+		}
+	}
+
+	if req.State.Raw.IsNull() {
+		return
+	}
+	// ALTER USER always sets password_last_changed, Read clears it when the password was changed outside of Terraform
+	var lastChanged types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("password_last_changed"), &lastChanged)...)
+	if !resp.Diagnostics.HasError() && lastChanged.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("password_last_changed"), types.StringUnknown())...)
+	}
+}
+
+// setPassword sets the password of the user and reads back when it was changed.
+func (r *userPasswordResource) setPassword(ctx context.Context, m *userPasswordResourceModel, password string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	account := m.User.ValueString() + "@" + m.Host.ValueString()
+	// The statement contains the password, it is only logged redacted by log_sql
+	_, err := execContext(ctx, r.db, sqlgen.AlterUserPassword(r.config.quoteAccount(m.User.ValueString(), m.Host.ValueString()), password))
+	if err != nil {
+		diags.AddError(
+			"Error setting the user password",
+			"Could not alter user '"+account+"', unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+
+	m.PasswordLastChanged, err = r.passwordLastChanged(ctx, m)
+	if err != nil {
+		diags.AddError(
+			"Error setting the user password",
+			"Could not read the password_last_changed of '"+account+"', unexpected error: "+err.Error(),
+		)
+	}
+	return diags
+}
+
+// passwordLastChanged reads when the password of the user was changed, sql.ErrNoRows is returned when the user
+// doesn't exist.
+func (r *userPasswordResource) passwordLastChanged(ctx context.Context, m *userPasswordResourceModel) (types.String, error) {
+	user, host := r.config.canonicalAccount(m.User.ValueString(), m.Host.ValueString())
+	// UNIX_TIMESTAMP doesn't depend on the time_zone of the session
+	var lastChanged sql.NullInt64
+	err := r.config.queryRowPrepared(ctx, r.db, "SELECT UNIX_TIMESTAMP(password_last_changed) FROM mysql.user WHERE User = ? AND Host = ?",
+		[]any{user, host}, &lastChanged)
+	if err != nil {
+		return types.StringNull(), err
+	}
+	if !lastChanged.Valid {
+		return types.StringNull(), nil
+	}
+	return types.StringValue(time.Unix(lastChanged.Int64, 0).UTC().Format(time.RFC3339)), nil
+}
@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+
+	secretmanager "google.golang.org/api/secretmanager/v1"
+)
+
+// secretManagerPrefix marks a password that is a reference to a Secret Manager secret version.
+const secretManagerPrefix = "sm://"
+
+var secretVersionNameRegex = regexp.MustCompile(`^projects/[^/]+/secrets/[^/]+/versions/[^/]+$`)
+
+// isSecretManagerReference checks if the value is a sm://projects/p/secrets/name/versions/v reference.
+func isSecretManagerReference(value string) bool {
+	return strings.HasPrefix(value, secretManagerPrefix)
+}
+
+// accessSecretVersion resolves a sm:// reference to the payload of the secret version, with the application default
+// credentials.
+func accessSecretVersion(ctx context.Context, reference string) (string, error) {
+	name := strings.TrimPrefix(reference, secretManagerPrefix)
+	if !secretVersionNameRegex.MatchString(name) {
+		return "", fmt.Errorf("the secret reference '%s' is invalid, expected sm://projects/<project>/secrets/<secret>/versions/<version>", reference)
+	}
+
+	service, err := secretmanager.NewService(ctx)
+	if err != nil {
+		return "", err
+	}
+	response, err := service.Projects.Secrets.Versions.Access(name).Context(ctx).Do()
+	if err != nil {
+		return "", err
+	}
+	if response.Payload == nil {
+		return "", fmt.Errorf("the secret version '%s' has no payload", name)
+	}
+	data, err := base64.StdEncoding.DecodeString(response.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("could not decode the payload of the secret version '%s': %w", name, err)
+	}
+	return string(data), nil
+}
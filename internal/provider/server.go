@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	datasourceschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	resourceschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-mux/tf5muxserver"
+	"github.com/hashicorp/terraform-plugin-mux/tf6muxserver"
+)
+
+// NewProtocol6 returns the muxed protocol version 6 server of the provider, the signature matches the
+// ProtoV6ProviderFactories of terraform-plugin-testing.
+func NewProtocol6(version string, options ...Option) func() (tfprotov6.ProviderServer, error) {
+	return func() (tfprotov6.ProviderServer, error) {
+		muxServer, err := tf6muxserver.NewMuxServer(context.Background(), providerserver.NewProtocol6(New(version, options...)()))
+		if err != nil {
+			return nil, err
+		}
+		return muxServer.ProviderServer(), nil
+	}
+}
+
+// NewProtocol5 returns the muxed protocol version 5 server of the provider, the signature matches the
+// ProtoV5ProviderFactories of terraform-plugin-testing. Protocol version 5 has no nested attributes, the resources
+// and data sources that use them are rejected when the server starts, Protocol5Unsupported lists them.
+func NewProtocol5(version string, options ...Option) func() (tfprotov5.ProviderServer, error) {
+	options = append(options, func(p *CloudSqlMysqlProvider) {
+		p.protocol5 = true
+	})
+	return func() (tfprotov5.ProviderServer, error) {
+		if unsupported := Protocol5Unsupported(); len(unsupported) > 0 {
+			log.Printf("[WARN] Protocol version 5 doesn't support nested attributes, these resources and data sources are "+
+				"not served: %s. Use protocol version 6 to manage them.", strings.Join(unsupported, ", "))
+		}
+		muxServer, err := tf5muxserver.NewMuxServer(context.Background(), providerserver.NewProtocol5(New(version, options...)()))
+		if err != nil {
+			return nil, err
+		}
+		return muxServer.ProviderServer(), nil
+	}
+}
+
+// Protocol5Unsupported returns the type names of the resources and data sources that have nested attributes,
+// protocol version 5 can't serve them.
+func Protocol5Unsupported() []string {
+	ctx := context.Background()
+	p := &CloudSqlMysqlProvider{}
+	_, unsupported := protocol5Resources(ctx, p.Resources(ctx))
+	_, unsupportedDataSources := protocol5DataSources(ctx, p.DataSources(ctx))
+	return append(unsupported, unsupportedDataSources...)
+}
+
+// protocol5Resources splits the resources into the resources without nested attributes and the type names of the
+// other resources.
+func protocol5Resources(ctx context.Context, resources []func() resource.Resource) (supported []func() resource.Resource, unsupported []string) {
+	for _, newResource := range resources {
+		r := newResource()
+		var metadata resource.MetadataResponse
+		r.Metadata(ctx, resource.MetadataRequest{ProviderTypeName: "cloudsqlmysql"}, &metadata)
+		var schema resource.SchemaResponse
+		r.Schema(ctx, resource.SchemaRequest{}, &schema)
+
+		nested := false
+		for _, attribute := range schema.Schema.Attributes {
+			switch attribute.(type) {
+			case resourceschema.ListNestedAttribute, resourceschema.SetNestedAttribute, resourceschema.MapNestedAttribute, resourceschema.SingleNestedAttribute:
+				nested = true
+			}
+		}
+		if nested {
+			unsupported = append(unsupported, metadata.TypeName)
+			continue
+		}
+		supported = append(supported, newResource)
+	}
+	return supported, unsupported
+}
+
+// protocol5DataSources splits the data sources into the data sources without nested attributes and the type names
+// of the other data sources.
+func protocol5DataSources(ctx context.Context, dataSources []func() datasource.DataSource) (supported []func() datasource.DataSource, unsupported []string) {
+	for _, newDataSource := range dataSources {
+		d := newDataSource()
+		var metadata datasource.MetadataResponse
+		d.Metadata(ctx, datasource.MetadataRequest{ProviderTypeName: "cloudsqlmysql"}, &metadata)
+		var schema datasource.SchemaResponse
+		d.Schema(ctx, datasource.SchemaRequest{}, &schema)
+
+		nested := false
+		for _, attribute := range schema.Schema.Attributes {
+			switch attribute.(type) {
+			case datasourceschema.ListNestedAttribute, datasourceschema.SetNestedAttribute, datasourceschema.MapNestedAttribute, datasourceschema.SingleNestedAttribute:
+				nested = true
+			}
+		}
+		if nested {
+			unsupported = append(unsupported, metadata.TypeName)
+			continue
+		}
+		supported = append(supported, newDataSource)
+	}
+	return supported, unsupported
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+func TestNewProtocol6Schema(t *testing.T) {
+	server, err := NewProtocol6("test")()
+	if err != nil {
+		t.Fatalf("NewProtocol6 returned error: %v", err)
+	}
+	resp, err := server.GetProviderSchema(context.Background(), &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("GetProviderSchema returned error: %v", err)
+	}
+	for _, diagnostic := range resp.Diagnostics {
+		t.Errorf("GetProviderSchema returned %s: %s", diagnostic.Summary, diagnostic.Detail)
+	}
+	if len(resp.ResourceSchemas) == 0 || len(resp.DataSourceSchemas) == 0 {
+		t.Errorf("GetProviderSchema returned %d resources and %d data sources", len(resp.ResourceSchemas), len(resp.DataSourceSchemas))
+	}
+}
+
+func TestNewProtocol5Schema(t *testing.T) {
+	server, err := NewProtocol5("test")()
+	if err != nil {
+		t.Fatalf("NewProtocol5 returned error: %v", err)
+	}
+	resp, err := server.GetProviderSchema(context.Background(), &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("GetProviderSchema returned error: %v", err)
+	}
+	for _, diagnostic := range resp.Diagnostics {
+		t.Errorf("GetProviderSchema returned %s: %s", diagnostic.Summary, diagnostic.Detail)
+	}
+	for _, typeName := range Protocol5Unsupported() {
+		if resp.ResourceSchemas[typeName] != nil || resp.DataSourceSchemas[typeName] != nil {
+			t.Errorf("GetProviderSchema of protocol version 5 returned %s, it has nested attributes", typeName)
+		}
+	}
+	if len(resp.ResourceSchemas) == 0 || len(resp.DataSourceSchemas) == 0 {
+		t.Errorf("GetProviderSchema returned %d resources and %d data sources", len(resp.ResourceSchemas), len(resp.DataSourceSchemas))
+	}
+}
@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// statementLogKey is the context key that enables logStatement for the statements of an operation.
+type statementLogKey struct{}
+
+// withStatementLog enables logging the statements of the operation when the provider is configured with log_sql.
+func (c *Config) withStatementLog(ctx context.Context) context.Context {
+	if !c.logSQL {
+		return ctx
+	}
+	return context.WithValue(ctx, statementLogKey{}, true)
+}
+
+// sensitiveLiteralRegex matches the string literals of passwords in account management statements.
+var sensitiveLiteralRegex = regexp.MustCompile(`(?i)(IDENTIFIED\s+(?:WITH\s+\S+\s+)?(?:BY|AS)\s+(?:RANDOM\s+PASSWORD\s+)?|PASSWORD\s*(?:=\s*|\(\s*)?|REPLACE\s+)'(?:[^'\\]|\\.|'')*'`)
+
+// redactStatement replaces password literals in the statement, parameter values are never logged.
+func redactStatement(query string) string {
+	return sensitiveLiteralRegex.ReplaceAllString(query, "$1'<redacted>'")
+}
+
+// logStatement logs the executed statement at INFO level when log_sql is enabled for the context, and counts it for
+// the SQL summary.
+// The result is nil for queries.
+func logStatement(ctx context.Context, query string, parameters int, start time.Time, result sql.Result, err error) {
+	recordStatement(start, err)
+	if enabled, _ := ctx.Value(statementLogKey{}).(bool); !enabled {
+		return
+	}
+
+	fields := map[string]any{
+		"statement":   redactStatement(query),
+		"parameters":  parameters,
+		"duration_ms": time.Since(start).Milliseconds(),
+	}
+	if result != nil {
+		if rowsAffected, rowsErr := result.RowsAffected(); rowsErr == nil {
+			fields["rows_affected"] = rowsAffected
+		}
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	tflog.Info(ctx, "Executed SQL statement", fields)
+}
+
+// withModuleName adds the module_name of the provider_meta block of the module to the log fields of the context,
+// so the statements logged by log_sql can be attributed to the module that manages them.
+func withModuleName(ctx context.Context, providerMeta tfsdk.Config) context.Context {
+	if providerMeta.Raw.IsNull() {
+		return ctx
+	}
+	var moduleName types.String
+	diags := providerMeta.GetAttribute(ctx, path.Root("module_name"), &moduleName)
+	if diags.HasError() || moduleName.IsNull() || moduleName.IsUnknown() {
+		return ctx
+	}
+	return tflog.SetField(ctx, "module_name", moduleName.ValueString())
+}
@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// sqlMetrics are the counters of the process for the summary logged when the provider server stops. They cover all
+// configured providers.
+var sqlMetrics struct {
+	statements atomic.Int64
+	failed     atomic.Int64
+	retries    atomic.Int64
+	sqlTime    atomic.Int64 // nanoseconds
+}
+
+// recordStatement counts an executed statement and its duration.
+func recordStatement(start time.Time, err error) {
+	sqlMetrics.statements.Add(1)
+	sqlMetrics.sqlTime.Add(int64(time.Since(start)))
+	if err != nil {
+		sqlMetrics.failed.Add(1)
+	}
+}
+
+// recordRetry counts a retried dial or statement.
+func recordRetry() {
+	sqlMetrics.retries.Add(1)
+}
+
+// sqlSummaryRequested checks if the summary is logged, with log_sql of one of the configured providers or the
+// CLOUDSQL_MYSQL_SQL_SUMMARY environment variable.
+func sqlSummaryRequested() bool {
+	if requested, _ := strconv.ParseBool(os.Getenv("CLOUDSQL_MYSQL_SQL_SUMMARY")); requested {
+		return true
+	}
+	openConfigsMutex.Lock()
+	defer openConfigsMutex.Unlock()
+	for _, c := range openConfigs {
+		if c.logSQL {
+			return true
+		}
+	}
+	return false
+}
+
+// SQLSummary returns the summary of the statements executed by the process, or an empty string when it isn't
+// requested. The connections opened are read from the pools, so it is called before CloseConnections.
+func SQLSummary() string {
+	if !sqlSummaryRequested() {
+		return ""
+	}
+
+	var opened int64
+	openConfigsMutex.Lock()
+	for _, c := range openConfigs {
+		for _, pool := range c.connectionStats() {
+			// The pools only count the connections they closed and the open ones
+			opened += int64(pool.stats.OpenConnections) + pool.stats.MaxIdleClosed + pool.stats.MaxIdleTimeClosed + pool.stats.MaxLifetimeClosed
+		}
+	}
+	openConfigsMutex.Unlock()
+
+	return fmt.Sprintf("SQL summary: %d statements executed, %d failed, %d retries, %s total SQL time, %d connections opened",
+		sqlMetrics.statements.Load(), sqlMetrics.failed.Load(), sqlMetrics.retries.Load(),
+		time.Duration(sqlMetrics.sqlTime.Load()).Round(time.Millisecond), opened)
+}
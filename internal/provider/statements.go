@@ -0,0 +1,188 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// killQueryTimeout limits how long we wait for KILL QUERY, Terraform is already shutting down at that point.
+const killQueryTimeout = 10 * time.Second
+
+// statementTimeoutKey is the context key of the timeout of the statements of an operation.
+type statementTimeoutKey struct{}
+
+// withStatementTimeout sets the timeout of every statement of the operation, without a timeout the statements are
+// only limited by the context of the operation.
+func withStatementTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	if timeout == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, statementTimeoutKey{}, timeout)
+}
+
+// statementContext returns the context of a single statement, it is canceled after the statement timeout of the
+// operation.
+func statementContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout, ok := ctx.Value(statementTimeoutKey{}).(time.Duration); ok {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// sqlWarningsKey is the context key of the collector of the warnings of the statements of an operation.
+type sqlWarningsKey struct{}
+
+// sqlWarnings collects the warnings of the executed statements, set with surface_sql_warnings.
+type sqlWarnings struct {
+	mutex sync.Mutex
+	diags diag.Diagnostics
+}
+
+// appendTo adds the collected warnings to the diagnostics of the operation, a nil collector adds nothing.
+func (w *sqlWarnings) appendTo(diags *diag.Diagnostics) {
+	if w == nil {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	diags.Append(w.diags...)
+}
+
+// readWarnings reads the warnings of the last statement on the connection with SHOW WARNINGS when the operation
+// collects them. The warnings are only reported, reading them never fails the statement.
+func readWarnings(ctx context.Context, conn *sql.Conn, query string) {
+	warnings, ok := ctx.Value(sqlWarningsKey{}).(*sqlWarnings)
+	if !ok {
+		return
+	}
+
+	rows, err := conn.QueryContext(ctx, "SHOW WARNINGS")
+	if err != nil {
+		tflog.Debug(ctx, "Unable to read the warnings of the statement: "+err.Error())
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var level, message string
+		var code int
+		if err = rows.Scan(&level, &code, &message); err != nil {
+			tflog.Debug(ctx, "Unable to read the warnings of the statement: "+err.Error())
+			return
+		}
+		warnings.mutex.Lock()
+		warnings.diags.AddWarning(
+			"MySQL "+level+" "+fmt.Sprint(code),
+			message+"\n\nStatement: "+redactStatement(query),
+		)
+		warnings.mutex.Unlock()
+	}
+}
+
+// execContext executes the statement on a dedicated connection. When the context is canceled before the statement
+// finishes, the statement is killed server-side with KILL QUERY using another connection. The driver only closes
+// its side of the connection on cancellation, which leaves GRANTs waiting on metadata locks running on the server.
+func execContext(ctx context.Context, db dbPool, query string, args ...any) (sql.Result, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return execOnConn(ctx, db, conn, query, args...)
+}
+
+// execOnConn executes the statement like execContext on a connection of the pool, for statements that need
+// to run on the same connection as the statements after it, e.g. to read session variables set by a procedure.
+// The SELECT CONNECTION_ID() and SHOW WARNINGS around the statement are part of it and not logged on their own.
+func execOnConn(ctx context.Context, db dbPool, conn *sql.Conn, query string, args ...any) (sql.Result, error) {
+	ctx, cancel := statementContext(ctx)
+	defer cancel()
+
+	var connectionID int64
+	err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&connectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	watcherDone := make(chan struct{})
+	go func() {
+		defer close(watcherDone)
+		select {
+		case <-done:
+		case <-ctx.Done():
+			killCtx, cancel := context.WithTimeout(context.Background(), killQueryTimeout)
+			defer cancel()
+			killQuery := fmt.Sprintf("KILL QUERY %d", connectionID)
+			start := time.Now()
+			result, err := db.ExecContext(killCtx, killQuery)
+			logStatement(ctx, killQuery, 0, start, result, err)
+			if err != nil {
+				tflog.Debug(ctx, fmt.Sprintf("Unable to kill query on connection %d: %s", connectionID, err.Error()))
+				return
+			}
+			tflog.Info(ctx, fmt.Sprintf("Killed query on connection %d because the operation was canceled or timed out", connectionID))
+		}
+	}()
+
+	start := time.Now()
+	result, err := conn.ExecContext(ctx, query, args...)
+	logStatement(ctx, query, len(args), start, result, err)
+	if err == nil {
+		readWarnings(ctx, conn, query)
+	}
+	close(done)
+	<-watcherDone // The connection can't go back to the pool while a KILL QUERY for it is in flight
+	return result, err
+}
+
+// queryContext runs the query like QueryContext and logs it with log_sql, all queries of the provider go through it
+// or the helpers below. The caller closes the rows.
+func queryContext(ctx context.Context, db dbExecutor, query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := db.QueryContext(ctx, query, args...)
+	logStatement(ctx, query, len(args), start, nil, err)
+	return rows, err
+}
+
+// queryRow runs the query returning a single row and scans the row into dest, sql.ErrNoRows when it returns no rows.
+func queryRow(ctx context.Context, db dbExecutor, query string, args []any, dest ...any) error {
+	ctx, cancel := statementContext(ctx)
+	defer cancel()
+
+	start := time.Now()
+	err := db.QueryRowContext(ctx, query, args...).Scan(dest...)
+	logErr := err
+	if errors.Is(err, sql.ErrNoRows) {
+		logErr = nil // An empty result is not a failed statement
+	}
+	logStatement(ctx, query, len(args), start, nil, logErr)
+	return err
+}
+
+// queryRows runs the query and calls scan for every row.
+func queryRows(ctx context.Context, db dbExecutor, query string, args []any, scan func(rows *sql.Rows) error) error {
+	ctx, cancel := statementContext(ctx)
+	defer cancel()
+
+	rows, err := queryContext(ctx, db, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestExecContext(t *testing.T) {
+	db, mock := newMockDB(t)
+	expectConnectionID(mock)
+	mock.ExpectExec("GRANT SELECT ON `app`.* TO 'app'@'%'").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if _, err := execContext(context.Background(), db, "GRANT SELECT ON `app`.* TO 'app'@'%'"); err != nil {
+		t.Errorf("execContext returned error: %v", err)
+	}
+}
+
+func TestExecContextError(t *testing.T) {
+	db, mock := newMockDB(t)
+	denied := &mysql.MySQLError{Number: 1044, Message: "Access denied for user 'provider'@'%' to database 'app'"}
+	expectConnectionID(mock)
+	mock.ExpectExec("GRANT SELECT ON `app`.* TO 'app'@'%'").WillReturnError(denied)
+
+	_, err := execContext(context.Background(), db, "GRANT SELECT ON `app`.* TO 'app'@'%'")
+	if !errors.Is(err, denied) {
+		t.Errorf("execContext returned %v, want %v", err, denied)
+	}
+}
+
+func TestExecContextConnectionIDError(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery("SELECT CONNECTION_ID()").WillReturnError(mysql.ErrInvalidConn)
+
+	// The statement is not executed without the connection id to kill it with
+	if _, err := execContext(context.Background(), db, "DROP USER 'app'@'%'"); !errors.Is(err, mysql.ErrInvalidConn) {
+		t.Errorf("execContext returned %v, want %v", err, mysql.ErrInvalidConn)
+	}
+}
+
+func TestQueryRow(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery("SELECT @@GLOBAL.performance_schema").WillReturnRows(sqlmock.NewRows([]string{"enabled"}).AddRow(1))
+	mock.ExpectQuery("SELECT ATTRIBUTE FROM INFORMATION_SCHEMA.USER_ATTRIBUTES WHERE USER = ? AND HOST = ?").
+		WithArgs("app", "%").WillReturnRows(sqlmock.NewRows([]string{"ATTRIBUTE"}))
+
+	var enabled bool
+	if err := queryRow(context.Background(), db, "SELECT @@GLOBAL.performance_schema", nil, &enabled); err != nil || !enabled {
+		t.Errorf("queryRow returned %t, %v", enabled, err)
+	}
+
+	attributes, err := (&Config{}).queryAccountAttributes(context.Background(), db, "app", "%")
+	if err != nil || attributes != nil {
+		t.Errorf("queryAccountAttributes without a row returned %v, %v", attributes, err)
+	}
+}
+
+func TestQueryRowNoRows(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery("SELECT 1 FROM mysql.user WHERE User = ?").WithArgs("app").WillReturnRows(sqlmock.NewRows([]string{"1"}))
+
+	var found int
+	err := queryRow(context.Background(), db, "SELECT 1 FROM mysql.user WHERE User = ?", []any{"app"}, &found)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("queryRow returned %v, want %v", err, sql.ErrNoRows)
+	}
+}
+
+func TestQueryRows(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery("SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ?").WithArgs("app").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("orders").AddRow("users"))
+	mock.ExpectQuery("SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ?").WithArgs("app").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("orders").RowError(0, mysql.ErrInvalidConn))
+
+	var tables []string
+	scan := func(rows *sql.Rows) error {
+		var table string
+		err := rows.Scan(&table)
+		tables = append(tables, table)
+		return err
+	}
+	err := queryRows(context.Background(), db, "SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ?", []any{"app"}, scan)
+	if err != nil || len(tables) != 2 || tables[0] != "orders" || tables[1] != "users" {
+		t.Errorf("queryRows returned %v, %v", tables, err)
+	}
+
+	err = queryRows(context.Background(), db, "SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ?", []any{"app"}, scan)
+	if !errors.Is(err, mysql.ErrInvalidConn) {
+		t.Errorf("queryRows returned %v, want %v", err, mysql.ErrInvalidConn)
+	}
+}
+
+func TestAccountDropped(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery("SHOW GRANTS FOR 'app'@'%'").WillReturnError(&mysql.MySQLError{Number: mysqlErrNonexistingGrant, Message: "There is no such grant defined"})
+	expectShowGrants(mock, "'app'@'%'", "GRANT USAGE ON *.* TO `app`@`%`")
+	mock.ExpectQuery("SHOW GRANTS FOR 'app'@'%'").WillReturnError(mysql.ErrInvalidConn)
+
+	config := &Config{}
+	if !config.accountDropped(context.Background(), db, "app", "%") {
+		t.Error("accountDropped returned false for ER_NONEXISTING_GRANT")
+	}
+	if config.accountDropped(context.Background(), db, "app", "%") {
+		t.Error("accountDropped returned true for an existing account")
+	}
+	if config.accountDropped(context.Background(), db, "app", "%") {
+		t.Error("accountDropped returned true for a connection error")
+	}
+}
+
+func TestCheckPreventDestroySQL(t *testing.T) {
+	db, mock := newMockDB(t)
+	query := "SELECT COUNT(*) AS sessions FROM INFORMATION_SCHEMA.PROCESSLIST WHERE USER = 'app' HAVING sessions > 0"
+	mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"sessions"}))
+	mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"sessions"}).AddRow(3))
+	mock.ExpectQuery(query).WillReturnError(errors.New("syntax error"))
+
+	if diags := checkPreventDestroySQL(context.Background(), db, types.StringValue(query)); diags.HasError() {
+		t.Errorf("checkPreventDestroySQL without rows returned %v", diags)
+	}
+	if diags := checkPreventDestroySQL(context.Background(), db, types.StringValue(query)); !diags.HasError() {
+		t.Error("checkPreventDestroySQL with rows returned no error")
+	}
+	if diags := checkPreventDestroySQL(context.Background(), db, types.StringValue(query)); !diags.HasError() {
+		t.Error("checkPreventDestroySQL with a failing query returned no error")
+	}
+}
@@ -0,0 +1,136 @@
+// Package sqlgen builds the account management statements executed by the provider.
+//
+// The builders only quote and join, account names need to be canonicalized by the caller:
+//
+//	sqlgen.Grant([]string{"SELECT", "INSERT"}, sqlgen.DatabaseLevel("db"), sqlgen.Account("user", "%"), false)
+//	// GRANT SELECT, INSERT ON `db`.* TO 'user'@'%'
+package sqlgen
+
+import (
+	"strings"
+)
+
+// GlobalLevel is the privilege level of global privileges.
+const GlobalLevel = "*.*"
+
+// String quotes the value as a MySQL string literal.
+func String(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// Identifier quotes the value as a MySQL identifier.
+func Identifier(value string) string {
+	return "`" + strings.ReplaceAll(value, "`", "``") + "`"
+}
+
+// Account quotes the user and host as a MySQL account name.
+func Account(user, host string) string {
+	return String(user) + "@" + String(host)
+}
+
+// DatabaseLevel returns the privilege level of all objects in the database.
+func DatabaseLevel(database string) string {
+	return Identifier(database) + ".*"
+}
+
+// TableLevel returns the privilege level of a table in the database.
+func TableLevel(database, table string) string {
+	return Identifier(database) + "." + Identifier(table)
+}
+
+// RoutineLevel returns the privilege level of a routine, the object type is FUNCTION or PROCEDURE.
+func RoutineLevel(objectType, database, routine string) string {
+	return objectType + " " + TableLevel(database, routine)
+}
+
+// ColumnPrivilege returns a column level privilege like SELECT (`a`, `b`).
+func ColumnPrivilege(privilege string, columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = Identifier(column)
+	}
+	return privilege + " (" + strings.Join(quoted, ", ") + ")"
+}
+
+// Grant returns a GRANT statement of the privileges on the level.
+func Grant(privileges []string, level, grantee string, withGrantOption bool) string {
+	statement := "GRANT " + strings.Join(privileges, ", ") + " ON " + level + " TO " + grantee
+	if withGrantOption {
+		statement += " WITH GRANT OPTION"
+	}
+	return statement
+}
+
+// Revoke returns a REVOKE statement of the privileges on the level.
+func Revoke(privileges []string, level, grantee string) string {
+	return "REVOKE " + strings.Join(privileges, ", ") + " ON " + level + " FROM " + grantee
+}
+
+// GrantRoles returns a GRANT statement of the roles, the roles need to be quoted accounts.
+func GrantRoles(roles []string, grantee string, withAdminOption bool) string {
+	statement := "GRANT " + strings.Join(roles, ", ") + " TO " + grantee
+	if withAdminOption {
+		statement += " WITH ADMIN OPTION"
+	}
+	return statement
+}
+
+// RevokeRoles returns a REVOKE statement of the roles, the roles need to be quoted accounts.
+func RevokeRoles(roles []string, grantee string) string {
+	return "REVOKE " + strings.Join(roles, ", ") + " FROM " + grantee
+}
+
+// CreateRole returns a CREATE ROLE statement, the role needs to be a quoted account.
+func CreateRole(role string, ifNotExists bool) string {
+	if ifNotExists {
+		return "CREATE ROLE IF NOT EXISTS " + role
+	}
+	return "CREATE ROLE " + role
+}
+
+// DropRole returns a DROP ROLE statement, the role needs to be a quoted account.
+func DropRole(role string, ifExists bool) string {
+	if ifExists {
+		return "DROP ROLE IF EXISTS " + role
+	}
+	return "DROP ROLE " + role
+}
+
+// AlterUserPassword returns an ALTER USER statement that sets the password, the user needs to be a quoted account.
+// The password is a literal, MySQL doesn't accept placeholders in IDENTIFIED BY.
+func AlterUserPassword(user, password string) string {
+	return "ALTER USER " + user + " IDENTIFIED BY " + String(password)
+}
+
+// CreateUser returns a CREATE USER statement with the password, the user needs to be a quoted account.
+func CreateUser(user, password string) string {
+	return "CREATE USER " + user + " IDENTIFIED BY " + String(password)
+}
+
+// DropUser returns a DROP USER statement, the user needs to be a quoted account.
+func DropUser(user string, ifExists bool) string {
+	if ifExists {
+		return "DROP USER IF EXISTS " + user
+	}
+	return "DROP USER " + user
+}
+
+// CreateIndex returns a CREATE INDEX statement of the columns on the table, the table needs to be a quoted
+// database and table.
+func CreateIndex(name, table string, columns []string, unique bool) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = Identifier(column)
+	}
+	statement := "CREATE INDEX "
+	if unique {
+		statement = "CREATE UNIQUE INDEX "
+	}
+	return statement + Identifier(name) + " ON " + table + " (" + strings.Join(quoted, ", ") + ")"
+}
+
+// DropIndex returns a DROP INDEX statement, the table needs to be a quoted database and table.
+func DropIndex(name, table string) string {
+	return "DROP INDEX " + Identifier(name) + " ON " + table
+}
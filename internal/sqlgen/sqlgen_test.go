@@ -0,0 +1,223 @@
+package sqlgen
+
+import "testing"
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: "''"},
+		{value: "app", want: "'app'"},
+		{value: "o'brien", want: "'o''brien'"},
+		{value: `back\slash`, want: `'back\\slash'`},
+		{value: `\'`, want: `'\\'''`},
+		{value: "back`tick", want: "'back`tick'"},
+		{value: "10.0.0.%", want: "'10.0.0.%'"},
+		{value: "new\nline", want: "'new\nline'"},
+	}
+	for _, test := range tests {
+		if got := String(test.value); got != test.want {
+			t.Errorf("String(%q) = %s, want %s", test.value, got, test.want)
+		}
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: "``"},
+		{value: "app", want: "`app`"},
+		{value: "back`tick", want: "`back``tick`"},
+		{value: "``", want: "``````"},
+		{value: "o'brien", want: "`o'brien`"},
+		{value: `back\slash`, want: "`back\\slash`"},
+		{value: "my_app", want: "`my_app`"},
+	}
+	for _, test := range tests {
+		if got := Identifier(test.value); got != test.want {
+			t.Errorf("Identifier(%q) = %s, want %s", test.value, got, test.want)
+		}
+	}
+}
+
+func TestAccount(t *testing.T) {
+	tests := []struct {
+		user, host string
+		want       string
+	}{
+		{user: "app", host: "%", want: "'app'@'%'"},
+		{user: "app", host: "10.0.0.0/255.255.255.0", want: "'app'@'10.0.0.0/255.255.255.0'"},
+		{user: "o'brien", host: "local'host", want: "'o''brien'@'local''host'"},
+		{user: `a\b`, host: "%", want: `'a\\b'@'%'`},
+		{user: "", host: "", want: "''@''"},
+	}
+	for _, test := range tests {
+		if got := Account(test.user, test.host); got != test.want {
+			t.Errorf("Account(%q, %q) = %s, want %s", test.user, test.host, got, test.want)
+		}
+	}
+}
+
+func TestLevels(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "database", got: DatabaseLevel("app"), want: "`app`.*"},
+		{name: "quoted database", got: DatabaseLevel("we`ird"), want: "`we``ird`.*"},
+		{name: "table", got: TableLevel("app", "users"), want: "`app`.`users`"},
+		{name: "quoted table", got: TableLevel("app", "o`rders"), want: "`app`.`o``rders`"},
+		{name: "procedure", got: RoutineLevel("PROCEDURE", "app", "refresh"), want: "PROCEDURE `app`.`refresh`"},
+		{name: "function", got: RoutineLevel("FUNCTION", "app", "to`tal"), want: "FUNCTION `app`.`to``tal`"},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s level = %s, want %s", test.name, test.got, test.want)
+		}
+	}
+}
+
+func TestColumnPrivilege(t *testing.T) {
+	tests := []struct {
+		privilege string
+		columns   []string
+		want      string
+	}{
+		{privilege: "SELECT", columns: []string{"id"}, want: "SELECT (`id`)"},
+		{privilege: "UPDATE", columns: []string{"id", "name"}, want: "UPDATE (`id`, `name`)"},
+		{privilege: "INSERT", columns: []string{"we`ird"}, want: "INSERT (`we``ird`)"},
+	}
+	for _, test := range tests {
+		if got := ColumnPrivilege(test.privilege, test.columns); got != test.want {
+			t.Errorf("ColumnPrivilege(%q, %q) = %s, want %s", test.privilege, test.columns, got, test.want)
+		}
+	}
+}
+
+func TestGrantAndRevoke(t *testing.T) {
+	account := Account("app", "%")
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{
+			name: "grant single privilege",
+			got:  Grant([]string{"SELECT"}, DatabaseLevel("app"), account, false),
+			want: "GRANT SELECT ON `app`.* TO 'app'@'%'",
+		},
+		{
+			name: "grant privileges",
+			got:  Grant([]string{"SELECT", "INSERT", "ALTER ROUTINE"}, DatabaseLevel("app"), account, false),
+			want: "GRANT SELECT, INSERT, ALTER ROUTINE ON `app`.* TO 'app'@'%'",
+		},
+		{
+			name: "grant with grant option",
+			got:  Grant([]string{"PROCESS"}, GlobalLevel, account, true),
+			want: "GRANT PROCESS ON *.* TO 'app'@'%' WITH GRANT OPTION",
+		},
+		{
+			name: "grant column privileges",
+			got:  Grant([]string{ColumnPrivilege("SELECT", []string{"id", "name"}), "INSERT"}, TableLevel("app", "users"), account, false),
+			want: "GRANT SELECT (`id`, `name`), INSERT ON `app`.`users` TO 'app'@'%'",
+		},
+		{
+			name: "grant on routine",
+			got:  Grant([]string{"EXECUTE"}, RoutineLevel("PROCEDURE", "app", "refresh"), account, true),
+			want: "GRANT EXECUTE ON PROCEDURE `app`.`refresh` TO 'app'@'%' WITH GRANT OPTION",
+		},
+		{
+			name: "grant to role",
+			got:  Grant([]string{"SELECT"}, DatabaseLevel("app"), Account("reader", "%"), false),
+			want: "GRANT SELECT ON `app`.* TO 'reader'@'%'",
+		},
+		{
+			name: "revoke single privilege",
+			got:  Revoke([]string{"SELECT"}, DatabaseLevel("app"), account),
+			want: "REVOKE SELECT ON `app`.* FROM 'app'@'%'",
+		},
+		{
+			name: "revoke privileges",
+			got:  Revoke([]string{"SELECT", "GRANT OPTION"}, TableLevel("app", "users"), account),
+			want: "REVOKE SELECT, GRANT OPTION ON `app`.`users` FROM 'app'@'%'",
+		},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s = %s, want %s", test.name, test.got, test.want)
+		}
+	}
+}
+
+func TestRoleStatements(t *testing.T) {
+	roles := []string{Account("reader", "%"), Account("writer", "%")}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "grant role", got: GrantRoles(roles[:1], Account("app", "%"), false), want: "GRANT 'reader'@'%' TO 'app'@'%'"},
+		{name: "grant roles", got: GrantRoles(roles, Account("app", "%"), false), want: "GRANT 'reader'@'%', 'writer'@'%' TO 'app'@'%'"},
+		{
+			name: "grant roles with admin option",
+			got:  GrantRoles(roles, Account("app", "%"), true),
+			want: "GRANT 'reader'@'%', 'writer'@'%' TO 'app'@'%' WITH ADMIN OPTION",
+		},
+		{name: "revoke roles", got: RevokeRoles(roles, Account("app", "%")), want: "REVOKE 'reader'@'%', 'writer'@'%' FROM 'app'@'%'"},
+		{name: "create role", got: CreateRole(roles[0], false), want: "CREATE ROLE 'reader'@'%'"},
+		{name: "create role if not exists", got: CreateRole(roles[0], true), want: "CREATE ROLE IF NOT EXISTS 'reader'@'%'"},
+		{name: "drop role", got: DropRole(roles[0], false), want: "DROP ROLE 'reader'@'%'"},
+		{name: "drop role if exists", got: DropRole(roles[0], true), want: "DROP ROLE IF EXISTS 'reader'@'%'"},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s = %s, want %s", test.name, test.got, test.want)
+		}
+	}
+}
+
+func TestUserStatements(t *testing.T) {
+	user := Account("app", "%")
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "create user", got: CreateUser(user, "s3cret"), want: "CREATE USER 'app'@'%' IDENTIFIED BY 's3cret'"},
+		{name: "create user with quote", got: CreateUser(user, `it's\`), want: `CREATE USER 'app'@'%' IDENTIFIED BY 'it''s\\'`},
+		{name: "alter password", got: AlterUserPassword(user, "n'ew"), want: "ALTER USER 'app'@'%' IDENTIFIED BY 'n''ew'"},
+		{name: "drop user", got: DropUser(user, false), want: "DROP USER 'app'@'%'"},
+		{name: "drop user if exists", got: DropUser(user, true), want: "DROP USER IF EXISTS 'app'@'%'"},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s = %s, want %s", test.name, test.got, test.want)
+		}
+	}
+}
+
+func TestIndexStatements(t *testing.T) {
+	table := TableLevel("app", "users")
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "create index", got: CreateIndex("idx_name", table, []string{"name"}, false), want: "CREATE INDEX `idx_name` ON `app`.`users` (`name`)"},
+		{
+			name: "create unique index",
+			got:  CreateIndex("idx`email", table, []string{"tenant", "email"}, true),
+			want: "CREATE UNIQUE INDEX `idx``email` ON `app`.`users` (`tenant`, `email`)",
+		},
+		{name: "drop index", got: DropIndex("idx_name", table), want: "DROP INDEX `idx_name` ON `app`.`users`"},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s = %s, want %s", test.name, test.got, test.want)
+		}
+	}
+}
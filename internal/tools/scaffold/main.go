@@ -0,0 +1,365 @@
+// Command scaffold generates the boilerplate of a new resource: the resource with its schema, model and Configure
+// wiring, the acceptance test skeleton, the documentation page and the example. Run it from the root of the
+// repository:
+//
+//	go run ./internal/tools/scaffold -type=grant_table
+//
+// The generated code compiles, the resource still needs to be added to Resources in provider.go. The acceptance test
+// runs with TF_ACC=1 against the instance of the CLOUDSQL_MYSQL_* environment variables.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+const providerName = "cloudsqlmysql"
+
+var typeNameRegex = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// resourceData is passed to the templates.
+type resourceData struct {
+	ProviderName string
+	TypeName     string // grant_table
+	Name         string // grantTable
+}
+
+func main() {
+	var typeName string
+	var root string
+	flag.StringVar(&typeName, "type", "", "the type name of the resource without the provider prefix, e.g. grant_table")
+	flag.StringVar(&root, "root", ".", "the root of the repository")
+	flag.Parse()
+
+	if !typeNameRegex.MatchString(typeName) {
+		log.Fatalf("-type must be a lowercase name with underscores like grant_table, got %q", typeName)
+	}
+
+	data := newResourceData(typeName)
+	files := []struct {
+		path     string
+		template *template.Template
+		gofmt    bool
+	}{
+		{filepath.Join(root, "internal", "provider", "resource_"+typeName+".go"), resourceTemplate, true},
+		{filepath.Join(root, "internal", "provider", "resource_"+typeName+"_test.go"), testTemplate, true},
+		{filepath.Join(root, "docs", "resources", typeName+".md"), docTemplate, false},
+		{filepath.Join(root, "examples", "resources", providerName+"_"+typeName, "resource.tf"), exampleTemplate, false},
+	}
+
+	for _, file := range files {
+		if _, err := os.Stat(file.path); err == nil {
+			log.Fatalf("%s already exists, the scaffold doesn't overwrite files", file.path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			log.Fatal(err)
+		}
+	}
+
+	for _, file := range files {
+		var content bytes.Buffer
+		if err := file.template.Execute(&content, data); err != nil {
+			log.Fatal(err)
+		}
+		source := content.Bytes()
+		if file.gofmt {
+			formatted, err := format.Source(source)
+			if err != nil {
+				log.Fatalf("formatting %s: %s", file.path, err)
+			}
+			source = formatted
+		}
+		if err := os.MkdirAll(filepath.Dir(file.path), 0o755); err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(file.path, source, 0o644); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("Created " + file.path)
+	}
+
+	fmt.Printf("Add new%sResource to Resources in internal/provider/provider.go\n", exported(data.Name))
+}
+
+func newResourceData(typeName string) resourceData {
+	parts := strings.Split(typeName, "_")
+	name := parts[0]
+	for _, part := range parts[1:] {
+		name += exported(part)
+	}
+	return resourceData{
+		ProviderName: providerName,
+		TypeName:     typeName,
+		Name:         name,
+	}
+}
+
+func exported(name string) string {
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+var templateFuncs = template.FuncMap{"exported": exported}
+
+var resourceTemplate = template.Must(template.New("resource").Funcs(templateFuncs).Parse(`package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource               = &{{.Name}}Resource{}
+	_ resource.ResourceWithConfigure  = &{{.Name}}Resource{}
+	_ resource.ResourceWithModifyPlan = &{{.Name}}Resource{}
+)
+
+type {{.Name}}Resource struct {
+	db     dbPool
+	config *Config
+}
+
+type {{.Name}}ResourceModel struct {
+	Name types.String ` + "`tfsdk:\"name\"`" + `
+}
+
+func new{{exported .Name}}Resource() resource.Resource {
+	return &{{.Name}}Resource{}
+}
+
+func (r *{{.Name}}Resource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_{{.TypeName}}"
+}
+
+func (r *{{.Name}}Resource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description:         "TODO: describe the {{.TypeName}} resource",
+		MarkdownDescription: "TODO: describe the ` + "`{{.TypeName}}`" + ` resource",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Description:         "TODO: describe the name",
+				MarkdownDescription: "TODO: describe the name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+	}
+}
+
+func (r *{{.Name}}Resource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan {{.Name}}ResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// TODO: execute the statements with execContext and the builders of the sqlgen package
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *{{.Name}}Resource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withReadTimeout(ctx)
+
+	var state {{.Name}}ResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// TODO: read the server state with r.config.queryRowPrepared, call resp.State.RemoveResource when it's gone
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *{{.Name}}Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan {{.Name}}ResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// TODO: apply the changes from the state to the plan
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *{{.Name}}Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = withModuleName(ctx, req.ProviderMeta)
+	ctx = r.config.withWriteTimeout(ctx)
+	ctx, warnings := r.config.withSQLWarnings(ctx)
+	defer warnings.appendTo(&resp.Diagnostics)
+
+	resp.Diagnostics.Append(r.config.acquireAdvisoryLock(ctx, r.db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state {{.Name}}ResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// TODO: remove the object from the server
+}
+
+func (r *{{.Name}}Resource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*Config)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Config, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	db, err := config.connectToMySQLNoDb(ctx) // Not connecting to a specific database
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to the Cloud SQL MySQL instance",
+			err.Error(),
+		)
+		return
+	}
+
+	err = config.detectServerSettings(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read the Cloud SQL MySQL server settings",
+			err.Error(),
+		)
+		return
+	}
+
+	r.db = db
+	r.config = config
+}
+
+func (r *{{.Name}}Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.config == nil {
+		return
+	}
+
+	var plan {{.Name}}ResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// TODO: check the plan against the provider settings, e.g. r.config.systemSchemaError and r.config.serverVersion
+}
+`))
+
+var testTemplate = template.Must(template.New("test").Funcs(templateFuncs).Parse(`package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAcc{{exported .Name}}Resource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: ` + "`" + `
+resource "{{.ProviderName}}_{{.TypeName}}" "test" {
+  name = "tf_acc_{{.TypeName}}"
+}
+` + "`" + `,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("{{.ProviderName}}_{{.TypeName}}.test", "name", "tf_acc_{{.TypeName}}"),
+				),
+			},
+			// TODO: add a step that updates the resource and checks the changed attributes
+		},
+	})
+}
+`))
+
+var docTemplate = template.Must(template.New("doc").Parse(`---
+# generated by https://github.com/hashicorp/terraform-plugin-docs
+page_title: "{{.ProviderName}}_{{.TypeName}} Resource - {{.ProviderName}}"
+subcategory: ""
+description: |-
+  TODO: describe the {{.TypeName}} resource
+---
+
+# {{.ProviderName}}_{{.TypeName}} (Resource)
+
+TODO: describe the ` + "`{{.TypeName}}`" + ` resource
+
+## Example Usage
+
+` + "```terraform" + `
+resource "{{.ProviderName}}_{{.TypeName}}" "default" {
+  name = "name"
+}
+` + "```" + `
+
+<!-- schema generated by tfplugindocs -->
+## Schema
+
+### Required
+
+- ` + "`name`" + ` (String) TODO: describe the name
+`))
+
+var exampleTemplate = template.Must(template.New("example").Parse(`resource "{{.ProviderName}}_{{.TypeName}}" "default" {
+  name = "name"
+}
+`))
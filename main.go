@@ -4,13 +4,15 @@
 package main
 
 import (
-	"context"
 	"flag"
 	"log"
 
 	"terraform-provider-cloudsqlmysql/internal/provider"
 
-	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5/tf5server"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6/tf6server"
 )
 
 //go:generate terraform fmt -recursive ./examples/
@@ -20,20 +22,62 @@ var (
 	version string = "dev"
 )
 
+const address = "registry.terraform.io/karim-fc/cloudsqlmysql"
+
 func main() {
 	var debug bool
+	var protocolVersion int
 
 	flag.BoolVar(&debug, "debug", false, "set to true to run the provider with support for debuggers like delve")
+	flag.IntVar(&protocolVersion, "protocol-version", 6, "the plugin protocol version to serve, 5 or 6")
 	flag.Parse()
 
-	opts := providerserver.ServeOpts{
-		Address: "registry.terraform.io/karim-fc/cloudsqlmysql",
-		Debug:   debug,
+	var err error
+	switch protocolVersion {
+	case 6:
+		err = serveProtocol6(debug)
+	case 5:
+		err = serveProtocol5(debug)
+	default:
+		log.Fatalf("-protocol-version must be 5 or 6, got %d", protocolVersion)
+	}
+
+	if summary := provider.SQLSummary(); summary != "" {
+		log.Printf("[INFO] %s", summary)
 	}
 
-	err := providerserver.Serve(context.Background(), provider.New(version), opts)
+	// Serve returns when Terraform shuts the plugin down, the pools are closed instead of left for the wait_timeout
+	if closeErr := provider.CloseConnections(); closeErr != nil {
+		log.Printf("[WARN] Unable to close the connections: %s", closeErr)
+	}
 
 	if err != nil {
 		log.Fatal(err.Error())
 	}
 }
+
+func serveProtocol6(debug bool) error {
+	server, err := provider.NewProtocol6(version)()
+	if err != nil {
+		return err
+	}
+
+	var opts []tf6server.ServeOpt
+	if debug {
+		opts = append(opts, tf6server.WithManagedDebug())
+	}
+	return tf6server.Serve(address, func() tfprotov6.ProviderServer { return server }, opts...)
+}
+
+func serveProtocol5(debug bool) error {
+	server, err := provider.NewProtocol5(version)()
+	if err != nil {
+		return err
+	}
+
+	var opts []tf5server.ServeOpt
+	if debug {
+		opts = append(opts, tf5server.WithManagedDebug())
+	}
+	return tf5server.Serve(address, func() tfprotov5.ProviderServer { return server }, opts...)
+}